@@ -7,13 +7,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/bigcache"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/configupdater"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/control"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/fingertest"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/node"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/notification"
@@ -21,8 +26,13 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/report"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/scheduler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/signature"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/task"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"log"
@@ -38,6 +48,18 @@ import (
 )
 
 func main() {
+	// 离线指纹规则测试，不需要连接数据库
+	if len(os.Args) > 1 && os.Args[1] == "fingertest" {
+		os.Exit(fingertest.Run(os.Args[2:]))
+	}
+	// 根据任务结果生成报告
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		os.Exit(report.Run(os.Args[2:]))
+	}
+	// 查询数据在模块流水线中的经过以及被丢弃的原因
+	if len(os.Args) > 1 && os.Args[1] == "trace" {
+		os.Exit(tracing.Run(os.Args[2:]))
+	}
 	Banner()
 	// 初始化系统信息
 	config.Initialize()
@@ -63,6 +85,8 @@ func main() {
 	}
 	// 初始化上下文管理器
 	contextmanager.NewContextManager()
+	// 初始化认证管理器
+	authmanager.NewAuthManager()
 	// 初始化tools
 	utils.InitializeTools()
 	utils.InitializeDnsTools()
@@ -102,10 +126,37 @@ func main() {
 		_ = PebbleStore.Close()
 	}(pebbledb.PebbleStore)
 
+	// 创建结果指纹索引，补充升级前结果的指纹
+	results.InitializeFindings()
 	// 初始化结果处理队列，(正常的时候将该初始化放入任务开始时，任务执行完毕关闭结果队列)
 	results.InitializeResultQueue()
 	defer results.Close()
 
+	// 指标接口
+	metrics.NodeInfo(global.AppConfig.NodeName, global.VERSION)
+	go func() {
+		if err := metrics.Serve(global.AppConfig.MetricsListen); err != nil {
+			logger.SlogErrorLocal(err.Error())
+		}
+	}()
+	if signature.Unsigned() {
+		logger.SlogWarnLocal("signature.allowUnsigned is set, control messages and plugins are not verified")
+	} else if !signature.Enabled() {
+		logger.SlogWarnLocal("signature.key is not configured, custom plugins and control messages are rejected")
+	}
+	// 节点本地控制接口
+	go func() {
+		if err := control.Serve(global.AppConfig.Control); err != nil {
+			logger.SlogErrorLocal(err.Error())
+		}
+	}()
+	// 目标处理过程的追踪
+	err = tracing.Initialize(global.AppConfig.Tracing)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("tracing Initialize error: %v", err))
+	}
+	defer tracing.Shutdown()
+
 	// 初始化全局插件管理器
 	plugins.GlobalPluginManager = plugins.NewPluginManager()
 	err = plugins.GlobalPluginManager.InitializePlugins()
@@ -113,6 +164,21 @@ func main() {
 		log.Fatalf("Failed to init plugins: %v", err)
 		return
 	}
+	// 收到退出信号时停止领取目标，等待运行中的插件完成后写入结果和本地检查点
+	shutdown.Register(shutdown.StageDrain, "tasks", task.Drain)
+	shutdown.Register(shutdown.StageForce, "tasks", task.Interrupt)
+	shutdown.Register(shutdown.StageFlush, "results", func(ctx context.Context) error {
+		return shutdown.Wait(ctx, results.Close)
+	})
+	shutdown.Register(shutdown.StageFlush, "pebbledb", func(ctx context.Context) error {
+		return pebbledb.PebbleStore.Flush()
+	})
+	shutdown.Register(shutdown.StageFlush, "tracing", func(ctx context.Context) error {
+		tracing.Shutdown()
+		return nil
+	})
+	shutdown.Register(shutdown.StageFlush, "node", node.Deregister)
+	shutdown.Listen()
 	// 性能监控
 	go pprof()
 	//go printMemStats(5 * time.Second)
@@ -120,14 +186,14 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done() // 减少计数器，表示任务完成
-		for {
+		for !shutdown.IsDraining() {
 			task.GetTask()
 		}
 	}()
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		for {
+		for !shutdown.IsDraining() {
 			node.Register()
 		}
 	}()
@@ -138,6 +204,12 @@ func main() {
 			configupdater.RefreshConfig()
 		}
 	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// 周期任务调度
+		scheduler.Run()
+	}()
 	time.Sleep(10 * time.Second)
 	wg.Wait()
 }
@@ -0,0 +1,551 @@
+// authmanager-------------------------------------
+// @file      : authmanager.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 14:20
+// -------------------------------------------
+
+package authmanager
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 登录失败后再次尝试的间隔，避免频繁请求登录接口
+const retryInterval = 60 * time.Second
+
+// AuthManager 管理每个任务的认证配置及会话
+type AuthManager struct {
+	mu       sync.RWMutex
+	sessions map[string][]*session          // taskId -> sessions
+	scopes   map[string]map[string]struct{} // taskId -> 目标的根域名或IP
+}
+
+var GlobalAuthManager *AuthManager
+
+// NewAuthManager 创建认证管理器
+func NewAuthManager() {
+	GlobalAuthManager = &AuthManager{
+		sessions: make(map[string][]*session),
+		scopes:   make(map[string]map[string]struct{}),
+	}
+}
+
+// AddTask 注册任务的认证配置，已经存在时只记录目标范围，保证同一个任务的多个目标共享会话
+func (am *AuthManager) AddTask(taskId string, target string, profiles []options.AuthProfile) {
+	if len(profiles) == 0 {
+		return
+	}
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	// 未配置主机的认证配置只用于任务目标范围内的主机
+	if scope := scopeOf(target); scope != "" {
+		if am.scopes[taskId] == nil {
+			am.scopes[taskId] = make(map[string]struct{})
+		}
+		am.scopes[taskId][scope] = struct{}{}
+	}
+	if _, ok := am.sessions[taskId]; ok {
+		return
+	}
+	var sessions []*session
+	for _, profile := range profiles {
+		sessions = append(sessions, newSession(profile))
+	}
+	am.sessions[taskId] = sessions
+	logger.SlogInfoLocal(fmt.Sprintf("task %v load %v auth profiles", taskId, len(sessions)))
+}
+
+// DeleteTask 任务结束时删除认证会话
+func (am *AuthManager) DeleteTask(taskId string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	delete(am.sessions, taskId)
+	delete(am.scopes, taskId)
+}
+
+// HasProfiles 任务是否配置了认证
+func (am *AuthManager) HasProfiles(taskId string) bool {
+	if am == nil {
+		return false
+	}
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return len(am.sessions[taskId]) != 0
+}
+
+// Headers 返回目标url需要携带的认证请求头，格式为 "Name: value"
+func (am *AuthManager) Headers(taskId string, rawUrl string) []string {
+	headerMap := am.HeaderMap(taskId, rawUrl)
+	if len(headerMap) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(headerMap))
+	for k := range headerMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	headers := make([]string, 0, len(keys))
+	for _, k := range keys {
+		headers = append(headers, k+": "+headerMap[k])
+	}
+	return headers
+}
+
+// HeaderMap 返回目标url需要携带的认证请求头，多个配置匹配时cookie会合并
+func (am *AuthManager) HeaderMap(taskId string, rawUrl string) map[string]string {
+	if am == nil {
+		return nil
+	}
+	host := hostOf(rawUrl)
+	am.mu.RLock()
+	sessions := am.sessions[taskId]
+	inScope := am.inScope(taskId, host)
+	am.mu.RUnlock()
+	if len(sessions) == 0 {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, s := range sessions {
+		if !s.match(host, inScope) {
+			continue
+		}
+		for k, v := range s.headers() {
+			if strings.EqualFold(k, "Cookie") {
+				if exist, ok := result["Cookie"]; ok && exist != "" {
+					result["Cookie"] = exist + "; " + v
+					continue
+				}
+				k = "Cookie"
+			}
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func hostOf(rawUrl string) string {
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "http://" + rawUrl
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// inScope 判断主机是否属于任务目标的根域名或IP，调用方需要持有读锁
+func (am *AuthManager) inScope(taskId string, host string) bool {
+	if host == "" {
+		return false
+	}
+	for scope := range am.scopes[taskId] {
+		if host == scope || strings.HasSuffix(host, "."+scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeOf 返回目标的根域名，IP目标返回IP本身
+func scopeOf(target string) string {
+	host := strings.TrimPrefix(hostOf(strings.TrimSpace(target)), "*.")
+	if host == "" {
+		return ""
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	root, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return root
+}
+
+type session struct {
+	mu       sync.Mutex
+	group    singleflight.Group // 同一时间只有一个刷新请求，刷新过程中不持有锁
+	profile  options.AuthProfile
+	client   *http.Client
+	dynamic  map[string]string // 动态获取的认证信息
+	expireAt time.Time         // oauth2 token过期时间
+	checkAt  time.Time         // 下一次会话有效性检测时间
+	failAt   time.Time         // 最后一次登录失败时间
+}
+
+func newSession(profile options.AuthProfile) *session {
+	jar, _ := cookiejar.New(nil)
+	return &session{
+		profile: profile,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+			Jar:     jar,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				DialContext:     (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+			},
+		},
+	}
+}
+
+// match 判断主机是否匹配该配置，未配置主机时只匹配任务目标范围内的主机
+func (s *session) match(host string, inScope bool) bool {
+	if len(s.profile.Hosts) == 0 {
+		return inScope
+	}
+	for _, pattern := range s.profile.Hosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if pattern == host {
+			return true
+		}
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+		// *.example.com 同时匹配 example.com
+		if strings.HasPrefix(pattern, "*.") && host == pattern[2:] {
+			return true
+		}
+	}
+	return false
+}
+
+// headers 返回当前有效的认证请求头，需要时刷新token或者重新登录
+// 刷新在锁外进行，已有认证信息时后台刷新并继续使用旧的认证信息
+func (s *session) headers() map[string]string {
+	s.mu.Lock()
+	result := make(map[string]string)
+	for k, v := range s.profile.Headers {
+		result[k] = v
+	}
+	if len(s.profile.Cookies) != 0 {
+		var cookies []string
+		for k, v := range s.profile.Cookies {
+			cookies = append(cookies, k+"="+v)
+		}
+		sort.Strings(cookies)
+		result["Cookie"] = strings.Join(cookies, "; ")
+	}
+	hasDynamic := s.dynamic != nil
+	needRefresh := s.needRefresh(time.Now())
+	s.mu.Unlock()
+	if needRefresh {
+		ch := s.group.DoChan("refresh", func() (interface{}, error) {
+			s.refresh()
+			return nil, nil
+		})
+		if !hasDynamic {
+			<-ch
+		}
+	}
+	s.mu.Lock()
+	for k, v := range s.dynamic {
+		if strings.EqualFold(k, "Cookie") && result["Cookie"] != "" {
+			result["Cookie"] = result["Cookie"] + "; " + v
+			continue
+		}
+		result[k] = v
+	}
+	s.mu.Unlock()
+	return result
+}
+
+// needRefresh 判断是否需要刷新认证信息，调用方需要持有锁
+func (s *session) needRefresh(now time.Time) bool {
+	switch s.profile.Type {
+	case "oauth2":
+		// 刷新失败后在重试间隔内不再请求，继续使用旧的token
+		return (s.dynamic == nil || now.After(s.expireAt)) && now.Sub(s.failAt) >= retryInterval
+	case "login":
+		if s.dynamic != nil {
+			return !now.Before(s.checkAt)
+		}
+		return now.Sub(s.failAt) >= retryInterval
+	}
+	return false
+}
+
+// refresh 在锁外请求认证接口，完成后在锁内发布新的认证信息
+func (s *session) refresh() {
+	switch s.profile.Type {
+	case "oauth2":
+		dynamic, expireAt, err := s.refreshOAuth2()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			s.failAt = time.Now()
+			logger.SlogWarnLocal(fmt.Sprintf("auth profile %v oauth2 refresh error: %v", s.profile.Name, err))
+			return
+		}
+		s.dynamic = dynamic
+		s.expireAt = expireAt
+		s.failAt = time.Time{}
+		logger.SlogInfoLocal(fmt.Sprintf("auth profile %v oauth2 token refreshed", s.profile.Name))
+	case "login":
+		s.ensureLogin()
+	}
+}
+
+// refreshOAuth2 使用 client credentials 获取 access token，返回认证请求头以及刷新时间
+func (s *session) refreshOAuth2() (map[string]string, time.Time, error) {
+	cfg := s.profile.OAuth2
+	if cfg.TokenUrl == "" {
+		return nil, time.Time{}, fmt.Errorf("tokenUrl is empty")
+	}
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	// 部分服务端同时收到两种凭证时拒绝请求，只使用其中一种
+	basic := !strings.EqualFold(cfg.AuthStyle, "body")
+	if !basic {
+		form.Set("client_id", cfg.ClientId)
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	req, err := http.NewRequest(http.MethodPost, cfg.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basic {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientId), url.QueryEscape(cfg.ClientSecret))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("token endpoint status code %v", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, time.Time{}, err
+	}
+	if token.AccessToken == "" {
+		return nil, time.Time{}, fmt.Errorf("access_token is empty")
+	}
+	if token.ExpiresIn <= 0 {
+		token.ExpiresIn = 3600
+	}
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	dynamic := map[string]string{"Authorization": tokenType + " " + token.AccessToken}
+	// 提前30秒刷新，避免请求过程中token过期，有效期较短时提前一半的时间
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	early := 30 * time.Second
+	if lifetime <= 2*early {
+		early = lifetime / 2
+	}
+	return dynamic, time.Now().Add(lifetime - early), nil
+}
+
+// ensureLogin 保证登录会话有效，会话失效时重新登录，网络请求过程中不持有锁
+func (s *session) ensureLogin() {
+	now := time.Now()
+	s.mu.Lock()
+	current := s.dynamic
+	failAt := s.failAt
+	s.mu.Unlock()
+	if current != nil && s.checkSession(current) {
+		s.mu.Lock()
+		s.checkAt = now.Add(s.checkInterval())
+		s.mu.Unlock()
+		return
+	}
+	if now.Sub(failAt) < retryInterval {
+		return
+	}
+	dynamic, err := s.login()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failAt = now
+		logger.SlogWarnLocal(fmt.Sprintf("auth profile %v login error: %v", s.profile.Name, err))
+		return
+	}
+	s.dynamic = dynamic
+	s.checkAt = now.Add(s.checkInterval())
+	logger.SlogInfoLocal(fmt.Sprintf("auth profile %v login success", s.profile.Name))
+}
+
+func (s *session) checkInterval() time.Duration {
+	if s.profile.Login.CheckInterval > 0 {
+		return time.Duration(s.profile.Login.CheckInterval) * time.Second
+	}
+	return 300 * time.Second
+}
+
+// login 按顺序执行登录步骤，返回收集到的cookie以及token
+func (s *session) login() (map[string]string, error) {
+	cfg := s.profile.Login
+	if len(cfg.Steps) == 0 {
+		return nil, fmt.Errorf("login steps is empty")
+	}
+	// 重新登录时清空旧的cookie
+	s.client.Jar, _ = cookiejar.New(nil)
+	var lastBody []byte
+	var lastHeader http.Header
+	var stepUrls []*url.URL
+	for _, step := range cfg.Steps {
+		method := strings.ToUpper(step.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		var body io.Reader
+		if step.Body != "" {
+			body = strings.NewReader(step.Body)
+		}
+		req, err := http.NewRequest(method, step.Url, body)
+		if err != nil {
+			return nil, err
+		}
+		if step.ContentType != "" {
+			req.Header.Set("Content-Type", step.ContentType)
+		} else if step.Body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		for k, v := range step.Headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		lastBody, _ = io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		lastHeader = resp.Header
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("login step %v status code %v", step.Url, resp.StatusCode)
+		}
+		stepUrls = append(stepUrls, req.URL)
+	}
+	dynamic := make(map[string]string)
+	// 合并所有步骤中获取到的cookie
+	seen := make(map[string]struct{})
+	var cookies []string
+	for _, u := range stepUrls {
+		for _, c := range s.client.Jar.Cookies(u) {
+			if _, ok := seen[c.Name]; ok {
+				continue
+			}
+			seen[c.Name] = struct{}{}
+			cookies = append(cookies, c.Name+"="+c.Value)
+		}
+	}
+	if len(cookies) != 0 {
+		dynamic["Cookie"] = strings.Join(cookies, "; ")
+	}
+	if cfg.TokenRegex != "" {
+		re, err := regexp.Compile(cfg.TokenRegex)
+		if err != nil {
+			return nil, fmt.Errorf("tokenRegex compile error: %v", err)
+		}
+		// 先从响应体中匹配，匹配不到再从响应头中匹配
+		content := string(lastBody)
+		m := re.FindStringSubmatch(content)
+		if m == nil {
+			var headerLines []string
+			for k, values := range lastHeader {
+				for _, v := range values {
+					headerLines = append(headerLines, k+": "+v)
+				}
+			}
+			m = re.FindStringSubmatch(strings.Join(headerLines, "\n"))
+		}
+		if m == nil {
+			return nil, fmt.Errorf("token not found in login response")
+		}
+		token := m[0]
+		if len(m) > 1 {
+			token = m[1]
+		}
+		tokenHeader := cfg.TokenHeader
+		if tokenHeader == "" {
+			tokenHeader = "Authorization"
+		}
+		dynamic[tokenHeader] = cfg.TokenPrefix + token
+	}
+	if len(dynamic) == 0 {
+		return nil, fmt.Errorf("no cookie or token obtained")
+	}
+	return dynamic, nil
+}
+
+// checkSession 检测当前会话是否有效
+func (s *session) checkSession(dynamic map[string]string) bool {
+	cfg := s.profile.Login
+	if cfg.CheckUrl == "" {
+		return true
+	}
+	req, err := http.NewRequest(http.MethodGet, cfg.CheckUrl, nil)
+	if err != nil {
+		return false
+	}
+	for k, v := range s.profile.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range dynamic {
+		req.Header.Set(k, v)
+	}
+	// 使用不带cookie jar的客户端，保证只验证当前保存的认证信息
+	client := &http.Client{
+		Timeout:   s.client.Timeout,
+		Transport: s.client.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	status := cfg.CheckStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if resp.StatusCode != status {
+		return false
+	}
+	if cfg.CheckRegex != "" {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		matched, err := regexp.MatchString(cfg.CheckRegex, string(body))
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -10,6 +10,7 @@ package bigcache
 import (
 	"context"
 	"github.com/allegro/bigcache/v3"
+	"strings"
 	"time"
 )
 
@@ -52,3 +53,22 @@ func (b *BigCacheWrapper) Get(key string) ([]byte, error) {
 func (b *BigCacheWrapper) Delete(key string) error {
 	return b.cache.Delete(key)
 }
+
+// DeletePrefix 删除指定前缀的所有缓存，返回删除的数量
+func (b *BigCacheWrapper) DeletePrefix(prefix string) int {
+	var keys []string
+	it := b.cache.Iterator()
+	for it.SetNext() {
+		entry, err := it.Value()
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(entry.Key(), prefix) {
+			keys = append(keys, entry.Key())
+		}
+	}
+	for _, key := range keys {
+		_ = b.cache.Delete(key)
+	}
+	return len(keys)
+}
@@ -32,8 +32,9 @@ func LoadConfig() error {
 		global.FirstRun = true
 		// 配置文件不存在，从环境变量读取
 		global.AppConfig = global.Config{
-			NodeName:     getEnv("NodeName", ""),
-			TimeZoneName: getEnv("TimeZoneName", "Asia/Shanghai"),
+			NodeName:      getEnv("NodeName", ""),
+			TimeZoneName:  getEnv("TimeZoneName", "Asia/Shanghai"),
+			MetricsListen: getEnv("METRICS_LISTEN", ""),
 			MongoDB: global.MongoDBConfig{
 				IP:       getEnv("MONGODB_IP", ""),
 				Port:     getEnv("MONGODB_PORT", "27017"),
@@ -46,6 +47,18 @@ func LoadConfig() error {
 				Port:     getEnv("REDIS_PORT", "6379"),
 				Password: getEnv("REDIS_PASSWORD", ""),
 			},
+			Tracing: global.TracingConfig{
+				Exporter: getEnv("TRACING_EXPORTER", ""),
+				Endpoint: getEnv("TRACING_ENDPOINT", ""),
+			},
+			Log: global.LogConfig{
+				Format: getEnv("LOG_FORMAT", "console"),
+				Level:  getEnv("LOG_LEVEL", ""),
+			},
+			Signature: global.SignatureConfig{
+				Key:           getEnv("SIGNATURE_KEY", ""),
+				AllowUnsigned: getEnv("SIGNATURE_ALLOW_UNSIGNED", "false") == "true",
+			},
 		}
 		debug := getEnv("DEBUG", "false")
 		if debug == "true" {
@@ -62,6 +62,8 @@ type VulnerabilityScanConfig struct {
 
 type ModulesConfigStruct struct {
 	MaxGoroutineCount   int                       `yaml:"maxGoroutineCount"`
+	MaxTaskCount        int                       `yaml:"maxTaskCount"` // 同时运行的任务数
+	ModuleBudget        map[string]int            `yaml:"moduleBudget"` // 各模块处理单条数据的默认时间预算（秒），任务没有配置时使用
 	SubdomainScan       SubdomainScanConfig       `yaml:"subdomainScan"`
 	SubdomainSecurity   SubdomainSecurityConfig   `yaml:"subdomainSecurity"`
 	AssetMapping        AssetMappConfig           `yaml:"assetMapping"`
@@ -14,6 +14,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
@@ -25,6 +26,8 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 // UpdateGlobalModulesConfig 拉取server的全局模块配置
@@ -175,12 +178,14 @@ type tmpProject struct {
 	ID          primitive.ObjectID `bson:"_id"`
 	RootDomains []string           `bson:"root_domains"`
 	Ignore      string             `bson:"ignore"`
+	// 敏感信息允许列表，每行一个值，支持*通配符
+	SensitiveAllow string `bson:"sensitive_allow"`
 }
 
 func UpdateProject() {
 	logger.SlogInfoLocal("project load begin")
 	var tmpProjects []tmpProject
-	if err := mongodb.MongodbClient.FindAll("project", bson.M{}, bson.M{"_id": 1, "root_domains": 1, "ignore": 1}, &tmpProjects); err != nil {
+	if err := mongodb.MongodbClient.FindAll("project", bson.M{}, bson.M{"_id": 1, "root_domains": 1, "ignore": 1, "sensitive_allow": 1}, &tmpProjects); err != nil {
 		return
 	}
 	global.Projects = []types.Project{}
@@ -197,6 +202,22 @@ func UpdateProject() {
 		}
 		proj.IgnoreList = ignoreList
 		proj.IgnoreRegexList = regexList
+		for _, allow := range strings.Split(tmpProj.SensitiveAllow, "\n") {
+			allow = strings.TrimSpace(allow)
+			if allow == "" {
+				continue
+			}
+			if !strings.Contains(allow, "*") {
+				proj.SensitiveAllowList = append(proj.SensitiveAllowList, allow)
+				continue
+			}
+			allowRegex, err := regexp.Compile("^" + strings.ReplaceAll(regexp.QuoteMeta(allow), `\*`, `.*`) + "$")
+			if err != nil {
+				logger.SlogWarnLocal(fmt.Sprintf("project %v sensitive allow %v error: %v", proj.ID, allow, err))
+				continue
+			}
+			proj.SensitiveAllowRegexList = append(proj.SensitiveAllowRegexList, allowRegex)
+		}
 		global.Projects = append(global.Projects, proj)
 	}
 	logger.SlogInfoLocal("project load end")
@@ -297,11 +318,11 @@ func UpdateWebFinger() {
 
 func UpdateNotification() {
 	logger.SlogInfoLocal("Notification load begin")
-	if err := mongodb.MongodbClient.FindAll("notification", bson.M{"state": true}, bson.M{"_id": 0, "method": 1, "url": 1, "contentType": 1, "data": 1, "state": 1}, &global.NotificationApi); err != nil {
+	if err := mongodb.MongodbClient.FindAll("notification", bson.M{"state": true}, bson.M{"_id": 0, "method": 1, "url": 1, "contentType": 1, "data": 1, "state": 1, "name": 1, "type": 1, "secret": 1, "template": 1, "templates": 1, "modules": 1, "projects": 1, "minSeverity": 1, "retry": 1, "suppressWindow": 1, "email": 1}, &global.NotificationApi); err != nil {
 		logger.SlogError(fmt.Sprintf("UpdateNotification error notification api: %s", err))
 		return
 	}
-	if err := mongodb.MongodbClient.FindOne("config", bson.M{"name": "notification"}, bson.M{"_id": 0, "dirScanNotification": 1, "portScanNotification": 1, "sensitiveNotification": 1, "subdomainTakeoverNotification": 1, "pageMonNotification": 1, "subdomainNotification": 1, "vulNotification": 1, "vulLevel": 1, "newAsset": 1}, &global.NotificationConfig); err != nil {
+	if err := mongodb.MongodbClient.FindOne("config", bson.M{"name": "notification"}, bson.M{"_id": 0, "dirScanNotification": 1, "portScanNotification": 1, "sensitiveNotification": 1, "subdomainTakeoverNotification": 1, "pageMonNotification": 1, "subdomainNotification": 1, "vulNotification": 1, "vulLevel": 1, "newAsset": 1, "assetChangeNotification": 1, "assetChangeLevel": 1}, &global.NotificationConfig); err != nil {
 		logger.SlogError(fmt.Sprintf("UpdateNotification error notification config: %s", err))
 		return
 	}
@@ -310,18 +331,17 @@ func UpdateNotification() {
 
 func LoadPlugin() {
 	logger.SlogInfoLocal("load plugin load begin")
-	var result []PluginInfo
-	err := mongodb.MongodbClient.FindAll("plugins", bson.M{"isSystem": false, "type": bson.M{"$ne": "server"}}, bson.M{"module": 1, "hash": 1, "source": 1}, &result)
+	var result []plugins.PluginInfo
+	err := mongodb.MongodbClient.FindAll("plugins", bson.M{"isSystem": false, "type": bson.M{"$ne": "server"}}, bson.M{"module": 1, "hash": 1, "source": 1, "signature": 1}, &result)
 	if err != nil {
 		logger.SlogErrorLocal(fmt.Sprintf("find plugin error: %v", err))
 		return
 	}
 	utils.Tools.DeleteFolder(global.PluginDir)
 	for _, r := range result {
-		plgPath := filepath.Join(global.PluginDir, r.Module, fmt.Sprintf("%v.go", r.Hash))
-		err = utils.Tools.WriteContentFile(plgPath, r.Source)
+		_, err = plugins.WritePlugin(r)
 		if err != nil {
-			logger.SlogErrorLocal(fmt.Sprintf("WriteContentFile plugin %v error: %v", r.Hash, err))
+			logger.SlogErrorLocal(err.Error())
 			continue
 		}
 	}
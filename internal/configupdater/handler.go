@@ -14,82 +14,167 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/stream"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
-	"os"
 	"time"
 )
 
 type Message struct {
-	Name    string `json:"name"`
-	Type    string `json:"type"`
-	Content string `json:"content"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+	Timestamp int64  `json:"timestamp"` // 签名时间，unix 秒
+	Nonce     string `json:"nonce"`     // 每条消息唯一，防止重放
+	Signature string `json:"signature"` // 见 signature 包
 }
 
+// legacyPollInterval 服务端通过流下发消息后，旧列表的轮询间隔
+const legacyPollInterval = 30 * time.Second
+
+var controlConsumer *stream.Consumer
+
+// RefreshConfig 接收服务端下发的控制消息
+// 消息通过节点的控制流推送，处理完成后确认，节点崩溃重启后重新投递未确认的消息
+// 兼容旧版本服务端，继续轮询 refresh_config:<node> 列表，收到流消息后降低轮询频率
 func RefreshConfig() {
+	// 节点退出过程中继续接收停止任务等消息
+	ctx := context.Background()
+	controlConsumer = &stream.Consumer{
+		Backend: redis.RedisClient.Client(),
+		Stream:  stream.ControlStream(global.AppConfig.NodeName),
+		Group:   stream.Group,
+		Name:    global.AppConfig.NodeName,
+		Handle:  handleStreamMessage,
+	}
+	go func() {
+		if err := controlConsumer.Run(ctx); err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("control stream error: %v", err))
+		}
+	}()
 	ticker := time.Tick(3 * time.Second)
+	lastPoll := time.Time{}
 	for {
 		<-ticker
-		RefreshConfigNodeName := "refresh_config:" + global.AppConfig.NodeName
+		if controlConsumer.Received() && time.Since(lastPoll) < legacyPollInterval {
+			continue
+		}
+		lastPoll = time.Now()
+		pollList()
+	}
+}
+
+// pollList 读取旧版本服务端写入列表中的消息，与流消息使用相同的签名校验
+func pollList() {
+	RefreshConfigNodeName := "refresh_config:" + global.AppConfig.NodeName
+	for {
 		exists, err := redis.RedisClient.Exists(context.Background(), RefreshConfigNodeName)
 		if err != nil {
 			logger.SlogError(fmt.Sprintf("RefreshConfig Error: %v", err))
+			return
+		}
+		if !exists {
+			return
+		}
+		msg, err := redis.RedisClient.PopFirstFromList(context.Background(), RefreshConfigNodeName)
+		logger.SlogInfo(fmt.Sprintf("recv RefreshConfig: %s", msg))
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("RefreshConfig Error 2:%v", err))
+			return
+		}
+		jsonData := Message{}
+		err = json.Unmarshal([]byte(msg), &jsonData)
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("Task parse error: %v", err))
 			continue
 		}
-		if exists {
-			msg, err := redis.RedisClient.PopFirstFromList(context.Background(), RefreshConfigNodeName)
-			logger.SlogInfo(fmt.Sprintf("recv RefreshConfig: %s", msg))
-			if err != nil {
-				logger.SlogErrorLocal(fmt.Sprintf("RefreshConfig Error 2:%v", err))
-				continue
-			}
-			jsonData := Message{}
-			err2 := json.Unmarshal([]byte(msg), &jsonData)
-			if err2 != nil {
-				logger.SlogErrorLocal(fmt.Sprintf("Task parse error: %v", err))
-				continue
-			}
-			if jsonData.Name == "all" || jsonData.Name == global.AppConfig.NodeName {
-				switch jsonData.Type {
-				case "system":
-					UpdateSystemConfig(jsonData.Content)
-				case "dictionary":
-					Updatedictionary(jsonData.Content)
-				case "subfinder":
-					UpdateSubfinderApiConfig()
-				case "rad":
-					UpdateRadConfig()
-				case "sensitive":
-					UpdateSensitive()
-				case "nodeConfig":
-					UpdateNode(jsonData.Content)
-				case "project":
-					UpdateProject()
-				case "poc":
-					UpdatePoc(jsonData.Content)
-				case "finger":
-					UpdateWebFinger()
-				case "notification":
-					UpdateNotification()
-				case "stop_task":
-					handler.TaskHandle.StopTask(jsonData.Content)
-				case "delete_task":
-					handler.TaskHandle.DeleteTask(jsonData.Content)
-				case "install_plugin":
-					InstallPlugin(jsonData.Content)
-				case "delete_plugin":
-					DeletePlugin(jsonData.Content)
-				case "re_install_plugin":
-					ReInstall(jsonData.Content)
-				case "re_check_plugin":
-					ReCheck(jsonData.Content)
-				case "uninstall_plugin":
-					Uninstall(jsonData.Content)
-				case "UpdateSystem":
-					SystemUpdate(jsonData.Content)
-				case "restart":
-					os.Exit(0)
-				}
-			}
+		HandleMessage(jsonData)
+	}
+}
+
+// handleStreamMessage 处理控制流中的消息，返回是否确认
+// 会结束进程的消息在处理前确认，防止重启后重复处理
+func handleStreamMessage(ctx context.Context, msg stream.Message) bool {
+	logger.SlogInfo(fmt.Sprintf("recv control message %v: %s", msg.ID, msg.Body))
+	jsonData := Message{}
+	err := json.Unmarshal([]byte(msg.Body), &jsonData)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("control message %v parse error: %v", msg.ID, err))
+		return true
+	}
+	switch jsonData.Type {
+	case "restart", "UpdateSystem":
+		controlConsumer.Ack(msg.ID)
+		handleMessage(jsonData, msg.ID)
+		return false
+	}
+	handleMessage(jsonData, msg.ID)
+	return true
+}
+
+// HandleMessage 处理服务端下发的控制消息，列表和本地控制接口使用相同的处理方法
+func HandleMessage(jsonData Message) {
+	handleMessage(jsonData, "")
+}
+
+// handleMessage 处理控制消息，streamId 为控制流中的消息ID
+func handleMessage(jsonData Message, streamId string) {
+	if jsonData.Name != "all" && jsonData.Name != global.AppConfig.NodeName {
+		return
+	}
+	// 没有签名、签名错误、过期或者重放的消息直接丢弃
+	if err := verifyMessage(jsonData, streamId); err != nil {
+		source := "list"
+		if streamId != "" {
+			source = "stream " + streamId
 		}
+		logger.SlogError(fmt.Sprintf("reject control message %v from %v: %v", jsonData.Type, source, err))
+		return
+	}
+	switch jsonData.Type {
+	case "system":
+		UpdateSystemConfig(jsonData.Content)
+	case "dictionary":
+		Updatedictionary(jsonData.Content)
+	case "subfinder":
+		UpdateSubfinderApiConfig()
+	case "rad":
+		UpdateRadConfig()
+	case "sensitive":
+		UpdateSensitive()
+	case "nodeConfig":
+		UpdateNode(jsonData.Content)
+	case "project":
+		UpdateProject()
+	case "poc":
+		UpdatePoc(jsonData.Content)
+	case "finger":
+		UpdateWebFinger()
+	case "notification":
+		UpdateNotification()
+	case "log_level":
+		UpdateLogLevel(jsonData.Content)
+	case "stop_task":
+		handler.TaskHandle.StopTask(jsonData.Content)
+	case "pause_task":
+		handler.TaskHandle.PauseTask(jsonData.Content)
+	case "resume_task":
+		handler.TaskHandle.ResumeTask(jsonData.Content)
+	case "delete_task":
+		handler.TaskHandle.DeleteTask(jsonData.Content)
+	case "install_plugin":
+		InstallPlugin(jsonData.Content)
+	case "delete_plugin":
+		DeletePlugin(jsonData.Content)
+	case "re_install_plugin":
+		ReInstall(jsonData.Content)
+	case "re_check_plugin":
+		ReCheck(jsonData.Content)
+	case "uninstall_plugin":
+		Uninstall(jsonData.Content)
+	case "UpdateSystem":
+		SystemUpdate(jsonData.Content)
+	case "restart":
+		go shutdown.Exit("restart")
 	}
 }
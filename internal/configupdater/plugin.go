@@ -22,10 +22,8 @@ import (
 	"strings"
 )
 
-// PluginInfo 存储插件信息的结构体
-
 func InstallPlugin(id string) {
-	var result PluginInfo
+	var result plugins.PluginInfo
 	logger.SlogInfoLocal(fmt.Sprintf("update plugin:%v", id))
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -33,15 +31,14 @@ func InstallPlugin(id string) {
 		return
 	}
 
-	err = mongodb.MongodbClient.FindOne("plugins", bson.M{"_id": objectID}, bson.M{"module": 1, "hash": 1, "source": 1}, &result)
+	err = mongodb.MongodbClient.FindOne("plugins", bson.M{"_id": objectID}, bson.M{"module": 1, "hash": 1, "source": 1, "signature": 1}, &result)
 	if err != nil {
 		logger.SlogErrorLocal(fmt.Sprintf("find plugin error: %v", err))
 		return
 	}
-	plgPath := filepath.Join(global.PluginDir, result.Module, fmt.Sprintf("%v.go", result.Hash))
-	err = utils.Tools.WriteContentFile(plgPath, result.Source)
+	plgPath, err := plugins.WritePlugin(result)
 	if err != nil {
-		logger.SlogErrorLocal(fmt.Sprintf("WriteContentFile plugin error: %v", err))
+		logger.SlogErrorLocal(err.Error())
 		return
 	}
 	logger.SlogInfoLocal(fmt.Sprintf("write plugin end:%v", id))
@@ -105,6 +102,7 @@ func DeletePlugin(data string) {
 	}
 	plgPath := filepath.Join(global.PluginDir, module, fmt.Sprintf("%v.go", hash))
 	utils.Tools.DeleteFile(plgPath)
+	utils.Tools.DeleteFile(plgPath + ".sig")
 	nodePlgInfokey := fmt.Sprintf("NodePlg:%v", global.AppConfig.NodeName)
 	plgInfoErr := redis.RedisClient.HDel(context.Background(), nodePlgInfokey, hash+"_install", hash+"_check")
 	if plgInfoErr != nil {
@@ -10,9 +10,3 @@ package configupdater
 type ConfigResult struct {
 	Value string `bson:"value"`
 }
-
-type PluginInfo struct {
-	Module string `bson:"module"`
-	Hash   string `bson:"hash"`
-	Source string `bson:"source"`
-}
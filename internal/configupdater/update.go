@@ -9,15 +9,16 @@ package configupdater
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"gopkg.in/yaml.v3"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -67,6 +68,33 @@ func UpdateSystemConfig(content string) {
 	UpdateModuleConfig(parts[1])
 }
 
+// UpdateLogLevel 修改默认日志级别和各模块的日志级别
+// content {"level":"info","modules":{"PortScan":"debug"}}，modules 为空时清除模块的日志级别
+func UpdateLogLevel(content string) {
+	var levels struct {
+		Level   string            `json:"level"`
+		Modules map[string]string `json:"modules"`
+	}
+	err := json.Unmarshal([]byte(content), &levels)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("log level parse error: %v", err))
+		return
+	}
+	err = logger.SetLevels(levels.Level, levels.Modules)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("update log level error: %v", err))
+		return
+	}
+	global.AppConfig.Log.Level = levels.Level
+	global.AppConfig.Log.ModuleLevels = levels.Modules
+	err = utils.Tools.WriteYAMLFile(global.ConfigPath, global.AppConfig)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("WriteYAMLFile global.AppConfig error: %v", err))
+		return
+	}
+	logger.SlogInfo(fmt.Sprintf("log level updated: %v %v", levels.Level, levels.Modules))
+}
+
 func UpdateModuleConfig(content string) {
 	modulesConfig := config.ModulesConfigStruct{}
 	err := yaml.Unmarshal([]byte(content), &modulesConfig)
@@ -74,6 +102,8 @@ func UpdateModuleConfig(content string) {
 		logger.SlogErrorLocal(fmt.Sprintf("modulesConfig parse error: %v", err))
 		return
 	}
+	config.ModulesConfig.MaxTaskCount = modulesConfig.MaxTaskCount
+	config.ModulesConfig.ModuleBudget = modulesConfig.ModuleBudget
 	if config.ModulesConfig.MaxGoroutineCount != modulesConfig.MaxGoroutineCount {
 		config.ModulesConfig.MaxGoroutineCount = modulesConfig.MaxGoroutineCount
 		err = pool.PoolManage.SetGoroutineCount("task", modulesConfig.MaxGoroutineCount)
@@ -246,7 +276,9 @@ func SystemUpdate(content string) {
 	Exit()
 }
 
+// Exit 退出节点，等待运行中的目标完成并写入结果后结束进程
 func Exit() {
 	logger.SlogInfo(fmt.Sprintf("system exit"))
-	os.Exit(0)
+	// 退出过程中仍然处理停止任务等消息
+	go shutdown.Exit("system update")
 }
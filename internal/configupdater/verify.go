@@ -0,0 +1,130 @@
+// configupdater-------------------------------------
+// @file      : verify.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 10:20
+// -------------------------------------------
+
+package configupdater
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/signature"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/cockroachdb/pebble"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxAge 控制消息默认的有效期
+	defaultMaxAge = 300 * time.Second
+	// defaultStreamRetention 控制流消息默认的有效期，节点崩溃重启后待确认的消息仍然需要处理
+	defaultStreamRetention = 7 * 24 * time.Hour
+)
+
+var (
+	ErrExpired  = errors.New("message expired")
+	ErrNoNonce  = errors.New("missing nonce")
+	ErrReplayed = errors.New("message replayed")
+)
+
+// 已处理消息的 nonce 保存在本地 nonce:<nonce>，值为 <签名时间>:<流消息ID>，有效期过后清理，节点重启后仍然可以拒绝重放
+var (
+	nonceMu   sync.Mutex
+	lastPrune time.Time
+)
+
+func maxAge() time.Duration {
+	if global.AppConfig.Signature.MaxAge > 0 {
+		return time.Duration(global.AppConfig.Signature.MaxAge) * time.Second
+	}
+	return defaultMaxAge
+}
+
+func streamRetention() time.Duration {
+	if global.AppConfig.Signature.StreamRetention > 0 {
+		return time.Duration(global.AppConfig.Signature.StreamRetention) * time.Second
+	}
+	return defaultStreamRetention
+}
+
+// verifyMessage 校验控制消息，streamId 为控制流中的消息ID，列表和本地控制接口的消息为空
+// 没有配置签名密钥时拒绝所有消息，只有配置 allowUnsigned 才处理未签名的消息
+// 签名包含接收消息的节点名称，发给 all 的消息也只能在签名时指定的节点上使用
+// 签名时间超过有效期或者 nonce 已经处理过的消息被拒绝
+// 流消息在确认前会重新投递，有效期使用流的保留时间，重放由 nonce 保证，同一条流消息的重新投递不算重放
+func verifyMessage(m Message, streamId string) error {
+	if !signature.Enabled() {
+		if signature.Unsigned() {
+			return nil
+		}
+		return signature.ErrNoKey
+	}
+	validity := maxAge()
+	if streamId != "" {
+		validity = streamRetention()
+	}
+	age := time.Since(time.Unix(m.Timestamp, 0))
+	if age > validity || age < -maxAge() {
+		return ErrExpired
+	}
+	if m.Nonce == "" {
+		return ErrNoNonce
+	}
+	err := signature.Verify(m.Signature, global.AppConfig.NodeName, m.Name, m.Type, m.Content, strconv.FormatInt(m.Timestamp, 10), m.Nonce)
+	if err != nil {
+		return err
+	}
+	return useNonce(m.Nonce, m.Timestamp, streamId)
+}
+
+// useNonce 记录 nonce，已经被其他消息使用过时返回 ErrReplayed
+func useNonce(nonce string, timestamp int64, streamId string) error {
+	nonceMu.Lock()
+	defer nonceMu.Unlock()
+	key := []byte("nonce:" + nonce)
+	value, err := pebbledb.PebbleStore.Get(key)
+	if err != nil && !errors.Is(err, pebble.ErrNotFound) {
+		return fmt.Errorf("check nonce: %v", err)
+	}
+	if value != nil {
+		// 处理过程中崩溃，重启后重新投递的同一条流消息
+		if _, id, _ := strings.Cut(string(value), ":"); streamId != "" && id == streamId {
+			return nil
+		}
+		return ErrReplayed
+	}
+	err = pebbledb.PebbleStore.Put(key, []byte(strconv.FormatInt(timestamp, 10)+":"+streamId))
+	if err != nil {
+		return fmt.Errorf("save nonce: %v", err)
+	}
+	if time.Since(lastPrune) > time.Minute {
+		lastPrune = time.Now()
+		pruneNonces()
+	}
+	return nil
+}
+
+// pruneNonces 删除超过有效期的 nonce，过期的消息不会通过时间校验
+// 列表中的消息可能被写入控制流重放，所有 nonce 都至少保留到流消息的有效期之后
+func pruneNonces() {
+	nonces, err := pebbledb.PebbleStore.GetKeysWithPrefix("nonce:")
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("get nonces error: %v", err))
+		return
+	}
+	retention := max(2*maxAge(), streamRetention())
+	for key, value := range nonces {
+		ts, _, _ := strings.Cut(string(value), ":")
+		timestamp, _ := strconv.ParseInt(ts, 10, 64)
+		if time.Since(time.Unix(timestamp, 0)) > retention {
+			_ = pebbledb.PebbleStore.Delete([]byte(key))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// configupdater-------------------------------------
+// @file      : verify_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/28 11:10
+// -------------------------------------------
+
+package configupdater
+
+import (
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/signature"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/cockroachdb/pebble"
+	"go.uber.org/zap"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func setup(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	db, err := pebbledb.NewPebbleDB(&pebble.Options{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDB, oldCfg := pebbledb.PebbleStore, global.AppConfig
+	pebbledb.PebbleStore = db
+	global.AppConfig.NodeName = "node1"
+	global.AppConfig.Signature = global.SignatureConfig{Key: "secret"}
+	t.Cleanup(func() {
+		_ = db.Close()
+		pebbledb.PebbleStore, global.AppConfig = oldDB, oldCfg
+	})
+}
+
+func signed(typ string, content string, timestamp time.Time, nonce string) Message {
+	m := Message{Name: "all", Type: typ, Content: content, Timestamp: timestamp.Unix(), Nonce: nonce}
+	m.Signature = signature.Sign("secret", "node1", m.Name, m.Type, m.Content, strconv.FormatInt(m.Timestamp, 10), m.Nonce)
+	return m
+}
+
+func TestVerifyListMessage(t *testing.T) {
+	setup(t)
+	m := signed("stop_task", "t1", time.Now(), "n1")
+	if err := verifyMessage(m, ""); err != nil {
+		t.Fatalf("valid message rejected: %v", err)
+	}
+	if err := verifyMessage(m, ""); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("replay: got %v, want ErrReplayed", err)
+	}
+	old := signed("stop_task", "t1", time.Now().Add(-10*time.Minute), "n2")
+	if err := verifyMessage(old, ""); !errors.Is(err, ErrExpired) {
+		t.Fatalf("old list message: got %v, want ErrExpired", err)
+	}
+}
+
+func TestVerifyStreamMessage(t *testing.T) {
+	setup(t)
+	// 节点崩溃后重新投递的待确认消息超过了 maxAge
+	m := signed("stop_task", "t1", time.Now().Add(-time.Hour), "n1")
+	if err := verifyMessage(m, "1-0"); err != nil {
+		t.Fatalf("pending stream message rejected: %v", err)
+	}
+	// 处理过程中崩溃，同一条流消息再次投递
+	if err := verifyMessage(m, "1-0"); err != nil {
+		t.Fatalf("redelivered stream message rejected: %v", err)
+	}
+	// 相同的消息写入新的流消息或者列表中属于重放
+	if err := verifyMessage(m, "2-0"); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("stream replay: got %v, want ErrReplayed", err)
+	}
+	if err := verifyMessage(m, ""); !errors.Is(err, ErrExpired) {
+		t.Fatalf("list replay: got %v, want ErrExpired", err)
+	}
+	expired := signed("stop_task", "t1", time.Now().Add(-8*24*time.Hour), "n2")
+	if err := verifyMessage(expired, "3-0"); !errors.Is(err, ErrExpired) {
+		t.Fatalf("stream message past retention: got %v, want ErrExpired", err)
+	}
+}
+
+func TestVerifyUnsignedMessage(t *testing.T) {
+	setup(t)
+	global.AppConfig.Signature = global.SignatureConfig{}
+	// 没有配置密钥时普通消息同样被拒绝
+	for _, typ := range []string{"system", "stop_task", "pause_task", "install_plugin"} {
+		m := Message{Name: "all", Type: typ, Timestamp: time.Now().Unix()}
+		if err := verifyMessage(m, ""); !errors.Is(err, signature.ErrNoKey) {
+			t.Fatalf("unsigned %v: got %v, want ErrNoKey", typ, err)
+		}
+		if err := verifyMessage(m, "1-0"); !errors.Is(err, signature.ErrNoKey) {
+			t.Fatalf("unsigned stream %v: got %v, want ErrNoKey", typ, err)
+		}
+	}
+	global.AppConfig.Signature = global.SignatureConfig{AllowUnsigned: true}
+	if err := verifyMessage(Message{Name: "all", Type: "stop_task"}, ""); err != nil {
+		t.Fatalf("allowUnsigned: got %v, want nil", err)
+	}
+}
@@ -20,6 +20,35 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// 除title/header/body外可以使用AC自动机预匹配的位置（contains、equals）
+var extraACLocations = []string{"cookie", "server", "cert_subject", "cert_issuer", "url_path"}
+
+// 使用精确值索引的位置（equals）
+var exactLocations = []string{"favicon_mmh3", "favicon_md5", "status_code", "jarm"}
+
+func isExtraACLocation(location string) bool {
+	for _, l := range extraACLocations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+func isExactLocation(location string) bool {
+	for _, l := range exactLocations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeExactValue 精确值索引使用的统一格式
+func NormalizeExactValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
 // NewACMatcher 创建新的AC自动机匹配器
 func NewACMatcher() *types.ACMatcher {
 	return &types.ACMatcher{
@@ -29,6 +58,10 @@ func NewACMatcher() *types.ACMatcher {
 		TitlePatternMap:   make(map[string]int),
 		HeaderPatternMap:  make(map[string]int),
 		BodyPatternMap:    make(map[string]int),
+		ExtraMatchers:     make(map[string]*goahocorasick.Machine),
+		ExtraPatterns:     make(map[string][]types.PatternInfo),
+		ExtraPatternMap:   make(map[string]map[string][]int),
+		ExactPatterns:     make(map[string]map[string][]string),
 		FingerprintMap:    make(map[string]*types.Fingerprint),
 		NonACFingerprints: make([]*types.Fingerprint, 0),
 	}
@@ -65,6 +98,19 @@ func LoadFingerprintWithID(filepath string) (*types.Fingerprint, error) {
 		return nil, fmt.Errorf("failed to read fingerprint file: %w", err)
 	}
 
+	return ParseFingerprintYaml(data)
+}
+
+// ParseFingerprintYaml 解析指纹YAML，兼容带fingerprint包装（与FingerprintRules中存储的格式一致）和不带包装两种格式
+func ParseFingerprintYaml(data []byte) (*types.Fingerprint, error) {
+	var wrapped types.FingerprintYaml
+	if err := yaml.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if wrapped.Fingerprint.Name != "" || len(wrapped.Fingerprint.Rules) != 0 {
+		return &wrapped.Fingerprint, nil
+	}
+
 	var fingerprint types.Fingerprint
 	if err := yaml.Unmarshal(data, &fingerprint); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
@@ -118,6 +164,25 @@ func extractPatternsFromConditionGroup(conditions []types.Condition, logic strin
 				RuleIndex:     ruleIndex,
 			})
 		}
+		// 其他文本位置：equals忽略大小写，AC自动机区分大小写，只对contains预匹配
+		if condition.MatchType == "contains" &&
+			isExtraACLocation(condition.Location) && condition.Pattern != "" {
+			allPatterns = append(allPatterns, types.PatternInfo{
+				Pattern:       condition.Pattern,
+				Location:      condition.Location,
+				FingerprintID: fingerprint.ID,
+				RuleIndex:     ruleIndex,
+			})
+		}
+		// 精确值位置：只有equals可以通过索引查找
+		if condition.MatchType == "equals" && isExactLocation(condition.Location) && condition.Pattern != "" {
+			allPatterns = append(allPatterns, types.PatternInfo{
+				Pattern:       NormalizeExactValue(condition.Pattern),
+				Location:      condition.Location,
+				FingerprintID: fingerprint.ID,
+				RuleIndex:     ruleIndex,
+			})
+		}
 	}
 
 	if len(allPatterns) == 0 {
@@ -198,7 +263,7 @@ func deduplicatePatterns(patterns []types.PatternInfo) []types.PatternInfo {
 }
 
 // selectBestPattern 根据优先级和长度选择最佳pattern（用于AND逻辑）
-// 优先级：title > header > body
+// 优先级：favicon/jarm > 证书 > title > header/server/cookie > body/url_path > status_code
 // 对于相同优先级，选择最长的
 func selectBestPattern(candidates []types.PatternInfo) *types.PatternInfo {
 	if len(candidates) == 0 {
@@ -207,9 +272,18 @@ func selectBestPattern(candidates []types.PatternInfo) *types.PatternInfo {
 
 	// 定义优先级
 	priority := map[string]int{
-		"title":  3,
-		"header": 2,
-		"body":   1,
+		"favicon_mmh3": 6,
+		"favicon_md5":  6,
+		"jarm":         5,
+		"cert_subject": 4,
+		"cert_issuer":  4,
+		"title":        3,
+		"header":       2,
+		"server":       2,
+		"cookie":       2,
+		"body":         1,
+		"url_path":     1,
+		"status_code":  0,
 	}
 
 	var best *types.PatternInfo
@@ -222,7 +296,7 @@ func selectBestPattern(candidates []types.PatternInfo) *types.PatternInfo {
 		length := len(candidate.Pattern)
 
 		// 优先级更高，或者优先级相同但长度更长
-		if p > bestPriority || (p == bestPriority && length > bestLength) {
+		if best == nil || p > bestPriority || (p == bestPriority && length > bestLength) {
 			best = candidate
 			bestPriority = p
 			bestLength = length
@@ -241,6 +315,7 @@ func BuildACMatcher(fingerprints []*types.Fingerprint) *types.ACMatcher {
 	titlePatternMap := make(map[string]types.PatternInfo)
 	headerPatternMap := make(map[string]types.PatternInfo)
 	bodyPatternMap := make(map[string]types.PatternInfo)
+	extraPatternMap := make(map[string]map[string]types.PatternInfo)
 
 	// 遍历所有fingerprint，建立FingerprintMap（优化内存，避免在PatternInfo中重复存储）
 	for _, fingerprint := range fingerprints {
@@ -294,6 +369,26 @@ func BuildACMatcher(fingerprints []*types.Fingerprint) *types.ACMatcher {
 						if _, exists := bodyPatternMap[key]; !exists {
 							bodyPatternMap[key] = patternInfo
 						}
+					default:
+						if isExactLocation(patternInfo.Location) {
+							// 精确值索引，同一个值可能对应多个指纹
+							if matcher.ExactPatterns[patternInfo.Location] == nil {
+								matcher.ExactPatterns[patternInfo.Location] = make(map[string][]string)
+							}
+							ids := matcher.ExactPatterns[patternInfo.Location][patternInfo.Pattern]
+							if !containsString(ids, patternInfo.FingerprintID) {
+								matcher.ExactPatterns[patternInfo.Location][patternInfo.Pattern] = append(ids, patternInfo.FingerprintID)
+							}
+							continue
+						}
+						if extraPatternMap[patternInfo.Location] == nil {
+							extraPatternMap[patternInfo.Location] = make(map[string]types.PatternInfo)
+						}
+						// 同一个pattern保留所有指纹
+						key += ":" + patternInfo.FingerprintID
+						if _, exists := extraPatternMap[patternInfo.Location][key]; !exists {
+							extraPatternMap[patternInfo.Location][key] = patternInfo
+						}
 					}
 				}
 			}
@@ -394,6 +489,27 @@ func BuildACMatcher(fingerprints []*types.Fingerprint) *types.ACMatcher {
 		bodyPatternsRune = nil
 	}
 
+	// 其他文本位置的AC自动机
+	for location, patternMap := range extraPatternMap {
+		matcher.ExtraPatternMap[location] = make(map[string][]int)
+		patternsRune := make([][]rune, 0, len(patternMap))
+		for _, patternInfo := range patternMap {
+			indexes, exists := matcher.ExtraPatternMap[location][patternInfo.Pattern]
+			if !exists {
+				patternsRune = append(patternsRune, []rune(patternInfo.Pattern))
+			}
+			matcher.ExtraPatternMap[location][patternInfo.Pattern] = append(indexes, len(matcher.ExtraPatterns[location]))
+			matcher.ExtraPatterns[location] = append(matcher.ExtraPatterns[location], patternInfo)
+		}
+		machine := &goahocorasick.Machine{}
+		if err := machine.Build(patternsRune); err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("构建%v AC自动机失败: %v", location, err))
+			continue
+		}
+		matcher.ExtraMatchers[location] = machine
+	}
+	extraPatternMap = nil
+
 	// 清理临时数据
 	titlePatterns = nil
 	headerPatterns = nil
@@ -402,6 +518,15 @@ func BuildACMatcher(fingerprints []*types.Fingerprint) *types.ACMatcher {
 	return matcher
 }
 
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 //
 //func main() {
 //	//test_main()
@@ -9,17 +9,55 @@ package contextmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"sync"
+	"time"
 )
 
 // ContextManager 管理多个上下文的结构体
 type ContextManager struct {
 	mu         sync.Mutex
-	contexts   map[string]context.Context    // 存储上下文
-	cancels    map[string]context.CancelFunc // 存储取消函数
-	waitGroups map[string]*sync.WaitGroup    // 存储每个任务的 WaitGroup
+	contexts   map[string]context.Context         // 存储上下文
+	cancels    map[string]context.CancelFunc      // 存储取消函数
+	waitGroups map[string]*sync.WaitGroup         // 存储每个任务的 WaitGroup
+	pauses     map[string]chan struct{}           // 暂停中的任务，恢复时关闭
+	expires    map[string]context.CancelCauseFunc // 截止时间到期时取消任务上下文
+	deadlines  map[string]*taskDeadline           // 任务的截止时间
+}
+
+// taskDeadline 任务截止时间，到期时以 context.DeadlineExceeded 为原因取消任务上下文
+// 任务暂停时停止计时并记录剩余的时间预算，恢复时重新计算截止时间
+type taskDeadline struct {
+	at        time.Time
+	timer     *time.Timer
+	remaining time.Duration // 暂停时剩余的时间预算，timer 为nil时有效
+}
+
+// arm 从现在开始计时剩余的时间预算
+func (d *taskDeadline) arm(remaining time.Duration, expire context.CancelCauseFunc) {
+	d.at = time.Now().Add(remaining)
+	d.remaining = 0
+	d.timer = time.AfterFunc(remaining, func() { expire(context.DeadlineExceeded) })
+}
+
+// pause 停止计时，记录剩余的时间预算
+func (d *taskDeadline) pause() {
+	if d.timer == nil {
+		return
+	}
+	d.timer.Stop()
+	d.timer = nil
+	d.remaining = max(time.Until(d.at), 0)
+}
+
+// left 剩余的时间预算
+func (d *taskDeadline) left() time.Duration {
+	if d.timer == nil {
+		return d.remaining
+	}
+	return max(time.Until(d.at), 0)
 }
 
 // Global map to store all ContextManagers by their IDs
@@ -31,6 +69,9 @@ func NewContextManager() {
 		contexts:   make(map[string]context.Context),
 		cancels:    make(map[string]context.CancelFunc),
 		waitGroups: make(map[string]*sync.WaitGroup),
+		pauses:     make(map[string]chan struct{}),
+		expires:    make(map[string]context.CancelCauseFunc),
+		deadlines:  make(map[string]*taskDeadline),
 	}
 }
 
@@ -45,14 +86,81 @@ func (cm *ContextManager) AddContext(taskID string) {
 		return
 	}
 	// 创建新的上下文及其取消函数
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
 
 	// 添加到管理器
 	cm.contexts[taskID] = ctx
-	cm.cancels[taskID] = cancel
+	cm.cancels[taskID] = func() { cancel(context.Canceled) }
+	cm.expires[taskID] = cancel
 	cm.waitGroups[taskID] = &sync.WaitGroup{}
 }
 
+// SetDeadline 为任务上下文设置截止时间，时间预算用尽后任务上下文结束，context.Cause 为 context.DeadlineExceeded
+// 取消的是任务原来的上下文，设置截止时间之前获取的上下文同样会结束
+// 任务处于暂停状态时只记录剩余的时间预算，恢复后开始计时
+func (cm *ContextManager) SetDeadline(taskID string, deadline time.Time) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	expire, ok := cm.expires[taskID]
+	if !ok {
+		return
+	}
+	remaining := max(time.Until(deadline), 0)
+	// 已经设置了相同或更少的剩余时间
+	d, ok := cm.deadlines[taskID]
+	if ok {
+		if remaining >= d.left() {
+			return
+		}
+		d.pause()
+	} else {
+		d = &taskDeadline{}
+		cm.deadlines[taskID] = d
+	}
+	if _, paused := cm.pauses[taskID]; paused {
+		d.remaining = remaining
+		logger.SlogInfoLocal(fmt.Sprintf("task %v is paused, remaining time budget: %v", taskID, remaining.Round(time.Second)))
+		return
+	}
+	d.arm(remaining, expire)
+	logger.SlogInfoLocal(fmt.Sprintf("task %v deadline: %v", taskID, d.at.Format("2006-01-02 15:04:05")))
+}
+
+// BudgetRemaining 任务剩余的时间预算，暂停期间不减少，任务没有设置截止时间时返回false
+func (cm *ContextManager) BudgetRemaining(taskID string) (time.Duration, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	d, ok := cm.deadlines[taskID]
+	if !ok {
+		return 0, false
+	}
+	return d.left(), true
+}
+
+// ModuleContext 模块处理单条数据的上下文，budget大于0时在任务上下文的基础上增加超时
+func (cm *ContextManager) ModuleContext(taskID string, budget time.Duration) (context.Context, context.CancelFunc) {
+	ctx := cm.GetContext(taskID)
+	if budget <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, budget)
+}
+
+// PluginContext 插件运行使用的上下文，模块设置了带时间预算的上下文时使用该上下文，否则使用任务上下文
+func (cm *ContextManager) PluginContext(ctx context.Context, taskID string) context.Context {
+	if ctx != nil {
+		return ctx
+	}
+	return cm.GetContext(taskID)
+}
+
+// BudgetExhausted 上下文是否因为时间预算用尽而结束，任务被取消时返回false
+func BudgetExhausted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), context.DeadlineExceeded)
+}
+
 // CancelContext 取消指定任务的上下文
 func (cm *ContextManager) CancelContext(taskID string) {
 	cm.mu.Lock()
@@ -64,6 +172,68 @@ func (cm *ContextManager) CancelContext(taskID string) {
 	}
 }
 
+// PauseContext 暂停指定任务，正在运行的插件处理完当前数据，各模块不再读取新的输入
+func (cm *ContextManager) PauseContext(taskID string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if _, ok := cm.pauses[taskID]; ok {
+		return
+	}
+	cm.pauses[taskID] = make(chan struct{})
+	// 暂停期间不消耗时间预算
+	if d, ok := cm.deadlines[taskID]; ok {
+		d.pause()
+	}
+	logger.SlogInfo(fmt.Sprintf("pause task success: %v", taskID))
+}
+
+// ResumeContext 恢复暂停的任务
+func (cm *ContextManager) ResumeContext(taskID string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if gate, ok := cm.pauses[taskID]; ok {
+		close(gate)
+		delete(cm.pauses, taskID)
+		if d, ok := cm.deadlines[taskID]; ok && d.timer == nil {
+			d.arm(d.remaining, cm.expires[taskID])
+		}
+		logger.SlogInfo(fmt.Sprintf("resume task success: %v", taskID))
+	}
+}
+
+// IsPaused 任务是否处于暂停状态
+func (cm *ContextManager) IsPaused(taskID string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	_, ok := cm.pauses[taskID]
+	return ok
+}
+
+// WaitIfPaused 任务暂停时阻塞直到恢复，任务被取消时返回false
+func (cm *ContextManager) WaitIfPaused(taskID string) bool {
+	cm.mu.Lock()
+	gate, paused := cm.pauses[taskID]
+	ctx, exists := cm.contexts[taskID]
+	cm.mu.Unlock()
+
+	if !paused {
+		return true
+	}
+	if !exists {
+		<-gate
+		return true
+	}
+	select {
+	case <-gate:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // CancelAllContexts 取消所有上下文
 func (cm *ContextManager) CancelAllContexts() {
 	cm.mu.Lock()
@@ -97,9 +267,22 @@ func (cm *ContextManager) DeleteContext(taskID string) {
 
 	// 删除上下文、取消函数和 WaitGroup
 	if _, ok := cm.contexts[taskID]; ok {
+		if cancel, ok := cm.cancels[taskID]; ok {
+			cancel()
+		}
+		// 释放截止时间的计时器
+		if d, ok := cm.deadlines[taskID]; ok {
+			d.pause()
+		}
 		delete(cm.contexts, taskID)
 		delete(cm.cancels, taskID)
+		delete(cm.expires, taskID)
+		delete(cm.deadlines, taskID)
 		delete(cm.waitGroups, taskID)
+		if gate, paused := cm.pauses[taskID]; paused {
+			close(gate)
+			delete(cm.pauses, taskID)
+		}
 		logger.SlogInfoLocal(fmt.Sprintf("Context %s deleted\n", taskID))
 	} else {
 		logger.SlogInfoLocal(fmt.Sprintf("Context %s not found\n", taskID))
@@ -0,0 +1,302 @@
+// control-------------------------------------
+// @file      : api.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 11:40
+// -------------------------------------------
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/configupdater"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/runner"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/task"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var startTime = time.Now()
+
+// routes 控制接口的路由
+//
+//	GET  /api/status                              节点状态
+//	GET  /api/tasks                               正在运行的任务和目标
+//	GET  /api/tasks/{id}                          任务中正在运行的目标及其进度
+//	GET  /api/modules                             各模块的协程池和输入积压
+//	GET  /api/plugins                             已加载的插件及安装、检查状态
+//	GET  /api/config                              配置文件版本
+//	GET  /api/assets/{host}/timeline              资产变化时间线，参数 port、assetId、severity、limit
+//	POST /api/tasks/{id}/stop|pause|resume        停止、暂停、恢复任务
+//	POST /api/plugins/{module}/{id}/reinstall     重新安装插件
+//	POST /api/plugins/{module}/{id}/recheck       重新检查插件
+//	POST /api/plugins/{id}/reload                 从数据库重新加载插件，id 为插件在数据库中的id
+func routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", status)
+	mux.HandleFunc("GET /api/tasks", tasks)
+	mux.HandleFunc("GET /api/tasks/{id}", taskDetail)
+	mux.HandleFunc("GET /api/modules", modules)
+	mux.HandleFunc("GET /api/plugins", pluginList)
+	mux.HandleFunc("GET /api/config", configVersions)
+	mux.HandleFunc("GET /api/assets/{host}/timeline", assetTimeline)
+	mux.HandleFunc("POST /api/tasks/{id}/{op}", taskOperation)
+	mux.HandleFunc("POST /api/plugins/{module}/{id}/{op}", pluginOperation)
+	mux.HandleFunc("POST /api/plugins/{id}/reload", pluginReload)
+	return mux
+}
+
+func status(w http.ResponseWriter, r *http.Request) {
+	running, finished := handler.TaskHandle.GetRunFin()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"node":       global.AppConfig.NodeName,
+		"version":    global.VERSION,
+		"state":      global.AppConfig.State,
+		"draining":   shutdown.IsDraining(),
+		"uptime":     time.Since(startTime).Round(time.Second).String(),
+		"tasks":      len(task.Running()),
+		"running":    running,
+		"finished":   finished,
+		"goroutines": runtime.NumGoroutine(),
+	})
+}
+
+type taskInfo struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Priority int                   `json:"priority"`
+	Paused   bool                  `json:"paused"`
+	Targets  []runner.ActiveTarget `json:"targets"`
+}
+
+func runningTasks() []taskInfo {
+	targets := make(map[string][]runner.ActiveTarget)
+	for _, t := range runner.Active() {
+		targets[t.TaskID] = append(targets[t.TaskID], t)
+	}
+	list := []taskInfo{}
+	for id, priority := range task.Running() {
+		info := taskInfo{
+			ID:       id,
+			Priority: priority,
+			Paused:   contextmanager.GlobalContextManagers.IsPaused(id),
+			Targets:  targets[id],
+		}
+		if len(info.Targets) > 0 {
+			info.Name = info.Targets[0].TaskName
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority > list[j].Priority
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+func tasks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, runningTasks())
+}
+
+func taskDetail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	for _, info := range runningTasks() {
+		if info.ID != id {
+			continue
+		}
+		// 目标的模块进度记录在redis中，与服务端看到的一致
+		progress := make(map[string]map[string]string, len(info.Targets))
+		for _, t := range info.Targets {
+			key := "TaskInfo:progress:" + id + ":" + t.Target
+			values, err := redis.RedisClient.Client().HGetAll(r.Context(), key).Result()
+			if err != nil {
+				values = map[string]string{"error": err.Error()}
+			}
+			progress[t.Target] = values
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"task":     info,
+			"progress": progress,
+		})
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("task %v is not running on this node", id))
+}
+
+func modules(w http.ResponseWriter, r *http.Request) {
+	backlog := make(map[string]int)
+	for _, t := range runner.Active() {
+		for module, n := range t.Backlog {
+			backlog[module] += n
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"pools":   pool.PoolManage.Stats(),
+		"backlog": backlog,
+	})
+}
+
+type pluginInfo struct {
+	Module  string `json:"module"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Install string `json:"install"`
+	Check   string `json:"check"`
+}
+
+func pluginList(w http.ResponseWriter, r *http.Request) {
+	// 安装和检查结果记录在redis中，1为成功 0为失败
+	key := fmt.Sprintf("NodePlg:%v", global.AppConfig.NodeName)
+	state, err := redis.RedisClient.Client().HGetAll(r.Context(), key).Result()
+	if err != nil {
+		logger.SlogWarnLocal(fmt.Sprintf("control api get plugin state error: %v", err))
+	}
+	list := []pluginInfo{}
+	for _, plg := range plugins.GlobalPluginManager.List() {
+		list = append(list, pluginInfo{
+			Module:  plg.GetModule(),
+			ID:      plg.GetPluginId(),
+			Name:    plg.GetName(),
+			Install: state[plg.GetPluginId()+"_install"],
+			Check:   state[plg.GetPluginId()+"_check"],
+		})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+type fileVersion struct {
+	Path     string    `json:"path"`
+	SHA256   string    `json:"sha256"`
+	Modified time.Time `json:"modified"`
+}
+
+func fileInfo(path string) (fileVersion, error) {
+	v := fileVersion{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return v, err
+	}
+	sum := sha256.Sum256(data)
+	v.SHA256 = hex.EncodeToString(sum[:])
+	if info, err := os.Stat(path); err == nil {
+		v.Modified = info.ModTime()
+	}
+	return v, nil
+}
+
+func configVersions(w http.ResponseWriter, r *http.Request) {
+	files := make(map[string]interface{})
+	for name, path := range map[string]string{
+		"config":  global.ConfigPath,
+		"modules": config.ModulesConfigPath,
+	} {
+		v, err := fileInfo(path)
+		if err != nil {
+			files[name] = map[string]string{"path": path, "error": err.Error()}
+			continue
+		}
+		files[name] = v
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"version": global.VERSION,
+		"files":   files,
+	})
+}
+
+// assetTimeline 按时间倒序返回资产的变化记录，默认最多100条
+func assetTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+	timeline, err := results.Results.AssetTimeline(query.Get("assetId"), r.PathValue("host"), query.Get("port"), query.Get("severity"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if timeline == nil {
+		timeline = []types.AssetChangeLog{}
+	}
+	writeJSON(w, http.StatusOK, timeline)
+}
+
+// taskOperation 与 stop_task、pause_task、resume_task 消息使用相同的处理方法
+func taskOperation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch r.PathValue("op") {
+	case "stop":
+		handler.TaskHandle.StopTask(id)
+	case "pause":
+		handler.TaskHandle.PauseTask(id)
+	case "resume":
+		handler.TaskHandle.ResumeTask(id)
+	default:
+		writeError(w, http.StatusNotFound, "unknown operation")
+		return
+	}
+	logger.SlogInfo(fmt.Sprintf("control api %v task %v", r.PathValue("op"), id))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// pluginOperation 与 re_install_plugin、re_check_plugin 消息使用相同的处理方法
+func pluginOperation(w http.ResponseWriter, r *http.Request) {
+	data := r.PathValue("id") + "_" + r.PathValue("module")
+	if !loaded(r.PathValue("module"), r.PathValue("id")) {
+		writeError(w, http.StatusNotFound, "plugin not found")
+		return
+	}
+	switch r.PathValue("op") {
+	case "reinstall":
+		configupdater.ReInstall(data)
+	case "recheck":
+		configupdater.ReCheck(data)
+	default:
+		writeError(w, http.StatusNotFound, "unknown operation")
+		return
+	}
+	logger.SlogInfo(fmt.Sprintf("control api %v plugin %v", r.PathValue("op"), data))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// pluginReload 与 install_plugin 消息使用相同的处理方法
+func pluginReload(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	configupdater.InstallPlugin(id)
+	logger.SlogInfo(fmt.Sprintf("control api reload plugin %v", id))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// loaded 只查找已加载的插件，不触发懒加载
+func loaded(module string, id string) bool {
+	for _, plg := range plugins.GlobalPluginManager.List() {
+		if plg.GetModule() == module && plg.GetPluginId() == id {
+			return true
+		}
+	}
+	return false
+}
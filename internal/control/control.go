@@ -0,0 +1,103 @@
+// control-------------------------------------
+// @file      : control.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 11:00
+// -------------------------------------------
+
+package control
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultListen 默认只监听本地地址
+const defaultListen = "127.0.0.1:9531"
+
+// Serve 开启节点本地控制接口，没有开启时直接返回
+// 接口与redis消息使用相同的处理方法，请求需要携带 Authorization: Bearer <token>
+func Serve(cfg global.ControlConfig) error {
+	if !cfg.Enable {
+		return nil
+	}
+	addr := cfg.Listen
+	if addr == "" {
+		addr = defaultListen
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			logger.SlogWarnLocal(fmt.Sprintf("control api listen on non-loopback address %v", addr))
+		}
+	}
+	token := cfg.Token
+	if token == "" {
+		var err error
+		token, err = generateToken()
+		if err != nil {
+			return fmt.Errorf("control api token: %v", err)
+		}
+		logger.SlogInfoLocal(fmt.Sprintf("control api token generated and saved to %v", global.ConfigPath))
+	}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           auth(token, routes()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logger.SlogInfoLocal(fmt.Sprintf("control api listen on %v", addr))
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("control api %v: %v", addr, err)
+	}
+	return nil
+}
+
+// generateToken 生成访问令牌并写入配置文件，重启后保持不变
+func generateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	global.AppConfig.Control.Token = token
+	if err := utils.Tools.WriteYAMLFile(global.ConfigPath, global.AppConfig); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// auth 校验访问令牌
+func auth(token string, next http.Handler) http.Handler {
+	expected := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,403 @@
+// fingertest-------------------------------------
+// @file      : fingertest.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 21:30
+// -------------------------------------------
+
+package fingertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/configupdater"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/modules/assethandle/webfingerprint"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/projectdiscovery/tlsx/pkg/tlsx/clients"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RuleProblem 指纹文件存在的问题
+type RuleProblem struct {
+	File     string   `json:"file"`
+	Name     string   `json:"name"`
+	Problems []string `json:"problems"`
+}
+
+// Mismatch 指纹在样本上的异常结果
+type Mismatch struct {
+	Sample      string `json:"sample"`
+	Fingerprint string `json:"fingerprint"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// Report 测试报告
+type Report struct {
+	Rules          int                 `json:"rules"`
+	Samples        int                 `json:"samples"`
+	Invalid        []RuleProblem       `json:"invalid"`
+	NonAC          []string            `json:"non_ac"`
+	Skipped        []string            `json:"skipped"`
+	Matches        map[string][]string `json:"matches"`
+	FalsePositives []Mismatch          `json:"false_positives"`
+	Missed         []Mismatch          `json:"missed"`
+	OverBroad      []string            `json:"over_broad"`
+	ACMisses       []Mismatch          `json:"ac_misses"`
+	Errors         []Mismatch          `json:"errors"`
+}
+
+// sample 样本响应
+type sample struct {
+	Name   string
+	Asset  *types.AssetHttp
+	Expect []string // 为nil表示未标注期望结果
+}
+
+// corpusEntry json格式的样本
+type corpusEntry struct {
+	URL         string   `json:"url"`
+	StatusCode  int      `json:"status_code"`
+	Headers     string   `json:"headers"`
+	Body        string   `json:"body"`
+	Title       string   `json:"title"`
+	FaviconMMH3 string   `json:"favicon_mmh3"`
+	IconContent string   `json:"icon_content"` // favicon内容，base64编码
+	Jarm        string   `json:"jarm"`
+	CertSubject string   `json:"cert_subject"` // 证书使用者DN
+	CertDomains []string `json:"cert_domains"` // 证书中的域名
+	CertIssuer  string   `json:"cert_issuer"`  // 证书颁发者DN
+	Expect      []string `json:"expect"`
+}
+
+// asset 将样本转换为指纹匹配使用的资产，证书信息放入TLSData
+func (e corpusEntry) asset() (*types.AssetHttp, error) {
+	if e.IconContent != "" {
+		if _, err := base64.StdEncoding.DecodeString(e.IconContent); err != nil {
+			return nil, fmt.Errorf("icon_content: %w", err)
+		}
+	}
+	asset := &types.AssetHttp{
+		URL:          e.URL,
+		StatusCode:   e.StatusCode,
+		RawHeaders:   e.Headers,
+		ResponseBody: e.Body,
+		Title:        e.Title,
+		FavIconMMH3:  e.FaviconMMH3,
+		IconContent:  e.IconContent,
+		Jarm:         e.Jarm,
+	}
+	if e.CertSubject != "" || len(e.CertDomains) != 0 || e.CertIssuer != "" {
+		asset.TLSData = &clients.Response{
+			CertificateResponse: &clients.CertificateResponse{
+				SubjectDN: e.CertSubject,
+				Domains:   e.CertDomains,
+				IssuerDN:  e.CertIssuer,
+			},
+		}
+	}
+	return asset, nil
+}
+
+type fingerFile struct {
+	File        string
+	Fingerprint *types.Fingerprint
+}
+
+// Run 执行指纹离线测试，返回进程退出码
+// 0 通过，1 存在错误（规则无效、匹配出错、AC索引漏报），2 参数错误
+func Run(args []string) int {
+	fs := flag.NewFlagSet("fingertest", flag.ContinueOnError)
+	rulesDir := fs.String("rules", "", "fingerprint yaml directory")
+	corpusDir := fs.String("corpus", "", "saved http response directory")
+	format := fs.String("format", "text", "output format: text or json")
+	fpRatio := fs.Float64("fp-ratio", 0.3, "rules matching more than this ratio of samples are reported as over-broad")
+	active := fs.Bool("active", false, "allow rules with active conditions to send requests")
+	strict := fs.Bool("strict", false, "also fail on false positives, missed samples, over-broad and non-AC rules")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *rulesDir == "" {
+		fmt.Fprintln(os.Stderr, "usage: ScopeSentry fingertest -rules <dir> [-corpus <dir>] [-format text|json] [-fp-ratio 0.3] [-active] [-strict]")
+		return 2
+	}
+	// 子命令不读取配置文件也不初始化日志，编译规则时的错误输出到标准错误，不影响json结果
+	if logger.ZapLog == nil {
+		logger.ZapLog = zap.New(zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.Lock(os.Stderr), zapcore.WarnLevel))
+	}
+
+	report := &Report{Matches: make(map[string][]string)}
+	files, err := loadRules(*rulesDir, report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load rules error: %v\n", err)
+		return 2
+	}
+	var fingerprints []*types.Fingerprint
+	for _, f := range files {
+		fingerprints = append(fingerprints, f.Fingerprint)
+	}
+	report.Rules = len(fingerprints)
+
+	matcher := configupdater.BuildACMatcher(fingerprints)
+	for _, fp := range matcher.NonACFingerprints {
+		report.NonAC = append(report.NonAC, fp.Name)
+	}
+	global.WebFingers = &types.WebFingerCore{ACMatcher: matcher}
+
+	if *corpusDir != "" {
+		samples, err := loadCorpus(*corpusDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load corpus error: %v\n", err)
+			return 2
+		}
+		report.Samples = len(samples)
+		runCorpus(fingerprints, matcher, samples, *active, *fpRatio, report)
+	}
+
+	if *format == "json" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printReport(report)
+	}
+
+	if len(report.Invalid) != 0 || len(report.Errors) != 0 || len(report.ACMisses) != 0 {
+		return 1
+	}
+	if *strict && (len(report.FalsePositives) != 0 || len(report.Missed) != 0 || len(report.OverBroad) != 0 || len(report.NonAC) != 0) {
+		return 1
+	}
+	return 0
+}
+
+// loadRules 加载目录下的指纹文件，无效的指纹记录到报告中且不参与匹配
+func loadRules(dir string, report *Report) ([]fingerFile, error) {
+	var files []fingerFile
+	ids := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		lower := strings.ToLower(path)
+		if info.IsDir() || !(strings.HasSuffix(lower, ".yaml") || strings.HasSuffix(lower, ".yml")) {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report.Invalid = append(report.Invalid, RuleProblem{File: rel, Problems: []string{err.Error()}})
+			return nil
+		}
+		fp, err := configupdater.ParseFingerprintYaml(data)
+		if err != nil {
+			report.Invalid = append(report.Invalid, RuleProblem{File: rel, Problems: []string{err.Error()}})
+			return nil
+		}
+		// 线上指纹的ID来自FingerprintRules，离线测试时没有ID则使用文件路径
+		if fp.ID == "" {
+			fp.ID = rel
+		}
+		problems := webfingerprint.ValidateFingerprint(fp)
+		if other, ok := ids[fp.ID]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate id %q, also used by %v", fp.ID, other))
+		}
+		if len(problems) != 0 {
+			report.Invalid = append(report.Invalid, RuleProblem{File: rel, Name: fp.Name, Problems: problems})
+			return nil
+		}
+		ids[fp.ID] = rel
+		files = append(files, fingerFile{File: rel, Fingerprint: fp})
+		return nil
+	})
+	return files, err
+}
+
+// loadCorpus 加载样本目录
+// .json 文件为corpusEntry格式，其他文件为原始HTTP响应
+// 原始响应位于子目录中时，子目录名作为期望命中的指纹名称
+func loadCorpus(dir string) ([]sample, error) {
+	var samples []sample
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(strings.ToLower(path), ".json") {
+			var entry corpusEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("%v: %w", rel, err)
+			}
+			asset, err := entry.asset()
+			if err != nil {
+				return fmt.Errorf("%v: %w", rel, err)
+			}
+			samples = append(samples, sample{Name: rel, Asset: asset, Expect: entry.Expect})
+			return nil
+		}
+		asset, err := parseRawResponse(data)
+		if err != nil {
+			return fmt.Errorf("%v: %w", rel, err)
+		}
+		s := sample{Name: rel, Asset: asset}
+		if parts := strings.Split(filepath.ToSlash(rel), "/"); len(parts) > 1 {
+			s.Expect = []string{parts[0]}
+		}
+		samples = append(samples, s)
+		return nil
+	})
+	return samples, err
+}
+
+// parseRawResponse 解析原始HTTP响应，无法解析时整个文件作为body
+func parseRawResponse(data []byte) (*types.AssetHttp, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		resp = &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader(data)),
+		}
+	}
+	defer resp.Body.Close()
+	return webfingerprint.ResponseToAsset("", resp)
+}
+
+// runCorpus 在所有样本上执行指纹匹配
+// 每个指纹都直接匹配一次，与AC自动机预匹配的候选集对比，发现索引漏报
+func runCorpus(fingerprints []*types.Fingerprint, matcher *types.ACMatcher, samples []sample, active bool, fpRatio float64, report *Report) {
+	var matchable []*types.Fingerprint
+	for _, fp := range fingerprints {
+		if !active && webfingerprint.HasActiveCondition(fp) {
+			report.Skipped = append(report.Skipped, fp.Name)
+			continue
+		}
+		matchable = append(matchable, fp)
+	}
+	nonAC := make(map[string]bool)
+	for _, fp := range matcher.NonACFingerprints {
+		nonAC[fp.ID] = true
+	}
+	for _, s := range samples {
+		candidates := make(map[string]bool)
+		for _, fp := range webfingerprint.AcRun(s.Asset) {
+			candidates[fp.ID] = true
+		}
+		matched := make(map[string]bool)
+		for _, fp := range matchable {
+			ok, err := webfingerprint.MatchFingerprint(fp, s.Asset)
+			if err != nil {
+				report.Errors = append(report.Errors, Mismatch{Sample: s.Name, Fingerprint: fp.Name, Detail: err.Error()})
+				continue
+			}
+			if !ok {
+				continue
+			}
+			matched[strings.ToLower(fp.Name)] = true
+			report.Matches[fp.Name] = append(report.Matches[fp.Name], s.Name)
+			if !candidates[fp.ID] && !nonAC[fp.ID] {
+				report.ACMisses = append(report.ACMisses, Mismatch{Sample: s.Name, Fingerprint: fp.Name})
+			}
+			if s.Expect != nil && !containsFold(s.Expect, fp.Name) {
+				report.FalsePositives = append(report.FalsePositives, Mismatch{Sample: s.Name, Fingerprint: fp.Name})
+			}
+		}
+		for _, name := range s.Expect {
+			if !matched[strings.ToLower(name)] {
+				report.Missed = append(report.Missed, Mismatch{Sample: s.Name, Fingerprint: name})
+			}
+		}
+	}
+	// 样本太少时比例没有意义
+	if len(samples) >= 3 {
+		for name, hits := range report.Matches {
+			if float64(len(hits))/float64(len(samples)) > fpRatio {
+				report.OverBroad = append(report.OverBroad, fmt.Sprintf("%v (%d/%d)", name, len(hits), len(samples)))
+			}
+		}
+		sort.Strings(report.OverBroad)
+	}
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// printReport 输出文本格式报告
+func printReport(report *Report) {
+	fmt.Printf("rules: %d valid, %d invalid; samples: %d\n", report.Rules, len(report.Invalid), report.Samples)
+	if len(report.Invalid) != 0 {
+		fmt.Println("\n[invalid rules]")
+		for _, r := range report.Invalid {
+			fmt.Printf("  %v %v\n", r.File, r.Name)
+			for _, p := range r.Problems {
+				fmt.Printf("    - %v\n", p)
+			}
+		}
+	}
+	printList("non-AC rules (evaluated on every asset)", report.NonAC)
+	printList("skipped rules with active conditions", report.Skipped)
+	if len(report.Matches) != 0 {
+		fmt.Println("\n[matches]")
+		names := make([]string, 0, len(report.Matches))
+		for name := range report.Matches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %v: %v\n", name, strings.Join(report.Matches[name], ", "))
+		}
+	}
+	printMismatches("false positive candidates", report.FalsePositives)
+	printMismatches("missed samples", report.Missed)
+	printList("over-broad rules", report.OverBroad)
+	printMismatches("AC index misses", report.ACMisses)
+	printMismatches("match errors", report.Errors)
+}
+
+func printList(title string, list []string) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("\n[%v]\n", title)
+	for _, v := range list {
+		fmt.Printf("  %v\n", v)
+	}
+}
+
+func printMismatches(title string, list []Mismatch) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("\n[%v]\n", title)
+	for _, m := range list {
+		if m.Detail != "" {
+			fmt.Printf("  %v -> %v: %v\n", m.Sample, m.Fingerprint, m.Detail)
+		} else {
+			fmt.Printf("  %v -> %v\n", m.Sample, m.Fingerprint)
+		}
+	}
+}
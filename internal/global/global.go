@@ -23,7 +23,6 @@ var (
 	AppConfig             Config
 	DisallowedURLFilters  []*regexp.Regexp
 	VERSION               string
-	TaskName              string
 	FirstRun              bool
 	DictPath              string
 	ExtDir                string
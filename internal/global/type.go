@@ -9,12 +9,18 @@ package global
 
 // Config 结构体
 type Config struct {
-	NodeName     string        `yaml:"NodeName"`
-	State        int           `yaml:"state"`
-	TimeZoneName string        `yaml:"TimeZoneName"`
-	Debug        bool          `yaml:"debug"`
-	MongoDB      MongoDBConfig `yaml:"mongodb"`
-	Redis        RedisConfig   `yaml:"redis"`
+	NodeName      string          `yaml:"NodeName"`
+	State         int             `yaml:"state"`
+	TimeZoneName  string          `yaml:"TimeZoneName"`
+	Debug         bool            `yaml:"debug"`
+	MongoDB       MongoDBConfig   `yaml:"mongodb"`
+	Redis         RedisConfig     `yaml:"redis"`
+	MetricsListen string          `yaml:"metricsListen"` // /metrics 监听地址，如 127.0.0.1:9527，为空时不开启
+	Tracing       TracingConfig   `yaml:"tracing"`
+	Log           LogConfig       `yaml:"log"`
+	ShutdownGrace int             `yaml:"shutdownGrace"` // 退出时等待运行中的目标完成的时间（秒），默认30
+	Control       ControlConfig   `yaml:"control"`
+	Signature     SignatureConfig `yaml:"signature"`
 }
 
 type MongoDBConfig struct {
@@ -30,3 +36,36 @@ type RedisConfig struct {
 	Port     string `yaml:"port"`
 	Password string `yaml:"password"`
 }
+
+// TracingConfig 追踪配置，Exporter 为空时不记录
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"` // file / otlp
+	Path     string `yaml:"path"`     // file 导出的文件路径，默认 data/traces.jsonl
+	Endpoint string `yaml:"endpoint"` // otlp 导出的 collector 地址，如 http://127.0.0.1:4318
+	MaxSize  int    `yaml:"maxSize"`  // file 导出的文件大小上限，单位MB，默认100
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Format       string            `yaml:"format"`       // console / json，默认console
+	Level        string            `yaml:"level"`        // 默认日志级别 debug / info / warn / error，为空时根据debug决定
+	ModuleLevels map[string]string `yaml:"moduleLevels"` // 各模块的日志级别，如 PortScan: debug
+	File         string            `yaml:"file"`         // 本地日志文件，默认 data/logs/scan.log，为 off 时不写入
+	MaxSize      int               `yaml:"maxSize"`      // 单个日志文件大小上限，单位MB，默认50
+	MaxBackups   int               `yaml:"maxBackups"`   // 保留的历史日志文件数，默认5
+}
+
+// SignatureConfig 控制消息和插件源码的签名配置
+type SignatureConfig struct {
+	Key             string `yaml:"key"`             // HMAC-SHA256 密钥，与服务端相同
+	AllowUnsigned   bool   `yaml:"allowUnsigned"`   // 没有配置密钥时允许加载插件和处理未签名的控制消息，默认拒绝
+	MaxAge          int    `yaml:"maxAge"`          // 控制消息的有效期（秒），默认300
+	StreamRetention int    `yaml:"streamRetention"` // 控制流消息的有效期（秒），默认7天，需不小于服务端流的保留时间
+}
+
+// ControlConfig 节点本地控制接口配置
+type ControlConfig struct {
+	Enable bool   `yaml:"enable"` // 是否开启，默认关闭
+	Listen string `yaml:"listen"` // 监听地址，默认 127.0.0.1:9531
+	Token  string `yaml:"token"`  // 访问令牌，为空时自动生成并写入配置文件
+}
@@ -39,10 +39,13 @@ func NewNucleiEngine() *nuclei.ThreadSafeNucleiEngine {
 
 func CloseNucleiEngine() {
 	NucleiEngineWg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
 	for _, ne := range NucleiEngines {
 		if ne != nil {
 			ne.Close()
 		}
 	}
+	NucleiEngines = nil
 	Parser = nil
 }
@@ -13,7 +13,9 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/scheduler"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"strings"
@@ -94,6 +96,16 @@ func (h *Handle) ProgressEnd(typ string, target string, taskId string, flag int,
 	}
 }
 
+// ProgressSkip 时间预算用尽时记录跳过的内容，<模块>_skipped:<插件或原因> 记录跳过的次数
+func (h *Handle) ProgressSkip(typ string, target string, taskId string, skipped string) {
+	logger.SlogInfo(fmt.Sprintf("%v module time budget exhausted, skip %v: %v", typ, skipped, target))
+	key := "TaskInfo:progress:" + taskId + ":" + target
+	err := redis.RedisClient.Client().HIncrBy(context.Background(), key, typ+"_skipped:"+skipped, 1).Err()
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("ProgressSkip redis error: %s", err))
+	}
+}
+
 func (h *Handle) TaskEnd(target string, taskId string) {
 	key := "TaskInfo:time:" + taskId
 	err := redis.RedisClient.Set(context.Background(), key, utils.Tools.GetTimeNow())
@@ -117,9 +129,79 @@ func (h *Handle) StopTask(id string) {
 	contextmanager.GlobalContextManagers.CancelContext(id)
 }
 
+// PauseTask 暂停任务，正在运行的目标处理完当前数据后停止，暂停状态保存到本地，节点重启后保持暂停
+func (h *Handle) PauseTask(content string) {
+	for _, id := range strings.Split(content, ",") {
+		logger.SlogInfo(fmt.Sprintf("pause task: %v", id))
+		contextmanager.GlobalContextManagers.PauseContext(id)
+		pool.Share.SetPaused(id, true)
+		// 暂停期间不消耗时间预算，记录剩余的预算，恢复时重新计算截止时间
+		if remaining, ok := contextmanager.GlobalContextManagers.BudgetRemaining(id); ok {
+			err := redis.RedisClient.Set(context.Background(), PausedBudgetKey(id), int64(remaining/time.Second))
+			if err != nil {
+				logger.SlogErrorLocal(fmt.Sprintf("task %v save remaining time budget error: %v", id, err))
+			}
+		}
+		err := pebbledb.PebbleStore.Put([]byte("pause:"+id), []byte(utils.Tools.GetTimeNow()))
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("PebbleStore put pause %v error: %v", id, err))
+		}
+		// 已领取的目标写入磁盘，恢复时从断点继续
+		err = pebbledb.PebbleStore.Flush()
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("PebbleStore flush error: %v", err))
+		}
+	}
+}
+
+// ResumeTask 恢复暂停的任务
+func (h *Handle) ResumeTask(content string) {
+	for _, id := range strings.Split(content, ",") {
+		logger.SlogInfo(fmt.Sprintf("resume task: %v", id))
+		err := pebbledb.PebbleStore.Delete([]byte("pause:" + id))
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("PebbleStore delete pause %v error: %v", id, err))
+		}
+		contextmanager.GlobalContextManagers.ResumeContext(id)
+		pool.Share.SetPaused(id, false)
+		if remaining, ok := contextmanager.GlobalContextManagers.BudgetRemaining(id); ok {
+			ctx := context.Background()
+			deadline := time.Now().Add(remaining)
+			err := redis.RedisClient.SetWithTimeout(ctx, DeadlineKey(id), deadline.Unix(), max(remaining, time.Second))
+			if err != nil {
+				logger.SlogErrorLocal(fmt.Sprintf("task %v save deadline error: %v", id, err))
+			}
+			_ = redis.RedisClient.Del(ctx, PausedBudgetKey(id))
+		}
+	}
+}
+
+// DeadlineKey 任务时间预算的截止时间，所有节点使用同一个截止时间
+func DeadlineKey(id string) string {
+	return "TaskDeadline:" + id
+}
+
+// PausedBudgetKey 任务暂停时剩余的时间预算(秒)，恢复后删除
+func PausedBudgetKey(id string) string {
+	return "TaskBudgetPaused:" + id
+}
+
+// RestorePause 节点重启后恢复任务的暂停状态
+func (h *Handle) RestorePause(id string) {
+	value, _ := pebbledb.PebbleStore.Get([]byte("pause:" + id))
+	if value != nil {
+		logger.SlogInfo(fmt.Sprintf("task %v paused at %v", id, string(value)))
+		contextmanager.GlobalContextManagers.PauseContext(id)
+		pool.Share.SetPaused(id, true)
+	}
+}
+
 func (h *Handle) DeleteTask(content string) {
 	for _, id := range strings.Split(content, ",") {
 		h.StopTask(id)
+		_ = pebbledb.PebbleStore.Delete([]byte("pause:" + id))
+		// 删除周期任务
+		scheduler.Delete(id)
 		prefix := fmt.Sprintf("%s:", id)
 		targets, err := pebbledb.PebbleStore.GetKeysWithPrefix(prefix)
 		if err != nil {
@@ -136,7 +218,13 @@ func (h *Handle) DeleteTask(content string) {
 	}
 }
 
+// TaskRemoved 任务从节点移除时调用，用于确认任务流中的消息
+var TaskRemoved func(id string)
+
 func (h *Handle) PopTaskId(id string) error {
+	if TaskRemoved != nil {
+		TaskRemoved(id)
+	}
 	TaskNodeName := "NodeTask:" + global.AppConfig.NodeName
 	exists, err := redis.RedisClient.Exists(context.Background(), TaskNodeName)
 	if err != nil {
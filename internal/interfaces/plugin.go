@@ -7,6 +7,8 @@
 
 package interfaces
 
+import "context"
+
 type Plugin interface {
 	GetName() string
 	SetName(name string)
@@ -30,3 +32,9 @@ type Plugin interface {
 	Clone() Plugin
 	Log(msg string, tp ...string)
 }
+
+// ContextPlugin 支持设置运行上下文的插件，模块设置了时间预算时在Execute之前传入带超时的上下文
+// 插件保存在 Ctx 字段中，Ctx 为空时使用任务上下文
+type ContextPlugin interface {
+	SetContext(ctx context.Context)
+}
@@ -0,0 +1,202 @@
+// metrics-------------------------------------
+// @file      : metrics.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 10:20
+// -------------------------------------------
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const namespace = "scopesentry"
+
+// Registry 节点的指标注册表，各模块通过 Registry.MustRegister 注册自己的采集器
+var Registry = prometheus.NewRegistry()
+
+var (
+	// PluginExecutions 插件执行次数
+	PluginExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_executions_total",
+		Help:      "Number of plugin executions.",
+	}, []string{"module", "plugin"})
+	// PluginErrors 插件执行返回错误的次数
+	PluginErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "plugin_errors_total",
+		Help:      "Number of plugin executions that returned an error.",
+	}, []string{"module", "plugin"})
+	// PluginDuration 插件执行耗时
+	PluginDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "plugin_duration_seconds",
+		Help:      "Plugin execution duration.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 15, 60, 300, 900, 3600},
+	}, []string{"module", "plugin"})
+	// ResultFlushDuration 结果队列写入数据库的耗时
+	ResultFlushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "result_flush_duration_seconds",
+		Help:      "Duration of flushing a result queue buffer to the database.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"queue"})
+	// ResultFlushItems 结果队列写入数据库的条数
+	ResultFlushItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "result_flush_items_total",
+		Help:      "Number of results flushed to the database.",
+	}, []string{"queue"})
+	// RedisDuration redis命令耗时
+	RedisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis command latency.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+	}, []string{"command"})
+	// RedisErrors redis命令错误次数，不包括 redis.Nil
+	RedisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_command_errors_total",
+		Help:      "Number of failed Redis commands.",
+	}, []string{"command"})
+	// MongoDuration mongodb命令耗时
+	MongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongodb_command_duration_seconds",
+		Help:      "MongoDB command latency.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+	}, []string{"command"})
+	// MongoErrors mongodb命令错误次数
+	MongoErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mongodb_command_errors_total",
+		Help:      "Number of failed MongoDB commands.",
+	}, []string{"command"})
+	// NotificationFailures 通知重试后仍然发送失败的次数
+	NotificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_failures_total",
+		Help:      "Number of notifications that failed after all retries.",
+	}, []string{"type", "name"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		PluginExecutions, PluginErrors, PluginDuration,
+		ResultFlushDuration, ResultFlushItems,
+		RedisDuration, RedisErrors,
+		MongoDuration, MongoErrors,
+		NotificationFailures,
+		inputs,
+	)
+}
+
+// ObservePlugin 记录一次插件执行
+func ObservePlugin(module string, plugin string, start time.Time, err error) {
+	PluginExecutions.WithLabelValues(module, plugin).Inc()
+	PluginDuration.WithLabelValues(module, plugin).Observe(time.Since(start).Seconds())
+	if err != nil {
+		PluginErrors.WithLabelValues(module, plugin).Inc()
+	}
+}
+
+// Gauge 注册一个按需计算的指标
+func Gauge(name string, help string, value func() float64) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// Desc 创建采集器使用的指标描述
+func Desc(name string, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+}
+
+// NodeInfo 记录节点名称和版本
+func NodeInfo(node string, version string) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "node_info",
+		Help:        "Node name and version.",
+		ConstLabels: prometheus.Labels{"node": node, "version": version},
+	}, func() float64 { return 1 }))
+}
+
+// inputCollector 统计正在运行的目标中各模块输入通道积压的数据
+type inputCollector struct {
+	mu     sync.Mutex
+	next   int
+	chans  map[int]map[string]chan interface{}
+	desc   *prometheus.Desc
+	capDes *prometheus.Desc
+}
+
+var inputs = &inputCollector{
+	chans:  make(map[int]map[string]chan interface{}),
+	desc:   Desc("module_input_backlog", "Items waiting in module input channels of running targets.", "module"),
+	capDes: Desc("module_input_capacity", "Capacity of module input channels of running targets.", "module"),
+}
+
+// TrackInputs 统计目标各模块的输入通道，目标运行结束时调用返回的函数
+func TrackInputs(chans map[string]chan interface{}) func() {
+	inputs.mu.Lock()
+	defer inputs.mu.Unlock()
+	id := inputs.next
+	inputs.next++
+	inputs.chans[id] = chans
+	return func() {
+		inputs.mu.Lock()
+		defer inputs.mu.Unlock()
+		delete(inputs.chans, id)
+	}
+}
+
+func (c *inputCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+	ch <- c.capDes
+}
+
+func (c *inputCollector) Collect(ch chan<- prometheus.Metric) {
+	backlog := make(map[string]int)
+	capacity := make(map[string]int)
+	c.mu.Lock()
+	for _, chans := range c.chans {
+		for module, input := range chans {
+			backlog[module] += len(input)
+			capacity[module] += cap(input)
+		}
+	}
+	c.mu.Unlock()
+	for module, n := range backlog {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), module)
+		ch <- prometheus.MustNewConstMetric(c.capDes, prometheus.GaugeValue, float64(capacity[module]), module)
+	}
+}
+
+// Serve 在本地地址上提供 /metrics，addr 为空时不开启
+func Serve(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	err := http.ListenAndServe(addr, mux)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server %v: %v", addr, err)
+	}
+	return nil
+}
@@ -13,8 +13,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -65,6 +67,16 @@ func Initialize() {
 	clientOptions.MaxConnecting = &MaxConnectingValue
 	var MinPoolSizeValue uint64 = 5
 	clientOptions.MinPoolSize = &MinPoolSizeValue
+	// 记录命令耗时
+	clientOptions.SetMonitor(&event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			metrics.MongoDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			metrics.MongoDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+			metrics.MongoErrors.WithLabelValues(e.CommandName).Inc()
+		},
+	})
 	client, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
 		fmt.Printf("mongodb connect error: %v", err)
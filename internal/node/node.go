@@ -14,6 +14,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"github.com/shirou/gopsutil/v3/mem"
@@ -70,6 +71,21 @@ func Register() {
 				continue
 			}
 		}
-		<-ticker
+		select {
+		case <-ticker:
+		case <-shutdown.Draining():
+			// 节点退出时停止心跳，由 Deregister 注销节点
+			return
+		}
+	}
+}
+
+// Deregister 注销节点，服务端不再向该节点下发任务
+func Deregister(ctx context.Context) error {
+	key := "node:" + global.AppConfig.NodeName
+	err := redis.RedisClient.Del(ctx, key)
+	if err != nil {
+		return fmt.Errorf("delete %v: %w", key, err)
 	}
+	return nil
 }
@@ -0,0 +1,249 @@
+// notification-------------------------------------
+// @file      : channel.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 22:05
+// -------------------------------------------
+
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// send 按通道类型发送一批消息
+func send(api types.NotificationApi, module string, messages []*types.NotificationMessage) error {
+	data := newTemplateData(module, messages)
+	content, err := render(templateFor(api, module), data)
+	if err != nil {
+		// 模板错误重试也无法恢复，使用默认模板发送
+		logger.SlogError(fmt.Sprintf("Notification %v template error: %v", channelName(api), err))
+		content, _ = render("", data)
+	}
+	switch strings.ToLower(api.Type) {
+	case "", "custom":
+		return sendCustom(api, content)
+	case "webhook":
+		return postJSON(api.Url, map[string]interface{}{
+			"node":     data.Node,
+			"module":   module,
+			"count":    data.Count,
+			"text":     content,
+			"messages": messages,
+		}, nil)
+	case "slack", "mattermost":
+		return postJSON(api.Url, map[string]interface{}{"text": content}, checkSlack)
+	case "dingtalk":
+		uri := api.Url
+		if api.Secret != "" {
+			timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			sign := hmacBase64(api.Secret, timestamp+"\n"+api.Secret)
+			uri = appendQuery(uri, "timestamp="+timestamp+"&sign="+url.QueryEscape(sign))
+		}
+		return postJSON(uri, map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": content},
+		}, checkErrCode)
+	case "feishu", "lark":
+		payload := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": content},
+		}
+		if api.Secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			payload["timestamp"] = timestamp
+			payload["sign"] = hmacBase64(timestamp+"\n"+api.Secret, "")
+		}
+		return postJSON(api.Url, payload, checkErrCode)
+	case "wecom":
+		return postJSON(api.Url, map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": content},
+		}, checkErrCode)
+	case "email":
+		subject := fmt.Sprintf("[ScopeSentry][%v][%v] %d results", data.Node, module, data.Count)
+		return sendEmail(api.Email, subject, content)
+	default:
+		return fmt.Errorf("unknown notification type: %v", api.Type)
+	}
+}
+
+// sendCustom 自定义接口，将*msg*替换为通知内容
+func sendCustom(api types.NotificationApi, content string) error {
+	content = jsonEscape(content)
+	uri := strings.Replace(api.Url, "*msg*", content, -1)
+	if api.Method == "GET" {
+		_, err := utils.Requests.HttpGet(uri)
+		return err
+	}
+	data := strings.Replace(api.Data, "*msg*", content, -1)
+	err, _ := utils.Requests.HttpPost(uri, []byte(data), api.ContentType)
+	return err
+}
+
+// postJSON 发送json请求，check用于校验响应内容
+func postJSON(uri string, payload interface{}, check func(body []byte) error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	err, resp := utils.Requests.HttpPost(uri, body, "json")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status code %v: %v", resp.StatusCode, string(resp.Body))
+	}
+	if check != nil {
+		return check(resp.Body)
+	}
+	return nil
+}
+
+// checkErrCode 校验钉钉、企业微信(errcode)和飞书(code)的返回码
+func checkErrCode(body []byte) error {
+	var result struct {
+		ErrCode *int   `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		Code    *int   `json:"code"`
+		Msg     string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil
+	}
+	if result.ErrCode != nil && *result.ErrCode != 0 {
+		return fmt.Errorf("errcode %v: %v", *result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != nil && *result.Code != 0 {
+		return fmt.Errorf("code %v: %v", *result.Code, result.Msg)
+	}
+	return nil
+}
+
+// checkSlack slack返回ok，mattermost返回空或json
+func checkSlack(body []byte) error {
+	text := strings.TrimSpace(string(body))
+	if text == "" || text == "ok" || strings.HasPrefix(text, "{") {
+		return nil
+	}
+	return fmt.Errorf("unexpected response: %v", text)
+}
+
+// hmacBase64 HmacSHA256签名后base64编码
+func hmacBase64(key string, data string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func appendQuery(uri string, query string) string {
+	if strings.Contains(uri, "?") {
+		return uri + "&" + query
+	}
+	return uri + "?" + query
+}
+
+// sendEmail 通过SMTP发送邮件，SSL为true时使用隐式TLS（一般为465端口），否则在支持时使用STARTTLS
+func sendEmail(cfg types.NotificationEmail, subject string, content string) error {
+	if cfg.Host == "" || len(cfg.To) == 0 {
+		return fmt.Errorf("email host or recipients is empty")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 25
+		if cfg.SSL {
+			port = 465
+		}
+	}
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if cfg.SSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if !cfg.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	msg := "From: " + from + "\r\n" +
+		"To: " + strings.Join(cfg.To, ", ") + "\r\n" +
+		"Subject: =?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(subject)) + "?=\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: base64\r\n\r\n" +
+		wrapLines(base64.StdEncoding.EncodeToString([]byte(content)), 76)
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// wrapLines 按固定长度换行，邮件正文单行不能超过998字符
+func wrapLines(s string, width int) string {
+	var b strings.Builder
+	for len(s) > width {
+		b.WriteString(s[:width])
+		b.WriteString("\r\n")
+		s = s[width:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
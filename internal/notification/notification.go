@@ -8,22 +8,26 @@
 package notification
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	batchSize     = 20
 	flushInterval = 2 * time.Second // 每2秒从队列中取数据
+	defaultRetry  = 2
 )
 
 type NotificationQueue struct {
-	Queue chan string
+	Queue    chan string
+	Messages chan *types.NotificationMessage
 }
 
 var NotificationQueues = make(map[string]*NotificationQueue)
@@ -33,17 +37,30 @@ func InitializeNotification() {
 	modules := []string{
 		"SubdomainScan", "SubdomainSecurity",
 		"AssetMapping", "PortScan", "URLScan",
-		"URLSecurity", "DirScan", "WebCrawler", "VulnerabilityScan", "PageMonitor", "NewAssets",
+		"URLSecurity", "DirScan", "WebCrawler", "VulnerabilityScan", "PageMonitor", "NewAssets", "AssetChange",
 	}
 	// 初始化模块队列和 Goroutine
 	for _, module := range modules {
 		NotificationQueues[module] = &NotificationQueue{
-			Queue: make(chan string, 50), // 缓存队列大小可以大于 batchSize
+			Queue:    make(chan string, 50), // 缓存队列大小可以大于 batchSize
+			Messages: make(chan *types.NotificationMessage, 50),
 		}
 		go processQueue(module, NotificationQueues[module])
 	}
 }
 
+// Send 发送结构化通知消息，按消息的Module进入对应队列
+func Send(msg *types.NotificationMessage) {
+	if msg == nil || msg.Text == "" {
+		return
+	}
+	mq, ok := NotificationQueues[msg.Module]
+	if !ok {
+		return
+	}
+	mq.Messages <- msg
+}
+
 func processQueue(module string, mq *NotificationQueue) {
 	ticker := time.NewTicker(flushInterval)
 	defer ticker.Stop()
@@ -59,50 +76,159 @@ func processQueue(module string, mq *NotificationQueue) {
 
 // processBatch 从队列中取出最多 batchSize 条数据进行处理
 func processBatch(module string, mq *NotificationQueue) {
-	var buffer = ""
-	num := 0
+	var messages []*types.NotificationMessage
 	// 尝试取出 batchSize 条数据，如果不足则取剩下的所有数据
-	for i := 0; i < batchSize; i++ {
+	for len(messages) < batchSize {
 		select {
-		case msg := <-mq.Queue:
-			num += 1
-			buffer += msg
+		case msg := <-mq.Messages:
+			messages = append(messages, msg)
+			continue
+		case text := <-mq.Queue:
+			// 兼容直接写入文本的调用方
+			if text != "" {
+				messages = append(messages, &types.NotificationMessage{Module: module, Text: text})
+			}
+			continue
 		default:
-			// 如果队列中没有更多数据，跳出循环
-			break
 		}
+		// 如果队列中没有更多数据，跳出循环
+		break
 	}
 
-	if num > 0 {
-		FlushBuffer(module, &buffer)
+	if len(messages) > 0 {
+		Dispatch(module, messages)
 	}
 }
 
-func jsonEscape(s string) string {
-	b, _ := json.Marshal(s)
-	return string(b[1 : len(b)-1])
+// FlushBuffer 发送文本格式的通知，兼容旧的调用方式
+func FlushBuffer(module string, buffer *string) {
+	if *buffer != "" {
+		Dispatch(module, []*types.NotificationMessage{{Module: module, Text: *buffer}})
+	}
+	// 清空缓冲区
+	*buffer = ""
 }
 
-// FlushBuffer 模拟处理队列中的数据
-func FlushBuffer(module string, buffer *string) {
-	*buffer = jsonEscape(*buffer)
-	// 处理消息
-	*buffer = "[" + global.AppConfig.NodeName + "][" + module + "]results:\n" + *buffer
+// Dispatch 按路由规则将消息分发到各通知通道
+func Dispatch(module string, messages []*types.NotificationMessage) {
 	for _, api := range global.NotificationApi {
-		uri := strings.Replace(api.Url, "*msg*", *buffer, -1)
-		if api.Method == "GET" {
-			_, err := utils.Requests.HttpGet(uri)
-			if err != nil {
-				logger.SlogError(fmt.Sprintf("SendNotification %v HTTP Get Error: %v", uri, err))
+		routed := route(api, module, messages)
+		if len(routed) == 0 {
+			continue
+		}
+		go deliver(api, module, routed)
+	}
+}
+
+// route 过滤出通道需要接收的消息，并去掉抑制窗口内已发送过的消息
+func route(api types.NotificationApi, module string, messages []*types.NotificationMessage) []*types.NotificationMessage {
+	if len(api.Modules) != 0 && !containsFold(api.Modules, module) {
+		return nil
+	}
+	var routed []*types.NotificationMessage
+	seen := make(map[string]struct{})
+	for _, msg := range messages {
+		if len(api.Projects) != 0 && !containsFold(api.Projects, msg.Project) {
+			continue
+		}
+		if api.MinSeverity != "" && msg.Severity != "" && utils.Results.SeverityRank(msg.Severity) < utils.Results.SeverityRank(api.MinSeverity) {
+			continue
+		}
+		if api.SuppressWindow > 0 {
+			key := suppressKey(api, module, msg)
+			if _, ok := seen[key]; ok || suppressed(key) {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		routed = append(routed, msg)
+	}
+	return routed
+}
+
+// deliver 发送消息，失败时按指数退避重试
+func deliver(api types.NotificationApi, module string, messages []*types.NotificationMessage) {
+	retry := api.Retry
+	if retry <= 0 {
+		retry = defaultRetry
+	}
+	backoff := time.Second
+	var err error
+	for attempt := 0; attempt <= retry; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = send(api, module, messages); err == nil {
+			// 发送成功后才进入抑制窗口，失败的消息下次仍然会发送
+			if api.SuppressWindow > 0 {
+				markSent(api, module, messages)
 			}
-		} else {
-			data := strings.Replace(api.Data, "*msg*", *buffer, -1)
-			err, _ := utils.Requests.HttpPost(uri, []byte(data), api.ContentType)
-			if err != nil {
-				logger.SlogError(fmt.Sprintf("SendNotification %v HTTP Post Error: %v", uri, err))
+			return
+		}
+	}
+	// 通道地址可能包含密钥，指标中只记录类型和名称
+	channelType := api.Type
+	if channelType == "" {
+		channelType = "custom"
+	}
+	metrics.NotificationFailures.WithLabelValues(channelType, api.Name).Inc()
+	logger.SlogError(fmt.Sprintf("SendNotification %v error after %v retries: %v", channelName(api), retry, err))
+}
+
+var (
+	suppressMu   sync.Mutex
+	suppressKeys = make(map[string]time.Time)
+)
+
+// suppressKey 抑制窗口使用的键，消息没有指定Key时使用消息内容
+func suppressKey(api types.NotificationApi, module string, msg *types.NotificationMessage) string {
+	key := msg.Key
+	if key == "" {
+		key = msg.Text
+	}
+	return channelName(api) + "|" + module + "|" + key
+}
+
+// suppressed 判断消息是否在抑制窗口内
+func suppressed(key string) bool {
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	expire, ok := suppressKeys[key]
+	return ok && time.Now().Before(expire)
+}
+
+// markSent 记录消息的发送时间，抑制窗口内不再发送相同的消息
+func markSent(api types.NotificationApi, module string, messages []*types.NotificationMessage) {
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	now := time.Now()
+	// 定期清理过期的键，避免无限增长
+	if len(suppressKeys) > 10000 {
+		for k, expire := range suppressKeys {
+			if now.After(expire) {
+				delete(suppressKeys, k)
 			}
 		}
 	}
-	// 清空缓冲区
-	*buffer = ""
+	window := time.Duration(api.SuppressWindow) * time.Second
+	for _, msg := range messages {
+		suppressKeys[suppressKey(api, module, msg)] = now.Add(window)
+	}
+}
+
+func channelName(api types.NotificationApi) string {
+	if api.Name != "" {
+		return api.Name
+	}
+	return api.Url
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
 }
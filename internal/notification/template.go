@@ -0,0 +1,79 @@
+// notification-------------------------------------
+// @file      : template.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 22:20
+// -------------------------------------------
+
+package notification
+
+import (
+	"bytes"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"strings"
+	"text/template"
+)
+
+// 默认模板，与旧版本的通知格式保持一致
+const defaultTemplate = "[{{.Node}}][{{.Module}}]results:\n{{.Text}}"
+
+// TemplateData 模板可使用的数据
+type TemplateData struct {
+	Node     string
+	Module   string
+	Count    int
+	Text     string // 所有消息文本拼接
+	Messages []*types.NotificationMessage
+}
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"field": func(msg *types.NotificationMessage, name string) string {
+		return msg.Fields[name]
+	},
+}
+
+// newTemplateData 构建模板数据
+func newTemplateData(module string, messages []*types.NotificationMessage) TemplateData {
+	var text strings.Builder
+	for _, msg := range messages {
+		text.WriteString(msg.Text)
+		if !strings.HasSuffix(msg.Text, "\n") {
+			text.WriteString("\n")
+		}
+	}
+	return TemplateData{
+		Node:     global.AppConfig.NodeName,
+		Module:   module,
+		Count:    len(messages),
+		Text:     text.String(),
+		Messages: messages,
+	}
+}
+
+// templateFor 获取通道在模块下使用的模板，模块模板优先
+func templateFor(api types.NotificationApi, module string) string {
+	if tpl, ok := api.Templates[module]; ok && tpl != "" {
+		return tpl
+	}
+	return api.Template
+}
+
+// render 渲染通知内容，模板为空时使用默认模板
+func render(tpl string, data TemplateData) (string, error) {
+	if tpl == "" {
+		tpl = defaultTemplate
+	}
+	t, err := template.New("notification").Funcs(templateFuncs).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
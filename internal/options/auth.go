@@ -0,0 +1,59 @@
+// options-------------------------------------
+// @file      : auth.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 14:02
+// -------------------------------------------
+
+package options
+
+// AuthProfile 任务的认证配置，按照主机匹配注入到各个扫描插件的请求中
+type AuthProfile struct {
+	Name  string   `bson:"name" json:"name"`
+	Hosts []string `bson:"hosts" json:"hosts"` // 匹配的主机，支持通配符 *.example.com，为空时只匹配任务目标的根域名
+	// 认证类型 static: 固定的请求头和cookie  oauth2: client credentials 获取token  login: 脚本化表单登录
+	Type    string            `bson:"type" json:"type"`
+	Headers map[string]string `bson:"headers" json:"headers"`
+	Cookies map[string]string `bson:"cookies" json:"cookies"`
+	OAuth2  OAuth2Config      `bson:"oauth2" json:"oauth2"`
+	Login   LoginConfig       `bson:"login" json:"login"`
+}
+
+// OAuth2Config oauth2 client credentials 配置
+type OAuth2Config struct {
+	TokenUrl     string `bson:"tokenUrl" json:"tokenUrl"`
+	ClientId     string `bson:"clientId" json:"clientId"`
+	ClientSecret string `bson:"clientSecret" json:"clientSecret"`
+	Scope        string `bson:"scope" json:"scope"`
+	// 客户端凭证的发送方式 basic: Authorization 请求头（默认）  body: 表单参数
+	AuthStyle string `bson:"authStyle" json:"authStyle"`
+}
+
+// LoginConfig 表单登录配置
+type LoginConfig struct {
+	// 登录步骤，按顺序执行，上一步获取的cookie会带到下一步
+	Steps []LoginStep `bson:"steps" json:"steps"`
+	// 从最后一步的响应中提取token的正则，第一个分组为token
+	TokenRegex string `bson:"tokenRegex" json:"tokenRegex"`
+	// token放置的请求头，默认 Authorization
+	TokenHeader string `bson:"tokenHeader" json:"tokenHeader"`
+	// token前缀，例如 "Bearer "
+	TokenPrefix string `bson:"tokenPrefix" json:"tokenPrefix"`
+	// 会话有效性检测地址，为空时不检测
+	CheckUrl string `bson:"checkUrl" json:"checkUrl"`
+	// 会话有效时的状态码，默认200
+	CheckStatus int `bson:"checkStatus" json:"checkStatus"`
+	// 会话有效时响应中需要包含的内容（正则）
+	CheckRegex string `bson:"checkRegex" json:"checkRegex"`
+	// 会话检测间隔（秒），默认300
+	CheckInterval int `bson:"checkInterval" json:"checkInterval"`
+}
+
+// LoginStep 单个登录请求
+type LoginStep struct {
+	Url         string            `bson:"url" json:"url"`
+	Method      string            `bson:"method" json:"method"`
+	Body        string            `bson:"body" json:"body"`
+	ContentType string            `bson:"contentType" json:"contentType"`
+	Headers     map[string]string `bson:"headers" json:"headers"`
+}
@@ -7,7 +7,11 @@
 
 package options
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
 type TaskOptions struct {
 	ID                  string                       //任务ID
@@ -31,10 +35,25 @@ type TaskOptions struct {
 	PassiveScan         []string                     `bson:"PassiveScan" json:"PassiveScan"`                 // 被动扫描模块
 	Parameters          map[string]map[string]string `bson:"Parameters" json:"Parameters"`                   // 各个插件的参数
 	IsRestart           bool                         // 是否为重启后从本地获取缓存中获取的目标
+	SubWork             string                       // 从其他节点领取的子任务内容，为空时表示服务端下发的目标
 	Duplicates          string                       `bson:"duplicates" json:"duplicates"` // 是否忽略已经存储在mongodb中的子域名
 	InputChan           map[string]chan interface{}  // 每个模块的输入
 	ModuleRunWg         *sync.WaitGroup              // 总的WaitGroup
+	TraceCtx            context.Context              // 目标的追踪上下文，模块处理的数据作为其子span
 	SubdomainFilename   string                       // 子域名扫描字典
 	ProtRangeId         string                       // 端口范围在数据库中的id
 	PortRange           string                       // 端口范围
+	AuthProfiles        []AuthProfile                `bson:"authProfiles" json:"authProfiles"` // 认证扫描配置
+	Report              []string                     `bson:"report" json:"report"`             // 任务结束时生成的报告格式 html markdown sarif
+	Schedule            string                       `bson:"schedule" json:"schedule"`         // 周期任务的cron表达式，为空时只运行一次
+	ScheduleRun         bool                         `bson:"scheduleRun" json:"scheduleRun"`   // 是否为定时调度触发的运行
+	Priority            int                          `bson:"priority" json:"priority"`         // 任务优先级，数值越大越优先
+	Weight              int                          `bson:"weight" json:"weight"`             // 同时运行多个任务时各模块协程的分配权重，默认1
+	TimeBudget          int                          `bson:"timeBudget" json:"timeBudget"`     // 任务的时间预算（秒），用尽后跳过剩余的目标，0为不限制
+	ModuleBudget        map[string]int               `bson:"moduleBudget" json:"moduleBudget"` // 各模块处理单条数据的时间预算（秒），用尽后跳过剩余的插件
+}
+
+// ModuleTimeout 模块处理单条数据的时间预算，没有配置时返回0
+func (t *TaskOptions) ModuleTimeout(module string) time.Duration {
+	return time.Duration(t.ModuleBudget[module]) * time.Second
 }
@@ -47,6 +47,11 @@ func (p *PebbleDB) Delete(key []byte) error {
 	return p.db.Delete(key, pebble.Sync)
 }
 
+// Flush 将内存中的数据写入磁盘
+func (p *PebbleDB) Flush() error {
+	return p.db.Flush()
+}
+
 // Close 关闭数据库连接
 func (p *PebbleDB) Close() error {
 	return p.db.Close()
@@ -12,6 +12,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/signature"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/symbols"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/customplugin"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
@@ -42,7 +43,11 @@ func GetCustomPlugin() ([]interfaces.Plugin, error) {
 			moduleName := filepath.Base(dir)
 
 			filename := filepath.Base(path)
-			extension := filepath.Ext(filename)              // 获取文件的扩展名
+			extension := filepath.Ext(filename) // 获取文件的扩展名
+			// 插件签名文件
+			if extension != ".go" {
+				return nil
+			}
 			plgId := strings.TrimSuffix(filename, extension) // 去掉扩展名
 			plugin, err := LoadCustomPlugin(path, moduleName, plgId)
 			if err != nil {
@@ -64,6 +69,19 @@ func GetCustomPlugin() ([]interfaces.Plugin, error) {
 
 func LoadCustomPlugin(path string, modlue string, plgId string) (interfaces.Plugin, error) {
 	logger.SlogInfoLocal(fmt.Sprintf("Load custom plugin: %v", path))
+	// 只加载签名正确的插件，没有配置密钥时只有配置 allowUnsigned 才加载
+	if !signature.Unsigned() {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sig, _ := os.ReadFile(path + ".sig")
+		err = signature.Verify(strings.TrimSpace(string(sig)), modlue, plgId, string(source))
+		if err != nil {
+			logger.SlogError(fmt.Sprintf("reject plugin %v %v: %v", modlue, plgId, err))
+			return nil, fmt.Errorf("plugin %v signature error: %v", plgId, err)
+		}
+	}
 	// 初始化 yaegi 解释器
 	interp := interp.New(interp.Options{})
 	// 加载标准库和符号
@@ -0,0 +1,53 @@
+// plugins-------------------------------------
+// @file      : metrics.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 11:20
+// -------------------------------------------
+
+package plugins
+
+import (
+	"context"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
+	"time"
+)
+
+// instrumented 记录插件执行次数、错误和耗时，模块设置了追踪上下文时记录插件的span
+type instrumented struct {
+	interfaces.Plugin
+	ctx context.Context
+}
+
+func instrument(plg interfaces.Plugin) interfaces.Plugin {
+	if _, ok := plg.(*instrumented); ok {
+		return plg
+	}
+	return &instrumented{Plugin: plg}
+}
+
+func (p *instrumented) Execute(input interface{}) (interface{}, error) {
+	ctx, span := tracing.StartPlugin(p.ctx, p.GetModule(), p.GetName(), input)
+	if cp, ok := p.Plugin.(interfaces.ContextPlugin); ok && ctx != nil {
+		cp.SetContext(ctx)
+	}
+	start := time.Now()
+	res, err := p.Plugin.Execute(input)
+	metrics.ObservePlugin(p.GetModule(), p.GetName(), start, err)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (p *instrumented) Clone() interfaces.Plugin {
+	return instrument(p.Plugin.Clone())
+}
+
+// SetContext 插件支持设置上下文时传递给插件
+func (p *instrumented) SetContext(ctx context.Context) {
+	p.ctx = ctx
+	if cp, ok := p.Plugin.(interfaces.ContextPlugin); ok {
+		cp.SetContext(ctx)
+	}
+}
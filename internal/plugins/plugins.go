@@ -14,6 +14,8 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/signature"
+	"github.com/Autumn-27/ScopeSentry-Scan/modules/assethandle/tlsaudit"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/assethandle/webfingerprint"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/assetmapping/httpx"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/dirscan/sentrydir"
@@ -26,16 +28,19 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/targethandler/targetparser"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlscan/katana"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlscan/wayback"
+	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlsecurity/jsanalysis"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlsecurity/pagemonitoring"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlsecurity/sensitive"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlsecurity/trufflehog"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/vulnerabilityscan/nuclei"
+	"github.com/Autumn-27/ScopeSentry-Scan/modules/webcrawler/apidiscovery"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/webcrawler/rad"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"github.com/cloudflare/cfssl/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"path/filepath"
+	"sort"
 	"sync"
 )
 
@@ -63,6 +68,25 @@ func (pm *PluginManager) RegisterPlugin(module string, id string, plugin interfa
 	pm.plugins[module][id] = plugin
 }
 
+// List 已加载的插件，按模块和插件id排序
+func (pm *PluginManager) List() []interfaces.Plugin {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	var list []interfaces.Plugin
+	for _, modPlugins := range pm.plugins {
+		for _, plugin := range modPlugins {
+			list = append(list, plugin)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].GetModule() != list[j].GetModule() {
+			return list[i].GetModule() < list[j].GetModule()
+		}
+		return list[i].GetPluginId() < list[j].GetPluginId()
+	})
+	return list
+}
+
 func (pm *PluginManager) GetPlugin(module, id string) (interfaces.Plugin, bool) {
 	// 先在读锁下查找已注册的插件
 	pm.mu.RLock()
@@ -71,7 +95,7 @@ func (pm *PluginManager) GetPlugin(module, id string) (interfaces.Plugin, bool)
 		if ok {
 			cloned := plugin.Clone()
 			pm.mu.RUnlock()
-			return cloned, true
+			return instrument(cloned), true
 		}
 	}
 	pm.mu.RUnlock()
@@ -96,30 +120,50 @@ func (pm *PluginManager) GetPlugin(module, id string) (interfaces.Plugin, bool)
 		logger.SlogErrorLocal(err.Error())
 		return nil, false
 	}
-	return plg.Clone(), true
+	return instrument(plg.Clone()), true
 }
 
 type PluginInfo struct {
-	Module string `bson:"module"`
-	Hash   string `bson:"hash"`
-	Source string `bson:"source"`
+	Module    string `bson:"module"`
+	Hash      string `bson:"hash"`
+	Source    string `bson:"source"`
+	Signature string `bson:"signature"`
 }
 
 func LoadPlugFromDB(hash string) error {
 	var result PluginInfo
-	err := mongodb.MongodbClient.FindOne("plugins", bson.M{"hash": hash}, bson.M{"module": 1, "hash": 1, "source": 1}, &result)
+	err := mongodb.MongodbClient.FindOne("plugins", bson.M{"hash": hash}, bson.M{"module": 1, "hash": 1, "source": 1, "signature": 1}, &result)
 	if err != nil {
 		return fmt.Errorf("find plugin error: %v", err)
 	}
-	plgPath := filepath.Join(global.PluginDir, result.Module, fmt.Sprintf("%v.go", result.Hash))
-	err = utils.Tools.WriteContentFile(plgPath, result.Source)
+	_, err = WritePlugin(result)
 	if err != nil {
-		return fmt.Errorf("WriteContentFile plugin error: %v", err)
+		return err
 	}
 	logger.SlogInfoLocal(fmt.Sprintf("write plugin end:%v", hash))
 	return nil
 }
 
+// WritePlugin 校验插件源码的签名后写入插件目录，签名写入同名的 .sig 文件，加载插件时再次校验
+func WritePlugin(info PluginInfo) (string, error) {
+	if err := signature.Verify(info.Signature, info.Module, info.Hash, info.Source); err != nil {
+		logger.SlogError(fmt.Sprintf("reject plugin %v %v: %v", info.Module, info.Hash, err))
+		return "", fmt.Errorf("plugin %v signature error: %v", info.Hash, err)
+	}
+	plgPath := filepath.Join(global.PluginDir, info.Module, fmt.Sprintf("%v.go", info.Hash))
+	err := utils.Tools.WriteContentFile(plgPath, info.Source)
+	if err != nil {
+		return "", fmt.Errorf("WriteContentFile plugin error: %v", err)
+	}
+	if info.Signature != "" {
+		err = utils.Tools.WriteContentFile(plgPath+".sig", info.Signature)
+		if err != nil {
+			return "", fmt.Errorf("WriteContentFile plugin signature error: %v", err)
+		}
+	}
+	return plgPath, nil
+}
+
 // InitializePlugins 初始化插件
 func (pm *PluginManager) InitializePlugins() error {
 	// TargetParser
@@ -157,6 +201,10 @@ func (pm *PluginManager) InitializePlugins() error {
 	webFingerprintPlugin := webfingerprint.NewPlugin()
 	pm.RegisterPlugin(webFingerprintPlugin.Module, webFingerprintPlugin.PluginId, webFingerprintPlugin)
 
+	// tlsaudit
+	tlsauditPlugin := tlsaudit.NewPlugin()
+	pm.RegisterPlugin(tlsauditPlugin.Module, tlsauditPlugin.PluginId, tlsauditPlugin)
+
 	// katana
 	katanaPlugin := katana.NewPlugin()
 	pm.RegisterPlugin(katanaPlugin.Module, katanaPlugin.PluginId, katanaPlugin)
@@ -168,6 +216,10 @@ func (pm *PluginManager) InitializePlugins() error {
 	// rad
 	radPlugin := rad.NewPlugin()
 	pm.RegisterPlugin(radPlugin.Module, radPlugin.PluginId, radPlugin)
+
+	// apidiscovery
+	apidiscoveryPlugin := apidiscovery.NewPlugin()
+	pm.RegisterPlugin(apidiscoveryPlugin.Module, apidiscoveryPlugin.PluginId, apidiscoveryPlugin)
 	// sensitive
 	sensitivePlugin := sensitive.NewPlugin()
 	pm.RegisterPlugin(sensitivePlugin.Module, sensitivePlugin.PluginId, sensitivePlugin)
@@ -180,6 +232,10 @@ func (pm *PluginManager) InitializePlugins() error {
 	pagemonitoringPlugin := pagemonitoring.NewPlugin()
 	pm.RegisterPlugin(pagemonitoringPlugin.Module, pagemonitoringPlugin.PluginId, pagemonitoringPlugin)
 
+	// jsanalysis
+	jsanalysisPlugin := jsanalysis.NewPlugin()
+	pm.RegisterPlugin(jsanalysisPlugin.Module, jsanalysisPlugin.PluginId, jsanalysisPlugin)
+
 	// SentryDir
 	dirPlugin := sentrydir.NewPlugin()
 	pm.RegisterPlugin(dirPlugin.Module, dirPlugin.PluginId, dirPlugin)
@@ -0,0 +1,257 @@
+// pool-------------------------------------
+// @file      : fairshare.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/22 10:30
+// -------------------------------------------
+
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// FairShare 多个任务同时运行时按优先级和权重分配每个模块的协程预算
+// 每个任务在模块中的份额 = 预算 * 权重 / 该模块中活跃任务的权重之和，至少为1
+// 份额内的请求直接放行；超出份额时，只有没有其他任务在份额内等待、也没有更高优先级的任务等待时才能借用空闲的协程
+// 高优先级任务领取目标期间，低优先级任务在目标边界处暂停领取新目标
+type FairShare struct {
+	mu     sync.Mutex
+	tasks  map[string]*shareTask
+	used   map[string]int
+	budget func(module string) int
+	wake   chan struct{}
+}
+
+type shareTask struct {
+	priority int
+	weight   int
+	busy     bool // 是否正在领取目标
+	paused   bool // 任务暂停中，不阻塞低优先级任务
+	used     map[string]int
+	waiting  map[string]int
+}
+
+// Share 全局的协程份额管理
+var Share = NewFairShare(func(module string) int {
+	if PoolManage == nil {
+		return 1
+	}
+	size, err := PoolManage.GetModulePoolSize(module)
+	if err != nil {
+		return 1
+	}
+	return size
+})
+
+// NewFairShare 创建份额管理，budget 返回模块的协程预算
+func NewFairShare(budget func(module string) int) *FairShare {
+	return &FairShare{
+		tasks:  make(map[string]*shareTask),
+		used:   make(map[string]int),
+		budget: budget,
+		wake:   make(chan struct{}),
+	}
+}
+
+// Register 登记任务的优先级和权重，权重小于1时按1处理
+func (f *FairShare) Register(taskId string, priority int, weight int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := f.task(taskId)
+	t.priority = priority
+	if weight < 1 {
+		weight = 1
+	}
+	t.weight = weight
+	t.busy = true
+	f.broadcast()
+}
+
+// Unregister 任务结束，移除登记
+func (f *FairShare) Unregister(taskId string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.tasks[taskId]; ok {
+		for module, n := range t.used {
+			f.used[module] -= n
+		}
+		delete(f.tasks, taskId)
+		f.broadcast()
+	}
+}
+
+// SetBusy 设置任务是否正在领取目标，没有可领取的目标时设置为false，不再阻塞低优先级任务
+func (f *FairShare) SetBusy(taskId string, busy bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := f.task(taskId)
+	if t.busy != busy {
+		t.busy = busy
+		f.broadcast()
+	}
+}
+
+// SetPaused 设置任务是否暂停，暂停的任务不阻塞低优先级任务领取目标，只修改已登记的任务
+func (f *FairShare) SetPaused(taskId string, paused bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.tasks[taskId]
+	if !ok || t.paused == paused {
+		return
+	}
+	t.paused = paused
+	f.broadcast()
+}
+
+// WaitTurn 领取新目标前调用，存在更高优先级且正在领取目标的任务时阻塞
+func (f *FairShare) WaitTurn(ctx context.Context, taskId string) error {
+	f.mu.Lock()
+	for {
+		if !f.preempted(taskId) {
+			f.mu.Unlock()
+			return nil
+		}
+		wake := f.wake
+		f.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+		f.mu.Lock()
+	}
+}
+
+// Acquire 获取任务在模块中的一个协程，超出份额且有其他任务等待时阻塞
+func (f *FairShare) Acquire(ctx context.Context, taskId string, module string) error {
+	f.mu.Lock()
+	t := f.task(taskId)
+	t.waiting[module]++
+	for !f.allowed(taskId, module) {
+		wake := f.wake
+		f.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			f.mu.Lock()
+			f.rejoin(taskId, module, t).waiting[module]--
+			f.broadcast()
+			f.mu.Unlock()
+			return ctx.Err()
+		case <-wake:
+		}
+		f.mu.Lock()
+		t = f.rejoin(taskId, module, t)
+	}
+	t.waiting[module]--
+	t.used[module]++
+	f.used[module]++
+	f.mu.Unlock()
+	return nil
+}
+
+// Release 释放Acquire获取的协程
+func (f *FairShare) Release(taskId string, module string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.tasks[taskId]
+	if !ok || t.used[module] == 0 {
+		return
+	}
+	t.used[module]--
+	f.used[module]--
+	f.broadcast()
+}
+
+// Quota 返回任务在模块中的份额
+func (f *FairShare) Quota(taskId string, module string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.quota(taskId, module)
+}
+
+// Used 返回任务在模块中正在使用的协程数
+func (f *FairShare) Used(taskId string, module string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t, ok := f.tasks[taskId]; ok {
+		return t.used[module]
+	}
+	return 0
+}
+
+// task 获取任务登记信息，没有登记的任务按优先级0权重1处理
+func (f *FairShare) task(taskId string) *shareTask {
+	t, ok := f.tasks[taskId]
+	if !ok {
+		t = &shareTask{weight: 1, used: make(map[string]int), waiting: make(map[string]int)}
+		f.tasks[taskId] = t
+	}
+	return t
+}
+
+// rejoin 等待期间任务被移除登记时，将等待的请求转移到新的登记信息中
+func (f *FairShare) rejoin(taskId string, module string, t *shareTask) *shareTask {
+	cur := f.task(taskId)
+	if cur != t {
+		cur.waiting[module]++
+	}
+	return cur
+}
+
+func (f *FairShare) quota(taskId string, module string) int {
+	t, ok := f.tasks[taskId]
+	if !ok {
+		return 0
+	}
+	total := 0
+	for id, o := range f.tasks {
+		if id == taskId || o.used[module] > 0 || o.waiting[module] > 0 {
+			total += o.weight
+		}
+	}
+	q := f.budget(module) * t.weight / total
+	if q < 1 {
+		q = 1
+	}
+	return q
+}
+
+func (f *FairShare) allowed(taskId string, module string) bool {
+	if f.used[module] >= f.budget(module) {
+		return false
+	}
+	t := f.tasks[taskId]
+	if t.used[module] < f.quota(taskId, module) {
+		return true
+	}
+	for id, o := range f.tasks {
+		if id == taskId || o.waiting[module] == 0 {
+			continue
+		}
+		if o.priority > t.priority || o.used[module] < f.quota(id, module) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *FairShare) preempted(taskId string) bool {
+	t, ok := f.tasks[taskId]
+	if !ok {
+		return false
+	}
+	for id, o := range f.tasks {
+		if id != taskId && o.busy && !o.paused && o.priority > t.priority {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcast 唤醒所有等待的请求重新检查
+func (f *FairShare) broadcast() {
+	close(f.wake)
+	f.wake = make(chan struct{})
+}
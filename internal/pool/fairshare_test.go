@@ -0,0 +1,253 @@
+// pool-------------------------------------
+// @file      : fairshare_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 15:10
+// -------------------------------------------
+
+package pool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const module = "PortScan"
+
+func budget(n int) func(string) int {
+	return func(string) int { return n }
+}
+
+// acquireAsync 在协程中申请，返回申请成功时关闭的通道
+func acquireAsync(t *testing.T, f *FairShare, ctx context.Context, taskId string) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- f.Acquire(ctx, taskId, module) }()
+	return done
+}
+
+func waitAcquired(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("acquire blocked")
+	}
+}
+
+func assertBlocked(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("acquire should block, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestFairShareQuota(t *testing.T) {
+	f := NewFairShare(budget(6))
+	f.Register("a", 0, 1)
+	f.Register("b", 0, 2)
+	ctx := context.Background()
+	if q := f.Quota("a", module); q != 6 {
+		t.Fatalf("quota of the only active task = %v, want 6", q)
+	}
+	// 只有正在使用或者等待该模块的任务参与分配
+	if err := f.Acquire(ctx, "b", module); err != nil {
+		t.Fatal(err)
+	}
+	if q := f.Quota("b", module); q != 6 {
+		t.Fatalf("quota of the only active task = %v, want 6", q)
+	}
+	if err := f.Acquire(ctx, "a", module); err != nil {
+		t.Fatal(err)
+	}
+	if q := f.Quota("a", module); q != 2 {
+		t.Fatalf("quota of weight 1 = %v, want 2", q)
+	}
+	if q := f.Quota("b", module); q != 4 {
+		t.Fatalf("quota of weight 2 = %v, want 4", q)
+	}
+	f.Unregister("b")
+	if q := f.Quota("a", module); q != 6 {
+		t.Fatalf("quota after unregister = %v, want 6", q)
+	}
+}
+
+func TestFairShareBorrowAndGiveBack(t *testing.T) {
+	f := NewFairShare(budget(4))
+	f.Register("a", 0, 1)
+	f.Register("b", 0, 1)
+	ctx := context.Background()
+	// 没有其他任务等待时，a 可以借用全部协程
+	for i := 0; i < 4; i++ {
+		waitAcquired(t, acquireAsync(t, f, ctx, "a"))
+	}
+	b := acquireAsync(t, f, ctx, "b")
+	assertBlocked(t, b)
+	// a 超出份额，释放的协程交给 b，a 的新请求等待
+	a := acquireAsync(t, f, ctx, "a")
+	assertBlocked(t, a)
+	f.Release("a", module)
+	waitAcquired(t, b)
+	assertBlocked(t, a)
+	if used := f.Used("b", module); used != 1 {
+		t.Fatalf("b used = %v, want 1", used)
+	}
+	// b 没有等待的请求时，a 继续借用空闲的协程
+	f.Release("a", module)
+	waitAcquired(t, a)
+	if used := f.Used("a", module); used != 3 {
+		t.Fatalf("a used = %v, want 3", used)
+	}
+}
+
+// TestFairShareNoStarvation 模拟两个插件持续提交，占满协程的任务不能饿死后来的任务
+func TestFairShareNoStarvation(t *testing.T) {
+	f := NewFairShare(budget(4))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.Register("a", 0, 1)
+	f.Register("b", 0, 1)
+
+	var running sync.WaitGroup
+	var max [2]atomic.Int32
+	var done [2]atomic.Int32
+	var inUse [2]atomic.Int32
+	run := func(i int, taskId string, workers int) {
+		for w := 0; w < workers; w++ {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				for ctx.Err() == nil {
+					if f.Acquire(ctx, taskId, module) != nil {
+						return
+					}
+					n := inUse[i].Add(1)
+					for {
+						m := max[i].Load()
+						if n <= m || max[i].CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					inUse[i].Add(-1)
+					done[i].Add(1)
+					f.Release(taskId, module)
+				}
+			}()
+		}
+	}
+	// a 先开始并占满所有协程
+	run(0, "a", 8)
+	time.Sleep(20 * time.Millisecond)
+	run(1, "b", 8)
+	time.Sleep(300 * time.Millisecond)
+	start := [2]int32{done[0].Load(), done[1].Load()}
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+	running.Wait()
+
+	a, b := done[0].Load()-start[0], done[1].Load()-start[1]
+	if a == 0 || b == 0 {
+		t.Fatalf("a task is starved: a=%v b=%v", a, b)
+	}
+	if a > 2*b || b > 2*a {
+		t.Fatalf("unfair share: a=%v b=%v", a, b)
+	}
+	if max[1].Load() < 2 {
+		t.Fatalf("b never reached its quota: max %v", max[1].Load())
+	}
+}
+
+func TestFairSharePriority(t *testing.T) {
+	f := NewFairShare(budget(2))
+	ctx := context.Background()
+	f.Register("low", 0, 1)
+	f.Register("high", 10, 1)
+
+	turn := make(chan error, 1)
+	go func() { turn <- f.WaitTurn(ctx, "low") }()
+	assertBlocked(t, turn)
+	if err := f.WaitTurn(ctx, "high"); err != nil {
+		t.Fatal(err)
+	}
+	// 高优先级任务没有可领取的目标后，低优先级任务继续领取
+	f.SetBusy("high", false)
+	waitAcquired(t, turn)
+
+	// 协程被占满时，释放的协程优先交给高优先级任务
+	waitAcquired(t, acquireAsync(t, f, ctx, "low"))
+	waitAcquired(t, acquireAsync(t, f, ctx, "low"))
+	high := acquireAsync(t, f, ctx, "high")
+	low := acquireAsync(t, f, ctx, "low")
+	assertBlocked(t, high)
+	f.Release("low", module)
+	waitAcquired(t, high)
+	assertBlocked(t, low)
+	// 等待期间移除登记的任务按没有登记的任务继续等待
+	f.Unregister("low")
+	f.Release("high", module)
+	waitAcquired(t, low)
+	f.Release("low", module)
+	if used := f.Used("low", module); used != 0 {
+		t.Fatalf("low used = %v, want 0", used)
+	}
+}
+
+func TestFairSharePaused(t *testing.T) {
+	f := NewFairShare(budget(2))
+	ctx := context.Background()
+	f.Register("low", 0, 1)
+	f.Register("high", 10, 1)
+
+	turn := make(chan error, 1)
+	go func() { turn <- f.WaitTurn(ctx, "low") }()
+	assertBlocked(t, turn)
+	// 暂停的高优先级任务不阻塞低优先级任务
+	f.SetPaused("high", true)
+	waitAcquired(t, turn)
+	// 恢复后重新让出
+	f.SetPaused("high", false)
+	go func() { turn <- f.WaitTurn(ctx, "low") }()
+	assertBlocked(t, turn)
+	f.Unregister("high")
+	waitAcquired(t, turn)
+	// 没有登记的任务不会被创建
+	f.SetPaused("other", true)
+	if _, ok := f.tasks["other"]; ok {
+		t.Fatal("SetPaused registered an unknown task")
+	}
+}
+
+func TestFairShareCancel(t *testing.T) {
+	f := NewFairShare(budget(1))
+	f.Register("a", 0, 1)
+	f.Register("b", 0, 1)
+	waitAcquired(t, acquireAsync(t, f, context.Background(), "a"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := acquireAsync(t, f, ctx, "b")
+	assertBlocked(t, b)
+	cancel()
+	select {
+	case err := <-b:
+		if err == nil {
+			t.Fatal("canceled acquire returned nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("canceled acquire blocked")
+	}
+	// 取消的请求不再占用份额
+	if q := f.Quota("a", module); q != 1 {
+		t.Fatalf("quota after cancel = %v, want 1", q)
+	}
+	f.Release("a", module)
+	waitAcquired(t, acquireAsync(t, f, context.Background(), "a"))
+}
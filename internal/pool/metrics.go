@@ -0,0 +1,45 @@
+// pool-------------------------------------
+// @file      : metrics.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 10:50
+// -------------------------------------------
+
+package pool
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector 采集各模块协程池的大小和占用
+type poolCollector struct {
+	size    *prometheus.Desc
+	running *prometheus.Desc
+	waiting *prometheus.Desc
+}
+
+func init() {
+	metrics.Registry.MustRegister(&poolCollector{
+		size:    metrics.Desc("pool_size", "Goroutine pool capacity of each module.", "module"),
+		running: metrics.Desc("pool_running", "Running goroutines of each module pool.", "module"),
+		waiting: metrics.Desc("pool_waiting", "Tasks blocked waiting for a goroutine of each module pool.", "module"),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.running
+	ch <- c.waiting
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	if PoolManage == nil {
+		return
+	}
+	for module, stat := range PoolManage.Stats() {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(stat.Size), module)
+		ch <- prometheus.MustNewConstMetric(c.running, prometheus.GaugeValue, float64(stat.Running), module)
+		ch <- prometheus.MustNewConstMetric(c.waiting, prometheus.GaugeValue, float64(stat.Waiting), module)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/panjf2000/ants/v2"
@@ -100,6 +101,22 @@ func (pm *Manager) SubmitTask(moduleName string, task func()) error {
 	return pool.Submit(task)
 }
 
+// SubmitTaskFor 按任务的协程份额提交，超出份额时等待，任务取消时返回错误
+func (pm *Manager) SubmitTaskFor(taskId string, moduleName string, task func()) error {
+	err := Share.Acquire(contextmanager.GlobalContextManagers.GetContext(taskId), taskId, moduleName)
+	if err != nil {
+		return err
+	}
+	err = pm.SubmitTask(moduleName, func() {
+		defer Share.Release(taskId, moduleName)
+		task()
+	})
+	if err != nil {
+		Share.Release(taskId, moduleName)
+	}
+	return err
+}
+
 func (pm *Manager) GetModulePoolSize(module string) (int, error) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
@@ -158,6 +175,24 @@ func (pm *Manager) PrintRunningGoroutines(sortedModuleNames []string) {
 	}
 }
 
+// PoolStat 模块协程池的使用情况
+type PoolStat struct {
+	Size    int `json:"size"`
+	Running int `json:"running"`
+	Waiting int `json:"waiting"`
+}
+
+// Stats 各模块协程池的使用情况
+func (pm *Manager) Stats() map[string]PoolStat {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	stats := make(map[string]PoolStat, len(pm.pools))
+	for module, p := range pm.pools {
+		stats[module] = PoolStat{Size: p.Cap(), Running: p.Running(), Waiting: p.Waiting()}
+	}
+	return stats
+}
+
 func (pm *Manager) GetModuleRunningGoroutines(module string) int {
 	running := pm.pools[module].Running()
 	return running
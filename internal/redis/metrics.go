@@ -0,0 +1,48 @@
+// redis-------------------------------------
+// @file      : metrics.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 11:05
+// -------------------------------------------
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// metricsHook 记录redis命令的耗时和错误
+type metricsHook struct{}
+
+func (metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		observe(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		observe("pipeline", start, err)
+		return err
+	}
+}
+
+func observe(command string, start time.Time, err error) {
+	metrics.RedisDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, redis.Nil) {
+		metrics.RedisErrors.WithLabelValues(command).Inc()
+	}
+}
@@ -62,6 +62,7 @@ func Initialize() {
 		fmt.Printf("failed to connect to Redis: %v", err)
 	}
 
+	client.AddHook(metricsHook{})
 	RedisClient = &Client{client: client}
 }
 
@@ -131,6 +132,47 @@ func (r *Client) SAdd(ctx context.Context, key string, members ...interface{}) (
 	return r.client.SAdd(ctx, key, members...).Result()
 }
 
+// SRem 从集合中删除成员
+func (r *Client) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return r.client.SRem(ctx, key, members...).Result()
+}
+
+// SetNX 键不存在时设置，返回是否设置成功
+func (r *Client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	if r.client == nil {
+		return false, errors.New("redis client nil")
+	}
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
+// Get 获取字符串键的值
+func (r *Client) Get(ctx context.Context, key string) (string, error) {
+	return r.client.Get(ctx, key).Result()
+}
+
+// DelPattern 使用SCAN删除匹配的所有键，返回删除的数量
+func (r *Client) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, 500).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) != 0 {
+			n, err := r.client.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
 func (r *Client) SMembers(ctx context.Context, key string) ([]string, error) {
 	return r.client.SMembers(ctx, key).Result()
 }
@@ -0,0 +1,62 @@
+// report-------------------------------------
+// @file      : cli.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 11:50
+// -------------------------------------------
+
+package report
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Run report子命令，从mongodb中读取任务结果生成报告，返回进程退出码
+func Run(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	taskId := fs.String("task", "", "task id, used as the report directory name")
+	taskName := fs.String("name", "", "task name")
+	format := fs.String("format", strings.Join(Formats, ","), "report formats: html,markdown,sarif")
+	out := fs.String("out", "", "output directory (default data/report/<task>)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *taskName == "" {
+		fmt.Fprintln(os.Stderr, "usage: ScopeSentry report -name <task name> [-task <task id>] [-format html,markdown,sarif] [-out <dir>]")
+		return 2
+	}
+	if *taskId == "" {
+		*taskId = *taskName
+	}
+	config.Initialize()
+	mongodb.Initialize()
+	utils.InitializeTools()
+	utils.InitializeResults()
+
+	r, err := Collect(*taskId, *taskName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "collect error: %v\n", err)
+		return 1
+	}
+	dir := *out
+	if dir == "" {
+		dir = filepath.Join(global.AbsolutePath, "data", "report", *taskId)
+	}
+	files, err := Generate(r, dir, strings.Split(*format, ","))
+	for _, file := range files {
+		fmt.Println(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+// report-------------------------------------
+// @file      : html.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 11:05
+// -------------------------------------------
+
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 漏洞等级从高到低
+var severityOrder = []string{"critical", "high", "medium", "low", "info", "unknown"}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lower":        strings.ToLower,
+	"join":         strings.Join,
+	"truncate":     truncate,
+	"address":      assetAddress,
+	"status":       statusText,
+	"fingerprints": fingerprints,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.TaskName}} 扫描报告</title>
+<style>
+body{font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;margin:32px;color:#222}
+h1{border-bottom:2px solid #333;padding-bottom:8px}
+h2{margin-top:32px;border-left:4px solid #409eff;padding-left:8px}
+table{border-collapse:collapse;width:100%;margin:12px 0;font-size:13px}
+th,td{border:1px solid #ddd;padding:6px 8px;text-align:left;vertical-align:top;word-break:break-all}
+th{background:#f5f7fa}
+.sev{display:inline-block;padding:2px 8px;border-radius:4px;color:#fff;font-size:12px}
+.critical{background:#8b0000}.high{background:#e6453c}.medium{background:#f08c00}.low{background:#409eff}.info,.unknown{background:#909399}
+.summary td:first-child{width:200px}
+</style>
+</head>
+<body>
+<h1>{{.TaskName}} 扫描报告</h1>
+<p>任务ID: {{.TaskId}}<br>生成时间: {{.GeneratedAt}}</p>
+<h2>概览</h2>
+<table class="summary">
+<tr><td>子域名</td><td>{{len .Subdomains}}</td></tr>
+<tr><td>子域名接管</td><td>{{len .Takeovers}}</td></tr>
+<tr><td>资产</td><td>{{len .Assets}}</td></tr>
+<tr><td>敏感信息</td><td>{{len .Sensitive}}</td></tr>
+<tr><td>目录</td><td>{{len .Dirs}}</td></tr>
+<tr><td>漏洞</td><td>{{len .Vulnerabilities}}{{range .Severities}} <span class="sev {{.Level}}">{{.Level}} {{.Count}}</span>{{end}}</td></tr>
+</table>
+{{if .Vulnerabilities}}<h2>漏洞</h2>
+<table><tr><th>等级</th><th>名称</th><th>URL</th><th>匹配</th></tr>
+{{range .Vulnerabilities}}<tr><td><span class="sev {{lower .Level}}">{{.Level}}</span></td><td>{{.VulName}}</td><td>{{.Url}}</td><td>{{truncate .Matched 500}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Takeovers}}<h2>子域名接管</h2>
+<table><tr><th>域名</th><th>CNAME</th></tr>
+{{range .Takeovers}}<tr><td>{{.Input}}</td><td>{{.Cname}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Sensitive}}<h2>敏感信息</h2>
+<table><tr><th>规则</th><th>URL</th><th>匹配内容</th></tr>
+{{range .Sensitive}}<tr><td>{{.SID}}</td><td>{{.Url}}</td><td>{{truncate (join .Match ", ") 500}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Assets}}<h2>资产</h2>
+<table><tr><th>地址</th><th>端口</th><th>服务</th><th>标题</th><th>状态码</th><th>指纹</th></tr>
+{{range .Assets}}<tr><td>{{address .}}</td><td>{{.Port}}</td><td>{{.Service}}</td><td>{{.Title}}</td><td>{{status .StatusCode}}</td><td>{{join (fingerprints .) ", "}}</td></tr>
+{{end}}</table>
+<h2>端口</h2>
+<table><tr><th>主机</th><th>端口</th></tr>
+{{range .Ports}}<tr><td>{{.Host}}</td><td>{{join .Ports ", "}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Dirs}}<h2>目录</h2>
+<table><tr><th>URL</th><th>状态码</th><th>长度</th></tr>
+{{range .Dirs}}<tr><td>{{.Url}}</td><td>{{.Status}}</td><td>{{.Length}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Subdomains}}<h2>子域名</h2>
+<table><tr><th>域名</th><th>类型</th><th>解析</th></tr>
+{{range .Subdomains}}<tr><td>{{.Host}}</td><td>{{.Type}}</td><td>{{join .Value ", "}} {{join .IP ", "}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
+
+type severityItem struct {
+	Level string
+	Count int
+}
+
+type hostPorts struct {
+	Host  string
+	Ports []string
+}
+
+// HTML 生成html格式报告
+func (r *Report) HTML() ([]byte, error) {
+	var severities []severityItem
+	for _, level := range severityOrder {
+		if count := r.SeverityCount[level]; count != 0 {
+			severities = append(severities, severityItem{Level: level, Count: count})
+		}
+	}
+	var ports []hostPorts
+	for host, p := range r.Ports() {
+		ports = append(ports, hostPorts{Host: host, Ports: p})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Host < ports[j].Host })
+	data := struct {
+		*Report
+		Severities []severityItem
+		Ports      []hostPorts
+	}{r, severities, ports}
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// assetAddress http资产显示URL，其他资产显示host
+func assetAddress(asset Asset) string {
+	if asset.URL != "" {
+		return asset.URL
+	}
+	return asset.Host
+}
+
+func statusText(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return strconv.Itoa(code)
+}
+
+// fingerprints 资产的指纹，有版本信息时显示版本
+func fingerprints(asset Asset) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, product := range asset.Products {
+		name := product.Name
+		if product.Version != "" {
+			name += " " + product.Version
+		}
+		result = append(result, name)
+		seen[strings.ToLower(product.Name)] = true
+	}
+	for _, tech := range asset.Technologies {
+		if !seen[strings.ToLower(tech)] {
+			result = append(result, tech)
+		}
+	}
+	if asset.Version != "" && asset.Service != "" {
+		result = append(result, asset.Service+" "+asset.Version)
+	}
+	return result
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
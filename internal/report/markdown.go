@@ -0,0 +1,98 @@
+// report-------------------------------------
+// @file      : markdown.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 10:40
+// -------------------------------------------
+
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Markdown 生成markdown格式报告
+func (r *Report) Markdown() ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %v 扫描报告\n\n", mdEscape(r.TaskName))
+	fmt.Fprintf(&b, "- 任务ID: %v\n- 生成时间: %v\n\n", r.TaskId, r.GeneratedAt)
+
+	b.WriteString("## 概览\n\n")
+	b.WriteString("| 类型 | 数量 |\n| --- | --- |\n")
+	fmt.Fprintf(&b, "| 子域名 | %d |\n| 子域名接管 | %d |\n| 资产 | %d |\n| 敏感信息 | %d |\n| 目录 | %d |\n| 漏洞 | %d |\n\n",
+		len(r.Subdomains), len(r.Takeovers), len(r.Assets), len(r.Sensitive), len(r.Dirs), len(r.Vulnerabilities))
+	if len(r.Vulnerabilities) != 0 {
+		b.WriteString("| 漏洞等级 | 数量 |\n| --- | --- |\n")
+		for _, level := range severityOrder {
+			if count := r.SeverityCount[level]; count != 0 {
+				fmt.Fprintf(&b, "| %v | %d |\n", level, count)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Vulnerabilities) != 0 {
+		b.WriteString("## 漏洞\n\n| 等级 | 名称 | URL | 匹配 |\n| --- | --- | --- | --- |\n")
+		for _, vuln := range r.Vulnerabilities {
+			fmt.Fprintf(&b, "| %v | %v | %v | %v |\n", mdEscape(vuln.Level), mdEscape(vuln.VulName), mdEscape(vuln.Url), mdEscape(vuln.Matched))
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Takeovers) != 0 {
+		b.WriteString("## 子域名接管\n\n| 域名 | CNAME |\n| --- | --- |\n")
+		for _, t := range r.Takeovers {
+			fmt.Fprintf(&b, "| %v | %v |\n", mdEscape(t.Input), mdEscape(t.Cname))
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Sensitive) != 0 {
+		b.WriteString("## 敏感信息\n\n| 规则 | URL | 匹配内容 |\n| --- | --- | --- |\n")
+		for _, s := range r.Sensitive {
+			fmt.Fprintf(&b, "| %v | %v | %v |\n", mdEscape(s.SID), mdEscape(s.Url), mdEscape(truncate(strings.Join(s.Match, ", "), 200)))
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Assets) != 0 {
+		b.WriteString("## 资产\n\n| 地址 | 端口 | 服务 | 标题 | 状态码 | 指纹 |\n| --- | --- | --- | --- | --- | --- |\n")
+		for _, asset := range r.Assets {
+			fmt.Fprintf(&b, "| %v | %v | %v | %v | %v | %v |\n", mdEscape(assetAddress(asset)), asset.Port, mdEscape(asset.Service),
+				mdEscape(asset.Title), statusText(asset.StatusCode), mdEscape(strings.Join(fingerprints(asset), ", ")))
+		}
+		b.WriteString("\n")
+		b.WriteString("## 端口\n\n| 主机 | 端口 |\n| --- | --- |\n")
+		ports := r.Ports()
+		hosts := make([]string, 0, len(ports))
+		for host := range ports {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+		for _, host := range hosts {
+			fmt.Fprintf(&b, "| %v | %v |\n", mdEscape(host), strings.Join(ports[host], ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Dirs) != 0 {
+		b.WriteString("## 目录\n\n| URL | 状态码 | 长度 |\n| --- | --- | --- |\n")
+		for _, d := range r.Dirs {
+			fmt.Fprintf(&b, "| %v | %v | %v |\n", mdEscape(d.Url), d.Status, d.Length)
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Subdomains) != 0 {
+		b.WriteString("## 子域名\n\n| 域名 | 类型 | 解析 |\n| --- | --- | --- |\n")
+		for _, s := range r.Subdomains {
+			fmt.Fprintf(&b, "| %v | %v | %v |\n", mdEscape(s.Host), s.Type, mdEscape(strings.Join(append(s.Value, s.IP...), ", ")))
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// mdEscape 转义表格中的特殊字符
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,178 @@
+// report-------------------------------------
+// @file      : report.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 10:10
+// -------------------------------------------
+
+package report
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats 支持的报告格式
+var Formats = []string{"html", "markdown", "sarif"}
+
+// Asset 报告中的资产信息，兼容http和其他类型资产
+type Asset struct {
+	Host         string          `bson:"host"`
+	IP           string          `bson:"ip"`
+	Port         string          `bson:"port"`
+	Type         string          `bson:"type"`
+	URL          string          `bson:"url"`
+	Title        string          `bson:"title"`
+	StatusCode   int             `bson:"statuscode"`
+	Service      string          `bson:"service"`
+	WebServer    string          `bson:"webServer"`
+	Version      string          `bson:"version"`
+	Technologies []string        `bson:"technologies"`
+	Products     []types.Product `bson:"products"`
+}
+
+// Report 任务报告数据
+type Report struct {
+	TaskId          string
+	TaskName        string
+	GeneratedAt     string
+	Subdomains      []types.SubdomainResult
+	Takeovers       []types.SubTakeResult
+	Assets          []Asset
+	Sensitive       []types.SensitiveResult
+	Dirs            []types.DirResult
+	Vulnerabilities []types.VulnResult
+	// SeverityCount 各等级漏洞数量
+	SeverityCount map[string]int
+}
+
+// Collect 从mongodb中汇总任务的结果，结果通过taskName关联，漏洞和敏感信息通过taskNames关联
+func Collect(taskId string, taskName string) (*Report, error) {
+	if taskName == "" {
+		return nil, fmt.Errorf("task name is empty")
+	}
+	r := &Report{
+		TaskId:        taskId,
+		TaskName:      taskName,
+		GeneratedAt:   utils.Tools.GetTimeNow(),
+		SeverityCount: make(map[string]int),
+	}
+	query := bson.M{"taskName": taskName}
+	// 漏洞和敏感信息跨任务去重，taskName 只记录首次发现的任务，taskNames 记录发现过该结果的所有任务
+	// 忽略（包括误报判定自动忽略的敏感信息）和复扫未复现已关闭的漏洞和敏感信息不写入报告
+	openQuery := bson.M{
+		"$or":    bson.A{bson.M{"taskNames": taskName}, bson.M{"taskName": taskName}},
+		"status": bson.M{"$nin": bson.A{3, 6}},
+	}
+	collections := []struct {
+		name   string
+		query  bson.M
+		result interface{}
+	}{
+		{"subdomain", query, &r.Subdomains},
+		{"SubdomainTakerResult", query, &r.Takeovers},
+		{"asset", query, &r.Assets},
+		{"SensitiveResult", openQuery, &r.Sensitive},
+		{"DirScanResult", query, &r.Dirs},
+		{"vulnerability", openQuery, &r.Vulnerabilities},
+	}
+	for _, c := range collections {
+		if err := mongodb.MongodbClient.FindAll(c.name, c.query, nil, c.result); err != nil {
+			return nil, fmt.Errorf("find %v error: %w", c.name, err)
+		}
+	}
+	sort.Slice(r.Subdomains, func(i, j int) bool { return r.Subdomains[i].Host < r.Subdomains[j].Host })
+	sort.Slice(r.Assets, func(i, j int) bool {
+		if r.Assets[i].Host != r.Assets[j].Host {
+			return r.Assets[i].Host < r.Assets[j].Host
+		}
+		return r.Assets[i].Port < r.Assets[j].Port
+	})
+	// 漏洞按等级从高到低排序
+	sort.SliceStable(r.Vulnerabilities, func(i, j int) bool {
+		return utils.Results.SeverityRank(r.Vulnerabilities[i].Level) > utils.Results.SeverityRank(r.Vulnerabilities[j].Level)
+	})
+	for _, vuln := range r.Vulnerabilities {
+		r.SeverityCount[strings.ToLower(vuln.Level)]++
+	}
+	return r, nil
+}
+
+// Ports 按host汇总开放的端口
+func (r *Report) Ports() map[string][]string {
+	ports := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, asset := range r.Assets {
+		key := asset.Host + ":" + asset.Port
+		if asset.Port == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		ports[asset.Host] = append(ports[asset.Host], asset.Port)
+	}
+	return ports
+}
+
+// Generate 生成指定格式的报告文件，返回生成的文件路径
+func Generate(r *Report, dir string, formats []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, format := range formats {
+		var data []byte
+		var err error
+		var name string
+		switch strings.ToLower(strings.TrimSpace(format)) {
+		case "html":
+			name = "report.html"
+			data, err = r.HTML()
+		case "markdown", "md":
+			name = "report.md"
+			data, err = r.Markdown()
+		case "sarif":
+			name = "report.sarif"
+			data, err = r.Sarif()
+		default:
+			return files, fmt.Errorf("unknown report format: %v", format)
+		}
+		if err != nil {
+			return files, fmt.Errorf("generate %v report error: %w", format, err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// GenerateTaskReport 任务结束时生成报告，报告保存在 data/report/任务ID 目录下
+func GenerateTaskReport(taskId string, taskName string, formats []string) {
+	if len(formats) == 0 {
+		return
+	}
+	start := time.Now()
+	r, err := Collect(taskId, taskName)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("task %v report collect error: %v", taskId, err))
+		return
+	}
+	files, err := Generate(r, filepath.Join(global.AbsolutePath, "data", "report", taskId), formats)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("task %v report generate error: %v", taskId, err))
+		return
+	}
+	logger.SlogInfo(fmt.Sprintf("task %v report generated: %v, time: %v", taskId, strings.Join(files, ", "), time.Since(start)))
+}
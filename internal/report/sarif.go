@@ -0,0 +1,108 @@
+// report-------------------------------------
+// @file      : sarif.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 11:30
+// -------------------------------------------
+
+package report
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/projectdiscovery/sarif"
+	"strings"
+)
+
+// Sarif 生成sarif格式报告，只包含漏洞和敏感信息
+func (r *Report) Sarif() ([]byte, error) {
+	exporter := sarif.NewReport()
+	var rules []sarif.ReportingDescriptor
+	ruleIndex := make(map[string]int)
+	// addRule 注册规则，返回规则在rules中的下标
+	addRule := func(id string, name string, description string, severity string) int {
+		if index, ok := ruleIndex[id]; ok {
+			return index
+		}
+		_, rating := sarifLevel(severity)
+		rules = append(rules, sarif.ReportingDescriptor{
+			Id:               id,
+			Name:             name,
+			ShortDescription: &sarif.MultiformatMessageString{Text: name},
+			FullDescription:  &sarif.MultiformatMessageString{Text: description},
+			Properties: map[string]interface{}{
+				"tags":              []string{"security"},
+				"security-severity": rating,
+			},
+		})
+		ruleIndex[id] = len(rules) - 1
+		return len(rules) - 1
+	}
+
+	for _, vuln := range r.Vulnerabilities {
+		id := vuln.VulnId
+		if id == "" {
+			id = vuln.VulName
+		}
+		index := addRule(id, vuln.VulName, vuln.VulName, vuln.Level)
+		level, _ := sarifLevel(vuln.Level)
+		message := fmt.Sprintf("%v [%v] %v", vuln.VulName, vuln.Level, vuln.Url)
+		if vuln.Matched != "" && vuln.Matched != vuln.Url {
+			message += " matched: " + vuln.Matched
+		}
+		exporter.RegisterResult(sarifResult(id, index, level, message, vuln.Url))
+	}
+	for _, sens := range r.Sensitive {
+		// 敏感信息没有等级，统一按medium处理
+		index := addRule(sens.SID, sens.SID, "sensitive information: "+sens.SID, "medium")
+		message := fmt.Sprintf("%v %v: %v", sens.SID, sens.Url, truncate(strings.Join(sens.Match, ", "), 200))
+		exporter.RegisterResult(sarifResult(sens.SID, index, sarif.Warning, message, sens.Url))
+	}
+
+	exporter.RegisterTool(sarif.ToolComponent{
+		Name:            "ScopeSentry",
+		Organization:    "Autumn-27",
+		Product:         "ScopeSentry-Scan",
+		FullName:        "ScopeSentry-Scan " + global.VERSION,
+		SemanticVersion: global.VERSION,
+		DownloadURI:     "https://github.com/Autumn-27/ScopeSentry-Scan",
+		Rules:           rules,
+	})
+	return exporter.Export()
+}
+
+func sarifResult(id string, index int, level sarif.Level, message string, uri string) sarif.Result {
+	return sarif.Result{
+		RuleId:    id,
+		RuleIndex: index,
+		Level:     level,
+		Kind:      sarif.Open,
+		Message:   &sarif.Message{Text: message},
+		Rule:      sarif.ReportingDescriptorReference{Id: id, Index: index},
+		Locations: []sarif.Location{{
+			Message: &sarif.Message{Text: uri},
+			PhysicalLocation: sarif.PhysicalLocation{
+				ArtifactLocation: sarif.ArtifactLocation{
+					Uri:         uri,
+					Description: &sarif.Message{Text: uri},
+				},
+			},
+		}},
+	}
+}
+
+// sarifLevel 漏洞等级转换为sarif等级和github的security-severity评分
+func sarifLevel(severity string) (sarif.Level, string) {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return sarif.Error, "9.4"
+	case "high":
+		return sarif.Error, "8"
+	case "medium":
+		return sarif.Warning, "5"
+	case "low":
+		return sarif.Note, "2"
+	default:
+		return sarif.None, "1"
+	}
+}
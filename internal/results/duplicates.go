@@ -145,6 +145,48 @@ func (d *duplicate) AssetInMongodb(host string, port string) (bool, string, bson
 	}
 }
 
+// HostInMongodb 判断主机是否已经存在其他端口的资产
+func (d *duplicate) HostInMongodb(host string) bool {
+	var result bson.M
+	err := mongodb.MongodbClient.FindOne("asset", bson.M{"host": host}, bson.M{"_id": 1}, &result)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.SlogErrorLocal(fmt.Sprintf("HostInMongodb error :%s\n", err))
+		}
+		return false
+	}
+	return true
+}
+
+// HostKnownInTask 判断主机在本次扫描之前是否已经存在资产，每个任务中每个主机只查询一次mongodb
+// 本次扫描发现的资产是异步批量写入的，同一主机的后续端口使用第一次查询的结果，不受写入进度影响
+func (d *duplicate) HostKnownInTask(taskId string, host string) bool {
+	key := "duplicates:" + taskId + ":host:" + host
+	if value, err := bigcache.BigCache.Get(key); err == nil {
+		return len(value) != 0 && value[0] == '1'
+	}
+	known := d.HostInMongodb(host)
+	value := []byte{'0'}
+	if known {
+		value[0] = '1'
+	}
+	if err := bigcache.BigCache.Set(key, value); err != nil {
+		logger.SlogError(fmt.Sprintf("Set BigCache error: %v - %v", key, err))
+	}
+	return known
+}
+
+// MarkHostKnown 主机的某个端口已经存在资产，说明扫描之前主机已经存在，不需要再查询mongodb
+func (d *duplicate) MarkHostKnown(taskId string, host string) {
+	key := "duplicates:" + taskId + ":host:" + host
+	if _, err := bigcache.BigCache.Get(key); err == nil {
+		return
+	}
+	if err := bigcache.BigCache.Set(key, []byte{'1'}); err != nil {
+		logger.SlogError(fmt.Sprintf("Set BigCache error: %v - %v", key, err))
+	}
+}
+
 func (d *duplicate) URL(rawUrl string, taskId string) bool {
 	dupKey := d.URLParams(rawUrl)
 	key := "duplicates:" + taskId + ":url:" + dupKey
@@ -235,3 +277,8 @@ func (d *duplicate) UrlDuplicate(urlRaw string) (bool, []string, string) {
 	}
 	return false, []string{}, ""
 }
+
+// ResetTaskLocal 删除本地缓存中任务的去重记录，周期任务再次运行时使用
+func (d *duplicate) ResetTaskLocal(taskId string) int {
+	return bigcache.BigCache.DeletePrefix("duplicates:" + taskId + ":")
+}
@@ -0,0 +1,227 @@
+// results-------------------------------------
+// @file      : finding.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 14:10
+// -------------------------------------------
+
+package results
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// 漏洞和敏感信息的处理状态
+const (
+	statusUnprocessed = 1
+	statusIgnored     = 3 // 忽略的结果不自动关闭
+	statusSuspected   = 4
+	statusConfirmed   = 5
+	statusClosed      = 6 // 复扫未复现已关闭
+)
+
+// VulnFingerprint 漏洞指纹，相同模板在相同URL上由相同匹配器命中视为同一个漏洞
+func VulnFingerprint(vulnId string, rawUrl string, matcherName string) string {
+	_, normalized := NormalizeURL(rawUrl)
+	return utils.Tools.HashXX64String(strings.Join([]string{"vuln", vulnId, normalized, matcherName}, "|"))
+}
+
+// SensitiveFingerprint 敏感信息指纹，相同规则在相同URL上命中视为同一个结果
+func SensitiveFingerprint(sid string, rawUrl string) string {
+	_, normalized := NormalizeURL(rawUrl)
+	return utils.Tools.HashXX64String(strings.Join([]string{"sensitive", sid, normalized}, "|"))
+}
+
+// FindingHost 返回URL或host:port规范化后的host，用于复扫时确定关闭范围
+func FindingHost(target string) string {
+	host, _ := NormalizeURL(target)
+	return host
+}
+
+// NormalizeURL 规范化URL，返回host[:port]和规范化后的URL
+// scheme和host转为小写，去掉默认端口、用户信息、fragment和末尾的/，query参数按名称排序
+// host:port格式原样转为小写，APP:、POST|等非URL格式不做处理，host为空
+func NormalizeURL(raw string) (string, string) {
+	raw = strings.TrimSpace(raw)
+	lower := strings.ToLower(raw)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		if raw == "" || strings.ContainsAny(raw, "|/") || strings.HasPrefix(raw, "APP:") {
+			return "", raw
+		}
+		return lower, lower
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		// ipv6
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return host, u.String()
+}
+
+// upsertFindings 按指纹写入结果，重复发现时更新最后发现时间和次数，保留处理状态、首次发现时间和首次发现的任务
+// taskName 为首次发现的任务，lastTaskName 为最后发现的任务，taskNames 记录发现过该结果的所有任务，按任务查询结果时使用 taskNames
+// 已被自动关闭的结果重新出现时恢复为新结果的状态
+func (r *result) upsertFindings(name string, order []string, findings map[string]interface{}, counts map[string]int) bool {
+	var operations []mongo.WriteModel
+	for _, fp := range order {
+		doc, err := toBsonM(findings[fp])
+		if err != nil {
+			logger.SlogWarnLocal(fmt.Sprintf("%v finding %v marshal error: %v", name, fp, err))
+			continue
+		}
+		status := doc["status"]
+		firstSeen := doc["firstSeen"]
+		taskName := doc["taskName"]
+		delete(doc, "_id")
+		delete(doc, "status")
+		delete(doc, "firstSeen")
+		delete(doc, "taskName")
+		delete(doc, "count")
+		doc["lastTaskName"] = taskName
+		operations = append(operations,
+			mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"fingerprint": fp}).
+				SetUpdate(bson.M{
+					"$set":         doc,
+					"$setOnInsert": bson.M{"status": status, "firstSeen": firstSeen, "taskName": taskName},
+					"$addToSet":    bson.M{"taskNames": taskName},
+					"$inc":         bson.M{"count": counts[fp]},
+					"$unset":       bson.M{"legacy": ""},
+				}).
+				SetUpsert(true),
+			mongo.NewUpdateManyModel().
+				SetFilter(bson.M{"fingerprint": fp, "status": statusClosed}).
+				SetUpdate(bson.M{
+					"$set":   bson.M{"status": status},
+					"$unset": bson.M{"closeTime": ""},
+				}),
+		)
+	}
+	if len(operations) == 0 {
+		return true
+	}
+	_, err := mongodb.MongodbClient.BulkWrite(name, operations)
+	if err != nil {
+		logger.SlogWarnLocal(fmt.Sprintf("upsert %v error: %s", name, err))
+		return false
+	}
+	return true
+}
+
+// closeFindings 关闭本次扫描范围内未复现的结果
+// 范围由扫描的host或URL、来源插件、规则和模板确定，与之前发现结果的任务无关，新建的复扫任务同样会关闭旧任务发现的结果
+func (r *result) closeFindings(name string, c *types.FindingClose) {
+	if c.Before == "" {
+		return
+	}
+	filter := bson.M{
+		"lastSeen": bson.M{"$lt": c.Before},
+		"status":   bson.M{"$nin": []int{statusIgnored, statusClosed}},
+	}
+	if len(c.Hosts) != 0 {
+		filter["host"] = bson.M{"$in": c.Hosts}
+	} else if c.Url != "" {
+		filter["url"] = c.Url
+	} else {
+		return
+	}
+	if c.Source != "" {
+		filter["source"] = c.Source
+	}
+	if len(c.Rules) != 0 {
+		filter["sid"] = bson.M{"$in": c.Rules}
+	}
+	if len(c.VulnIds) != 0 {
+		filter["vulnid"] = bson.M{"$in": c.VulnIds}
+	}
+	if len(c.Keep) != 0 {
+		filter["fingerprint"] = bson.M{"$nin": c.Keep}
+	}
+	update := bson.M{"$set": bson.M{"status": statusClosed, "closeTime": utils.Tools.GetTimeNow()}}
+	res, err := mongodb.MongodbClient.UpdateAll(name, filter, update)
+	if err != nil {
+		logger.SlogWarnLocal(fmt.Sprintf("close %v error: %s", name, err))
+		return
+	}
+	if res.ModifiedCount != 0 {
+		logger.SlogInfoLocal(fmt.Sprintf("task %v closed %v %v not reproduced", c.TaskName, res.ModifiedCount, name))
+	}
+}
+
+// InsertSensitive 敏感信息按指纹去重写入
+func (r *result) InsertSensitive(result *[]interface{}) {
+	order, findings, counts := []string{}, make(map[string]interface{}), make(map[string]int)
+	var closes []*types.FindingClose
+	for _, v := range *result {
+		var sensResult *types.SensitiveResult
+		switch tmp := v.(type) {
+		case *types.SensitiveResult:
+			sensResult = tmp
+		case **types.SensitiveResult:
+			if tmp != nil {
+				sensResult = *tmp
+			}
+		case *types.FindingClose:
+			closes = append(closes, tmp)
+			continue
+		}
+		if sensResult == nil {
+			fmt.Println("类型断言失败:", reflect.TypeOf(v))
+			continue
+		}
+		if sensResult.Fingerprint == "" {
+			sensResult.Fingerprint = SensitiveFingerprint(sensResult.SID, sensResult.Url)
+		}
+		sensResult.FirstSeen = sensResult.Time
+		sensResult.LastSeen = sensResult.Time
+		if _, ok := findings[sensResult.Fingerprint]; !ok {
+			order = append(order, sensResult.Fingerprint)
+		}
+		findings[sensResult.Fingerprint] = sensResult
+		counts[sensResult.Fingerprint]++
+	}
+	r.upsertFindings("SensitiveResult", order, findings, counts)
+	for _, c := range closes {
+		r.closeFindings("SensitiveResult", c)
+	}
+}
+
+func toBsonM(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
@@ -0,0 +1,150 @@
+// results-------------------------------------
+// @file      : findingindex.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/11/02 10:30
+// -------------------------------------------
+
+package results
+
+import (
+	"context"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findingCollections 按指纹去重的结果集合，以及复扫关闭时使用的范围字段
+var findingCollections = []struct {
+	name  string
+	scope string
+}{
+	{"vulnerability", "host"},
+	{"SensitiveResult", "url"},
+}
+
+// InitializeFindings 创建指纹的唯一索引和复扫关闭使用的索引，并为升级前没有指纹的结果补充指纹
+// 多个节点同时启动时重复执行不会产生重复的指纹
+func InitializeFindings() {
+	for _, c := range findingCollections {
+		if err := ensureFindingIndexes(c.name, c.scope); err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("%v create index error: %v", c.name, err))
+		}
+		n, err := backfillFingerprints(c.name)
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("%v backfill fingerprint error: %v", c.name, err))
+		}
+		if n != 0 {
+			logger.SlogInfoLocal(fmt.Sprintf("%v backfill fingerprint: %v", c.name, n))
+		}
+	}
+}
+
+// ensureFindingIndexes 指纹为唯一索引，只包含已有指纹的文档，已经存在重复指纹时先去重再创建
+func ensureFindingIndexes(name string, scope string) error {
+	indexes := mongodb.MongodbClient.GetCollection(name).Indexes()
+	_, err := indexes.CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: scope, Value: 1}, {Key: "lastSeen", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	unique := mongo.IndexModel{
+		Keys: bson.D{{Key: "fingerprint", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"fingerprint": bson.M{"$type": "string"}}),
+	}
+	_, err = indexes.CreateOne(context.Background(), unique)
+	if err == nil || !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	if err := dedupFingerprints(name); err != nil {
+		return err
+	}
+	_, err = indexes.CreateOne(context.Background(), unique)
+	return err
+}
+
+// dedupFingerprints 处理创建唯一索引之前并发写入的重复指纹，保留最早的结果，其余结果的指纹加上文档ID
+func dedupFingerprints(name string) error {
+	var groups []struct {
+		Ids []primitive.ObjectID `bson:"ids"`
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{"fingerprint": bson.M{"$type": "string"}}},
+		{"$sort": bson.M{"_id": 1}},
+		{"$group": bson.M{"_id": "$fingerprint", "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}
+	if err := mongodb.MongodbClient.Aggregate(name, pipeline, &groups); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		for _, id := range g.Ids[1:] {
+			update := []bson.M{{"$set": bson.M{"fingerprint": bson.M{"$concat": []interface{}{"$fingerprint", ":" + id.Hex()}}}}}
+			if _, err := mongodb.MongodbClient.Update(name, bson.M{"_id": id}, update); err != nil {
+				return err
+			}
+		}
+	}
+	logger.SlogWarnLocal(fmt.Sprintf("%v %v duplicated fingerprints renamed", name, len(groups)))
+	return nil
+}
+
+// backfillFingerprints 升级前的结果使用随机hash，没有指纹，按照当前的规则计算指纹，复扫时继续使用原来的结果和处理状态
+// 多个旧结果计算出相同的指纹时，第一个使用该指纹，其余结果的指纹加上文档ID
+// 旧的漏洞结果没有匹配器名称，标记为 legacy，带匹配器名称的结果写入时按照不带匹配器名称的指纹接管
+func backfillFingerprints(name string) (int, error) {
+	collection := mongodb.MongodbClient.GetCollection(name)
+	opts := options.Find().SetSort(bson.M{"_id": 1}).
+		SetProjection(bson.M{"_id": 1, "url": 1, "vulnid": 1, "sid": 1, "matcherName": 1, "time": 1, "taskName": 1})
+	cursor, err := collection.Find(context.Background(), bson.M{"fingerprint": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+	count := 0
+	for cursor.Next(context.Background()) {
+		var doc struct {
+			Id          primitive.ObjectID `bson:"_id"`
+			Url         string             `bson:"url"`
+			VulnId      string             `bson:"vulnid"`
+			SID         string             `bson:"sid"`
+			MatcherName string             `bson:"matcherName"`
+			Time        string             `bson:"time"`
+			TaskName    string             `bson:"taskName"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return count, err
+		}
+		set := bson.M{
+			"firstSeen": doc.Time,
+			"lastSeen":  doc.Time,
+			"count":     1,
+			"taskNames": []string{doc.TaskName},
+		}
+		if name == "vulnerability" {
+			set["fingerprint"] = VulnFingerprint(doc.VulnId, doc.Url, doc.MatcherName)
+			set["host"] = FindingHost(doc.Url)
+			set["legacy"] = true
+		} else {
+			set["fingerprint"] = SensitiveFingerprint(doc.SID, doc.Url)
+		}
+		filter := bson.M{"_id": doc.Id, "fingerprint": bson.M{"$exists": false}}
+		_, err := mongodb.MongodbClient.Update(name, filter, bson.M{"$set": set})
+		if mongo.IsDuplicateKeyError(err) {
+			set["fingerprint"] = fmt.Sprintf("%v:%v", set["fingerprint"], doc.Id.Hex())
+			delete(set, "legacy")
+			_, err = mongodb.MongodbClient.Update(name, filter, bson.M{"$set": set})
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
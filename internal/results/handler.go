@@ -69,8 +69,16 @@ func (h *handler) Subdomain(result *types.SubdomainResult) {
 	result.Project = h.GetAssetProject(rootDomain)
 	interfaceSlice = &result
 	if global.NotificationConfig.SubdomainNotification {
-		NotificationMsg := fmt.Sprintf("%v - %v\n", result.Host, result.IP)
-		notification.NotificationQueues["SubdomainScan"].Queue <- NotificationMsg
+		notification.Send(&types.NotificationMessage{
+			Module:  "SubdomainScan",
+			Project: result.Project,
+			Text:    fmt.Sprintf("%v - %v\n", result.Host, result.IP),
+			Fields: map[string]string{
+				"host":     result.Host,
+				"ip":       strings.Join(result.IP, ","),
+				"taskName": result.TaskName,
+			},
+		})
 	}
 	ResultQueues["SubdomainScan"].Queue <- interfaceSlice
 }
@@ -85,8 +93,17 @@ func (h *handler) SubdomainTakeover(result *types.SubTakeResult) {
 	result.Project = h.GetAssetProject(rootDomain)
 	interfaceSlice = &result
 	if global.NotificationConfig.SubdomainTakeoverNotification {
-		NotificationMsg := fmt.Sprintf("Subdomain Takeover:\n%v - %v\n", result.Input, result.Cname)
-		notification.NotificationQueues["SubdomainSecurity"].Queue <- NotificationMsg
+		notification.Send(&types.NotificationMessage{
+			Module:   "SubdomainSecurity",
+			Project:  result.Project,
+			Severity: "high",
+			Text:     fmt.Sprintf("Subdomain Takeover:\n%v - %v\n", result.Input, result.Cname),
+			Fields: map[string]string{
+				"host":     result.Input,
+				"cname":    result.Cname,
+				"taskName": result.TaskName,
+			},
+		})
 	}
 	ResultQueues["SubdomainSecurity"].Queue <- interfaceSlice
 }
@@ -94,6 +111,36 @@ func (h *handler) SubdomainTakeover(result *types.SubTakeResult) {
 func (h *handler) AssetChangeLog(result *types.AssetChangeLog) {
 	var interfaceSlice interface{}
 	interfaceSlice = &result
+	if global.NotificationConfig.AssetChangeNotification && len(result.Events) != 0 {
+		level := global.NotificationConfig.AssetChangeLevel
+		if level == "" {
+			level = "high"
+		}
+		for _, event := range result.Events {
+			if utils.Results.SeverityRank(event.Severity) < utils.Results.SeverityRank(level) {
+				continue
+			}
+			NotificationMsg := fmt.Sprintf("[%v][%v] %v\n", event.Severity, event.Type, event.Description)
+			if event.Old != "" || event.New != "" {
+				NotificationMsg += fmt.Sprintf("%v -> %v\n", event.Old, event.New)
+			}
+			notification.Send(&types.NotificationMessage{
+				Module:   "AssetChange",
+				Severity: event.Severity,
+				Text:     NotificationMsg,
+				Fields: map[string]string{
+					"assetId": result.AssetId,
+					"host":    result.Host,
+					"port":    result.Port,
+					"type":    event.Type,
+					"field":   event.FieldName,
+					"old":     event.Old,
+					"new":     event.New,
+				},
+				Key: result.AssetId + ":" + event.Type + ":" + event.New,
+			})
+		}
+	}
 	ResultQueues["AssetChangeLog"].Queue <- interfaceSlice
 }
 
@@ -357,15 +404,32 @@ func (h *handler) Sensitive(result *types.SensitiveResult) {
 		result.Time = utils.Tools.GetTimeNow()
 	}
 	result.Project = h.GetAssetProject(rootDomain)
+	// 误报判定，自动调整状态
+	h.classifySensitive(result)
 	interfaceSlice = &result
-	if global.NotificationConfig.SensitiveNotification {
-		NotificationMsg := fmt.Sprintf("Sensitive Scan:\n%v - %v\n", result.Url, result.SID)
-		notification.NotificationQueues["URLSecurity"].Queue <- NotificationMsg
+	if global.NotificationConfig.SensitiveNotification && result.Status != statusIgnored {
+		notification.Send(&types.NotificationMessage{
+			Module:  "URLSecurity",
+			Project: result.Project,
+			Text:    fmt.Sprintf("Sensitive Scan:\n%v - %v\n", result.Url, result.SID),
+			Fields: map[string]string{
+				"url":      result.Url,
+				"sid":      result.SID,
+				"match":    strings.Join(result.Match, ","),
+				"taskName": result.TaskName,
+			},
+			Key: result.Url + ":" + result.SID,
+		})
 	}
 	ResultQueues["SensitiveResult"].Queue <- interfaceSlice
 
 }
 
+// SensitiveClose URL复扫结束后关闭未复现的敏感信息，与结果使用同一个队列，保证在之前的结果写入后执行
+func (h *handler) SensitiveClose(c *types.FindingClose) {
+	ResultQueues["SensitiveResult"].Queue <- c
+}
+
 func (h *handler) SensitiveBody(body string, md5 string) {
 	selector := bson.M{"md5": md5}
 
@@ -419,7 +483,17 @@ func (h *handler) Dir(result *types.DirResult) {
 		} else {
 			NotificationMsg = fmt.Sprintf("%v - %v\n", result.Url, result.Status)
 		}
-		notification.NotificationQueues["DirScan"].Queue <- NotificationMsg
+		notification.Send(&types.NotificationMessage{
+			Module:  "DirScan",
+			Project: result.Project,
+			Text:    NotificationMsg,
+			Fields: map[string]string{
+				"url":      result.Url,
+				"status":   fmt.Sprintf("%v", result.Status),
+				"msg":      result.Msg,
+				"taskName": result.TaskName,
+			},
+		})
 	}
 	ResultQueues["DirScan"].Queue <- interfaceSlice
 }
@@ -453,11 +527,30 @@ func (h *handler) Vulnerability(result *types.VulnResult) {
 				NotificationMsg += fmt.Sprintf("%v-[%v]-[%v]\n", result.Level, result.VulName, result.Matched)
 			}
 		}
-		notification.NotificationQueues["VulnerabilityScan"].Queue <- NotificationMsg
+		notification.Send(&types.NotificationMessage{
+			Module:   "VulnerabilityScan",
+			Project:  result.Project,
+			Severity: result.Level,
+			Text:     NotificationMsg,
+			Fields: map[string]string{
+				"url":      result.Url,
+				"vulnId":   result.VulnId,
+				"name":     result.VulName,
+				"level":    result.Level,
+				"matched":  result.Matched,
+				"taskName": result.TaskName,
+			},
+			Key: result.Url + ":" + result.VulnId,
+		})
 	}
 	ResultQueues["VulnerabilityScan"].Queue <- interfaceSlice
 }
 
+// VulnerabilityClose 复扫结束后关闭未复现的漏洞，与结果使用同一个队列，保证在之前的结果写入后执行
+func (h *handler) VulnerabilityClose(c *types.FindingClose) {
+	ResultQueues["VulnerabilityScan"].Queue <- c
+}
+
 func (h *handler) PageMonitoringInsert(result *types.PageMonit) {
 	var interfaceSlice interface{}
 	rootDomain, err := utils.Tools.GetRootDomain(result.Url)
@@ -502,8 +595,17 @@ func (h *handler) RootDomain(result *types.RootDomain) {
 		// 出现错误表示 mongodb中不存在， 不存在则不进行处理 直接更新插入
 		// 通知新增根域名
 		if global.NotificationConfig.NewAsset {
-			tmp := fmt.Sprintf("Found a new root domain name: %v - %v - %v - %v", result.Domain, result.ICP, result.Company, result.Project)
-			notification.NotificationQueues["NewAssets"].Queue <- tmp
+			notification.Send(&types.NotificationMessage{
+				Module:  "NewAssets",
+				Project: result.Project,
+				Text:    fmt.Sprintf("Found a new root domain name: %v - %v - %v - %v\n", result.Domain, result.ICP, result.Company, result.Project),
+				Fields: map[string]string{
+					"type":    "rootDomain",
+					"domain":  result.Domain,
+					"icp":     result.ICP,
+					"company": result.Company,
+				},
+			})
 		}
 	} else {
 		// mongodb中存在
@@ -572,8 +674,17 @@ func (h *handler) APP(result *types.APP) {
 		// 出现错误表示 mongodb中不存在， 不存在则不进行处理 直接更新插入
 		// 通知新增根域名
 		if global.NotificationConfig.NewAsset {
-			tmp := fmt.Sprintf("Found a new app name: %v - %v - %v - %v", result.Name, result.ICP, result.Company, result.Project)
-			notification.NotificationQueues["NewAssets"].Queue <- tmp
+			notification.Send(&types.NotificationMessage{
+				Module:  "NewAssets",
+				Project: result.Project,
+				Text:    fmt.Sprintf("Found a new app name: %v - %v - %v - %v\n", result.Name, result.ICP, result.Company, result.Project),
+				Fields: map[string]string{
+					"type":    "app",
+					"name":    result.Name,
+					"icp":     result.ICP,
+					"company": result.Company,
+				},
+			})
 		}
 	} else {
 		if result.ICP == resultEx.ICP && result.Company == resultEx.Company && result.Project == resultEx.Project && resultEx.BundleID == result.BundleID {
@@ -661,8 +772,17 @@ func (h *handler) MP(result *types.MP) {
 		// 出现错误表示 mongodb中不存在， 不存在则不进行处理 直接更新插入
 		// 通知新增根域名
 		if global.NotificationConfig.NewAsset {
-			tmp := fmt.Sprintf("Found a new mp name: %v - %v - %v - %v", result.Name, result.ICP, result.Company, result.Project)
-			notification.NotificationQueues["NewAssets"].Queue <- tmp
+			notification.Send(&types.NotificationMessage{
+				Module:  "NewAssets",
+				Project: result.Project,
+				Text:    fmt.Sprintf("Found a new mp name: %v - %v - %v - %v\n", result.Name, result.ICP, result.Company, result.Project),
+				Fields: map[string]string{
+					"type":    "mp",
+					"name":    result.Name,
+					"icp":     result.ICP,
+					"company": result.Company,
+				},
+			})
 		}
 	} else {
 		if result.ICP == resultEx.ICP && result.Company == resultEx.Company && result.Project == resultEx.Project {
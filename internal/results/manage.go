@@ -8,6 +8,9 @@
 package results
 
 import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
 	"time"
 )
 
@@ -19,10 +22,16 @@ const (
 type ResultQueue struct {
 	Queue   chan interface{}
 	CloseCh chan struct{}
+	flushCh chan chan struct{} // 立即写入缓存的结果，写入完成后关闭收到的通道
 }
 
 var ResultQueues = make(map[string]*ResultQueue)
 
+var (
+	queueWg   sync.WaitGroup
+	closeOnce sync.Once
+)
+
 func InitializeResultQueue() {
 	// 模块列表
 	modules := []string{
@@ -60,6 +69,8 @@ func InitializeResultQueue() {
 			}
 		}
 
+		ResultQueues[module].flushCh = make(chan chan struct{})
+		queueWg.Add(1)
 		go processQueue(module, ResultQueues[module])
 	}
 
@@ -72,6 +83,7 @@ func InitializeResultQueue() {
 }
 
 func processQueue(module string, mq *ResultQueue) {
+	defer queueWg.Done()
 	ticker := time.NewTicker(flushInterval)
 	if module == "URLScan" {
 		ticker = time.NewTicker(60 * time.Second)
@@ -93,8 +105,13 @@ func processQueue(module string, mq *ResultQueue) {
 			if len(buffer) > 0 {
 				flushBuffer(module, &buffer)
 			}
+		case done := <-mq.flushCh:
+			drainQueue(mq, &buffer)
+			flushBuffer(module, &buffer)
+			close(done)
 		case <-mq.CloseCh:
-			// 处理关闭信号
+			// 处理关闭信号，写入队列中剩余的结果
+			drainQueue(mq, &buffer)
 			if len(buffer) > 0 {
 				flushBuffer(module, &buffer)
 			}
@@ -103,10 +120,29 @@ func processQueue(module string, mq *ResultQueue) {
 	}
 }
 
+// drainQueue 取出队列中已有的结果，不等待新的结果
+func drainQueue(mq *ResultQueue, buffer *[]interface{}) {
+	for {
+		select {
+		case batch := <-mq.Queue:
+			if batch != nil {
+				*buffer = append(*buffer, batch)
+			}
+		default:
+			return
+		}
+	}
+}
+
 func flushBuffer(module string, buffer *[]interface{}) {
 	if len(*buffer) == 0 {
 		return
 	}
+	start := time.Now()
+	metrics.ResultFlushItems.WithLabelValues(module).Add(float64(len(*buffer)))
+	defer func() {
+		metrics.ResultFlushDuration.WithLabelValues(module).Observe(time.Since(start).Seconds())
+	}()
 	var name string
 	if module == "SensitiveBody" {
 		Results.Update(buffer, "SensitiveBody")
@@ -114,6 +150,8 @@ func flushBuffer(module string, buffer *[]interface{}) {
 		Results.Update(buffer, "RootDomain")
 	} else if module == "VulnerabilityScan" {
 		Results.InsertVulnerabilityScan(buffer)
+	} else if module == "SensitiveResult" {
+		Results.InsertSensitive(buffer)
 	} else {
 		switch module {
 		case "SubdomainScan":
@@ -124,8 +162,6 @@ func flushBuffer(module string, buffer *[]interface{}) {
 			name = "AssetChangeLog"
 		case "URLScan":
 			name = "UrlScan"
-		case "SensitiveResult":
-			name = "SensitiveResult"
 		case "WebCrawler":
 			name = "crawler"
 		case "DirScan":
@@ -144,9 +180,55 @@ func flushBuffer(module string, buffer *[]interface{}) {
 	*buffer = nil
 }
 
-func Close() {
+// queueCollector 采集结果队列中等待写入的数据量
+type queueCollector struct {
+	depth    *prometheus.Desc
+	capacity *prometheus.Desc
+}
+
+func init() {
+	metrics.Registry.MustRegister(&queueCollector{
+		depth:    metrics.Desc("result_queue_depth", "Results waiting in each result queue.", "queue"),
+		capacity: metrics.Desc("result_queue_capacity", "Capacity of each result queue.", "queue"),
+	})
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	ch <- c.capacity
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	for module, mq := range ResultQueues {
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(len(mq.Queue)), module)
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(cap(mq.Queue)), module)
+	}
+}
+
+// Flush 立即写入所有结果队列中已有的结果并等待写入完成，不关闭队列
+// 任务结束后生成报告前调用，保证报告包含本节点的全部结果
+func Flush() {
+	var pending []chan struct{}
 	for _, mq := range ResultQueues {
-		close(mq.Queue)   // 关闭队列
-		close(mq.CloseCh) // 发送关闭信号
+		done := make(chan struct{})
+		select {
+		case mq.flushCh <- done:
+			pending = append(pending, done)
+		case <-mq.CloseCh:
+		}
 	}
+	for _, done := range pending {
+		<-done
+	}
+}
+
+// Close 关闭结果队列，等待队列中的结果写入数据库，重复调用时只等待
+// 不关闭 Queue，防止仍在发送结果的协程 panic
+func Close() {
+	closeOnce.Do(func() {
+		for _, mq := range ResultQueues {
+			close(mq.CloseCh) // 发送关闭信号
+		}
+	})
+	queueWg.Wait()
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"reflect"
 )
@@ -23,6 +24,11 @@ type result struct {
 
 var Results *result
 
+// InsertMany 批量写入结果，默认写入mongodb，测试时替换
+var InsertMany = func(name string, documents []interface{}) (*mongo.InsertManyResult, error) {
+	return mongodb.MongodbClient.InsertMany(name, documents)
+}
+
 func InitializeResults() {
 	Results = &result{}
 }
@@ -37,7 +43,7 @@ func InitializeResults() {
 //}
 
 func (r *result) Insert(name string, result *[]interface{}) bool {
-	_, err := mongodb.MongodbClient.InsertMany(name, *result)
+	_, err := InsertMany(name, *result)
 	if err != nil {
 		var writeException mongo.WriteException
 		if errors.As(err, &writeException) {
@@ -103,21 +109,28 @@ func (r *result) UpdateNow(op types.BulkUpdateOperation, name string) bool {
 	return true
 }
 
+// InsertVulnerabilityScan 漏洞按指纹去重写入，请求响应保存在vulnerabilityDetail中
 func (r *result) InsertVulnerabilityScan(result *[]interface{}) {
-	var newVulnResults []interface{}
-	var vulDetails []interface{}
+	order, findings, counts := []string{}, make(map[string]interface{}), make(map[string]int)
+	var vulDetails []mongo.WriteModel
+	var closes []*types.FindingClose
 
 	for _, v := range *result {
 		var vulnResult *types.VulnResult
 
+		switch tmp := v.(type) {
 		// 第一种：*types.VulnResult
-		if vr, ok := v.(*types.VulnResult); ok {
-			vulnResult = vr
-		}
-
+		case *types.VulnResult:
+			vulnResult = tmp
 		// 第二种：**types.VulnResult
-		if vrPtr, ok := v.(**types.VulnResult); ok && vrPtr != nil {
-			vulnResult = *vrPtr
+		case **types.VulnResult:
+			if tmp != nil {
+				vulnResult = *tmp
+			}
+		// 复扫关闭，在本批次结果写入后执行
+		case *types.FindingClose:
+			closes = append(closes, tmp)
+			continue
 		}
 
 		if vulnResult == nil {
@@ -125,25 +138,102 @@ func (r *result) InsertVulnerabilityScan(result *[]interface{}) {
 			continue
 		}
 
-		// 生成唯一 Hash
-		vulnResult.Hash = utils.Tools.GenerateRandomString(16)
+		// 生成稳定的指纹，Hash与指纹一致，重复发现时覆盖详细信息
+		if vulnResult.Fingerprint == "" {
+			vulnResult.Fingerprint = VulnFingerprint(vulnResult.VulnId, vulnResult.Url, vulnResult.MatcherName)
+		}
+		vulnResult.Hash = vulnResult.Fingerprint
+		vulnResult.Host = FindingHost(vulnResult.Url)
+		vulnResult.FirstSeen = vulnResult.Time
+		vulnResult.LastSeen = vulnResult.Time
 
 		// 保存详细信息
-		vulDetails = append(vulDetails, types.VulnerabilityDetail{
-			Hash:     vulnResult.Hash,
-			Request:  vulnResult.Request,
-			Response: vulnResult.Response,
-		})
+		vulDetails = append(vulDetails, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"hash": vulnResult.Hash}).
+			SetUpdate(bson.M{"$set": types.VulnerabilityDetail{
+				Hash:     vulnResult.Hash,
+				Request:  vulnResult.Request,
+				Response: vulnResult.Response,
+			}}).
+			SetUpsert(true))
 
 		// 清空请求与响应
 		vulnResult.Request = ""
 		vulnResult.Response = ""
 
-		// 放回新的结果集
-		newVulnResults = append(newVulnResults, vulnResult)
+		if _, ok := findings[vulnResult.Fingerprint]; !ok {
+			order = append(order, vulnResult.Fingerprint)
+		}
+		findings[vulnResult.Fingerprint] = vulnResult
+		counts[vulnResult.Fingerprint]++
 	}
 
+	// 升级前的漏洞没有匹配器名称，带匹配器名称的漏洞接管对应的旧结果，保留处理状态
+	var adopts []mongo.WriteModel
+	for _, fp := range order {
+		vulnResult := findings[fp].(*types.VulnResult)
+		if vulnResult.MatcherName == "" {
+			continue
+		}
+		adopts = append(adopts, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"fingerprint": VulnFingerprint(vulnResult.VulnId, vulnResult.Url, ""), "legacy": true}).
+			SetUpdate(bson.M{"$set": bson.M{"fingerprint": fp}, "$unset": bson.M{"legacy": ""}}))
+	}
+	if len(adopts) != 0 {
+		// 已经存在该指纹的结果时保留旧结果不变
+		if _, err := mongodb.MongodbClient.BulkWrite("vulnerability", adopts); err != nil && !mongo.IsDuplicateKeyError(err) {
+			logger.SlogWarnLocal(fmt.Sprintf("adopt legacy vulnerability error: %s", err))
+		}
+	}
 	// 写入数据库
-	r.Insert("vulnerability", &newVulnResults)
-	r.Insert("vulnerabilityDetail", &vulDetails)
+	r.upsertFindings("vulnerability", order, findings, counts)
+	if len(vulDetails) != 0 {
+		if _, err := mongodb.MongodbClient.BulkWrite("vulnerabilityDetail", vulDetails); err != nil {
+			logger.SlogWarnLocal(fmt.Sprintf("upsert vulnerabilityDetail error: %s", err))
+		}
+	}
+	for _, c := range closes {
+		r.closeFindings("vulnerability", c)
+	}
+}
+
+// AssetTimeline 查询资产的变化时间线，按时间倒序返回
+// assetId 为空时按 host、port 查询，minSeverity 不为空时只返回包含该等级及以上事件的记录
+func (r *result) AssetTimeline(assetId string, host string, port string, minSeverity string, limit int) ([]types.AssetChangeLog, error) {
+	var conditions []bson.M
+	if assetId != "" {
+		conditions = append(conditions, bson.M{"assetid": assetId})
+	}
+	if host != "" {
+		query := bson.M{"host": host}
+		if port != "" {
+			query["port"] = port
+		}
+		conditions = append(conditions, query)
+	}
+	if len(conditions) == 0 {
+		return nil, errors.New("assetId or host is required")
+	}
+	match := bson.M{"$or": conditions}
+	if minSeverity != "" {
+		var levels []string
+		for _, level := range []string{"info", "low", "medium", "high", "critical"} {
+			if utils.Results.SeverityRank(level) >= utils.Results.SeverityRank(minSeverity) {
+				levels = append(levels, level)
+			}
+		}
+		match["severity"] = bson.M{"$in": levels}
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"timestamp": -1}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+	var timeline []types.AssetChangeLog
+	if err := mongodb.MongodbClient.Aggregate("AssetChangeLog", pipeline, &timeline); err != nil {
+		return nil, err
+	}
+	return timeline, nil
 }
@@ -0,0 +1,225 @@
+// results-------------------------------------
+// @file      : sensitivefilter.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 16:30
+// -------------------------------------------
+
+package results
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// minSecretEntropy 类似密钥的值低于该信息熵时判定为疑似误报
+const minSecretEntropy = 3.0
+
+// 匹配内容中 key=value、"key": "value" 形式的值
+var secretAssignRegex = regexp.MustCompile(`^["']?[A-Za-z_][\w.\-]*["']?\s*[:=]\s*["'` + "`" + `]?([^"'` + "`" + `\s,;]+)`)
+
+// 常见第三方库文件，其中的匹配多为误报
+var libraryFileRegex = regexp.MustCompile(`(?i)^(jquery|bootstrap|react|react-dom|vue|vuex|vue-router|angular|lodash|underscore|moment|echarts|highcharts|d3|three|swiper|axios|layui|zepto|backbone|require|crypto-js|jsencrypt|polyfills?|chunk-vendors|vendors?|element-ui|antd)([.\-_][\w.\-]*)?\.js$`)
+
+// 占位符、示例值中常见的片段
+var placeholderParts = []string{
+	"example", "sample", "dummy", "placeholder", "your_", "your-", "yourkey", "yourtoken", "yoursecret",
+	"xxxx", "****", "changeme", "change_me", "fake", "redacted", "replace_me", "todo", "insert_",
+	"${", "{{", "%s", "<your", "test_key", "testkey", "abcdefgh",
+}
+
+// 常见的占位值，完全相等时判定
+var placeholderValues = map[string]bool{
+	"password": true, "passwd": true, "secret": true, "token": true, "apikey": true, "api_key": true,
+	"null": true, "nil": true, "undefined": true, "none": true, "true": true, "false": true,
+	"123456": true, "12345678": true, "123456789": true, "admin": true, "test": true, "demo": true,
+}
+
+// 各厂商文档中的示例密钥和测试密钥前缀
+var placeholderPrefixes = []string{"sk_test_", "pk_test_", "rk_test_", "akiaiosfodnn7example", "wjalrxutnfemi/k7mdeng"}
+
+// sensitiveVerdict 单个匹配的判定结果
+type sensitiveVerdict struct {
+	Status int
+	Reason string
+}
+
+// classifySensitive 对敏感信息进行误报判定，根据所有匹配的判定结果调整状态并记录原因
+// 任一匹配在线验证有效时为确认；存在未判定的匹配时保持未处理；否则存在疑似时为疑似，全部忽略时为忽略
+func (h *handler) classifySensitive(result *types.SensitiveResult) {
+	if result.Status != statusUnprocessed || len(result.Match) == 0 {
+		return
+	}
+	library := libraryFile(result.Url)
+	var reasons []string
+	seen := make(map[string]bool)
+	confirmed, unflagged, suspected := false, false, false
+	for _, match := range result.Match {
+		verdict := h.classifyMatch(match, result.Project, library, result.Verify)
+		switch verdict.Status {
+		case statusConfirmed:
+			confirmed = true
+		case statusSuspected:
+			suspected = true
+		case statusIgnored:
+		default:
+			unflagged = true
+		}
+		if verdict.Reason != "" && !seen[verdict.Reason] {
+			seen[verdict.Reason] = true
+			reasons = append(reasons, verdict.Reason)
+		}
+	}
+	switch {
+	case confirmed:
+		result.Status = statusConfirmed
+	case unflagged:
+	case suspected:
+		result.Status = statusSuspected
+	default:
+		result.Status = statusIgnored
+	}
+	result.Reason = strings.Join(reasons, "; ")
+}
+
+// classifyMatch 按允许列表、占位符、在线验证、信息熵、第三方库的顺序判定单个匹配
+func (h *handler) classifyMatch(match string, project string, library string, verify bool) sensitiveVerdict {
+	value := secretValue(match)
+	if entry := sensitiveAllowed(project, match, value); entry != "" {
+		return sensitiveVerdict{statusIgnored, fmt.Sprintf("allow list: %v", entry)}
+	}
+	if isPlaceholder(value) {
+		return sensitiveVerdict{statusIgnored, fmt.Sprintf("placeholder: %v", value)}
+	}
+	if verify {
+		if provider, valid, ok := verifySecret(value); ok {
+			if valid {
+				return sensitiveVerdict{statusConfirmed, fmt.Sprintf("verified: %v", provider)}
+			}
+			return sensitiveVerdict{statusIgnored, fmt.Sprintf("verification failed: %v", provider)}
+		}
+	}
+	if secretLike(value) {
+		if entropy := shannonEntropy(value); entropy < minSecretEntropy {
+			return sensitiveVerdict{statusSuspected, fmt.Sprintf("low entropy %.2f: %v", entropy, value)}
+		}
+	}
+	if library != "" {
+		return sensitiveVerdict{statusSuspected, fmt.Sprintf("third-party library: %v", library)}
+	}
+	return sensitiveVerdict{}
+}
+
+// sensitiveAllowed 检查项目的敏感信息允许列表，返回命中的条目
+func sensitiveAllowed(projectId string, match string, value string) string {
+	if projectId == "" {
+		return ""
+	}
+	for _, p := range global.Projects {
+		if p.ID != projectId {
+			continue
+		}
+		for _, allow := range p.SensitiveAllowList {
+			if allow == value || allow == match {
+				return allow
+			}
+		}
+		for _, allowRegex := range p.SensitiveAllowRegexList {
+			if allowRegex.MatchString(value) || allowRegex.MatchString(match) {
+				return allowRegex.String()
+			}
+		}
+	}
+	return ""
+}
+
+// secretValue 从匹配内容中提取值，如 "apiKey": "xxx" 提取 xxx
+func secretValue(match string) string {
+	match = strings.TrimSpace(match)
+	if sub := secretAssignRegex.FindStringSubmatch(match); len(sub) == 2 && !strings.HasPrefix(sub[1], "//") {
+		return sub[1]
+	}
+	return strings.Trim(match, "\"'` ")
+}
+
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	if placeholderValues[lower] {
+		return true
+	}
+	for _, prefix := range placeholderPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	for _, part := range placeholderParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	// 由单个字符重复组成，如 00000000
+	if len(value) >= 6 && strings.Count(value, value[:1]) == len(value) {
+		return true
+	}
+	// <API_KEY> 形式
+	return strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">")
+}
+
+// secretLike 是否为类似密钥的值：长度不小于16、不含空白、同时包含字母和数字
+// 手机号、身份证号等纯数字的敏感信息不做信息熵判定
+func secretLike(value string) bool {
+	if len(value) < 16 {
+		return false
+	}
+	letters, digits := 0, 0
+	for _, r := range value {
+		switch {
+		case unicode.IsSpace(r):
+			return false
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+	return letters >= 2 && digits > 0
+}
+
+// shannonEntropy 计算每个字符的香农信息熵
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// libraryFile URL为常见第三方库文件时返回文件名
+func libraryFile(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Path == "" {
+		return ""
+	}
+	name := path.Base(u.Path)
+	if libraryFileRegex.MatchString(name) {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+// results-------------------------------------
+// @file      : sensitiveverify.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 17:05
+// -------------------------------------------
+
+package results
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// secretVerifier 支持在线验证的密钥类型
+// check 返回 valid 表示密钥有效，ok 为false表示无法判断（网络错误、限流等）
+type secretVerifier struct {
+	Provider string
+	Pattern  *regexp.Regexp
+	Check    func(secret string) (valid bool, ok bool)
+}
+
+var secretVerifiers = []secretVerifier{
+	{
+		Provider: "github",
+		Pattern:  regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})$`),
+		Check: func(secret string) (bool, bool) {
+			return checkStatus(utils.Requests.HttpGetWithCustomHeader("https://api.github.com/user", []string{
+				"Authorization: token " + secret,
+				"User-Agent: ScopeSentry",
+			}))
+		},
+	},
+	{
+		Provider: "gitlab",
+		Pattern:  regexp.MustCompile(`^glpat-[A-Za-z0-9_\-]{20,}$`),
+		Check: func(secret string) (bool, bool) {
+			return checkStatus(utils.Requests.HttpGetWithCustomHeader("https://gitlab.com/api/v4/user", []string{"PRIVATE-TOKEN: " + secret}))
+		},
+	},
+	{
+		Provider: "stripe",
+		Pattern:  regexp.MustCompile(`^[sr]k_live_[A-Za-z0-9]{20,}$`),
+		Check: func(secret string) (bool, bool) {
+			auth := base64.StdEncoding.EncodeToString([]byte(secret + ":"))
+			return checkStatus(utils.Requests.HttpGetWithCustomHeader("https://api.stripe.com/v1/balance", []string{"Authorization: Basic " + auth}))
+		},
+	},
+	{
+		Provider: "slack",
+		Pattern:  regexp.MustCompile(`^xox[abposr]-[A-Za-z0-9\-]{10,}$`),
+		Check: func(secret string) (bool, bool) {
+			err, resp := utils.Requests.HttpPostWithCustomHeader("https://slack.com/api/auth.test", nil, "", []string{"Authorization: Bearer " + secret})
+			if err != nil || resp.StatusCode != 200 {
+				return false, false
+			}
+			var result struct {
+				Ok    bool   `json:"ok"`
+				Error string `json:"error"`
+			}
+			if json.Unmarshal(resp.Body, &result) != nil {
+				return false, false
+			}
+			if result.Ok {
+				return true, true
+			}
+			return false, result.Error == "invalid_auth" || result.Error == "token_revoked" || result.Error == "account_inactive"
+		},
+	},
+	{
+		Provider: "google",
+		Pattern:  regexp.MustCompile(`^AIza[0-9A-Za-z_\-]{35}$`),
+		Check: func(secret string) (bool, bool) {
+			resp, err := utils.Requests.HttpGet("https://maps.googleapis.com/maps/api/geocode/json?address=beijing&key=" + url.QueryEscape(secret))
+			if err != nil {
+				return false, false
+			}
+			var result struct {
+				Status       string `json:"status"`
+				ErrorMessage string `json:"error_message"`
+			}
+			if json.Unmarshal([]byte(resp.Body), &result) != nil {
+				return false, false
+			}
+			switch {
+			case result.Status == "OK" || result.Status == "ZERO_RESULTS":
+				return true, true
+			case strings.Contains(strings.ToLower(result.ErrorMessage), "invalid"):
+				return false, true
+			}
+			// 未开通对应API等情况无法判断
+			return false, false
+		},
+	},
+	{
+		Provider: "telegram",
+		Pattern:  regexp.MustCompile(`^[0-9]{8,10}:[A-Za-z0-9_\-]{35}$`),
+		Check: func(secret string) (bool, bool) {
+			resp, err := utils.Requests.HttpGet("https://api.telegram.org/bot" + secret + "/getMe")
+			if err != nil {
+				return false, false
+			}
+			switch resp.StatusCode {
+			case 200:
+				return true, true
+			case 401, 404:
+				return false, true
+			}
+			return false, false
+		},
+	},
+}
+
+// verifySecret 在线验证密钥，ok为false表示不支持该类型或无法判断
+func verifySecret(secret string) (provider string, valid bool, ok bool) {
+	for _, verifier := range secretVerifiers {
+		if !verifier.Pattern.MatchString(secret) {
+			continue
+		}
+		valid, ok = verifier.Check(secret)
+		return verifier.Provider, valid, ok
+	}
+	return "", false, false
+}
+
+// checkStatus 根据状态码判断，200有效，401/403无效
+func checkStatus(resp types.HttpResponse, err error) (bool, bool) {
+	if err != nil {
+		return false, false
+	}
+	switch resp.StatusCode {
+	case 200:
+		return true, true
+	case 401, 403:
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,78 @@
+// runner-------------------------------------
+// @file      : active.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 10:20
+// -------------------------------------------
+
+package runner
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ActiveTarget 当前节点正在运行的目标
+type ActiveTarget struct {
+	TaskID   string         `json:"taskId"`
+	TaskName string         `json:"taskName"`
+	Target   string         `json:"target"`
+	Type     string         `json:"type"`
+	SubWork  bool           `json:"subWork"`
+	Start    time.Time      `json:"start"`
+	Backlog  map[string]int `json:"backlog"` // 各模块输入通道中等待处理的数据
+}
+
+var active = struct {
+	sync.Mutex
+	next    int
+	targets map[int]*options.TaskOptions
+	starts  map[int]time.Time
+}{
+	targets: make(map[int]*options.TaskOptions),
+	starts:  make(map[int]time.Time),
+}
+
+// track 登记正在运行的目标，目标运行结束时调用返回的函数
+func track(op *options.TaskOptions) func() {
+	active.Lock()
+	defer active.Unlock()
+	id := active.next
+	active.next++
+	active.targets[id] = op
+	active.starts[id] = time.Now()
+	return func() {
+		active.Lock()
+		defer active.Unlock()
+		delete(active.targets, id)
+		delete(active.starts, id)
+	}
+}
+
+// Active 当前节点正在运行的目标，按开始时间排序
+func Active() []ActiveTarget {
+	active.Lock()
+	defer active.Unlock()
+	list := make([]ActiveTarget, 0, len(active.targets))
+	for id, op := range active.targets {
+		backlog := make(map[string]int, len(op.InputChan))
+		for module, input := range op.InputChan {
+			backlog[module] = len(input)
+		}
+		list = append(list, ActiveTarget{
+			TaskID:   op.ID,
+			TaskName: op.TaskName,
+			Target:   op.Target,
+			Type:     op.Type,
+			SubWork:  op.SubWork != "",
+			Start:    active.starts[id],
+			Backlog:  backlog,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Start.Before(list[j].Start)
+	})
+	return list
+}
@@ -104,6 +104,8 @@ func Handler(pageMonitResult types.PageMonit, wg *sync.WaitGroup) {
 				return
 			}
 			pageMonitResult.Similarity = similarity
+			// 记录变化的差异
+			recordChange(pageMonitResult, tmp.Content[len(tmp.Content)-1], response.Body, response.StatusCode, response.StatusCode, newHash, similarity, flag)
 			if len(pageMonitResult.Hash) == 2 {
 				pageMonitResult.Hash = pageMonitResult.Hash[1:]
 			}
@@ -121,6 +123,7 @@ func Handler(pageMonitResult types.PageMonit, wg *sync.WaitGroup) {
 		}
 	} else {
 		// 状态码不相同，记录状态码
+		oldStatus := pageMonitResult.StatusCode[len(pageMonitResult.StatusCode)-1]
 		if len(pageMonitResult.StatusCode) == 2 {
 			pageMonitResult.StatusCode = pageMonitResult.StatusCode[1:]
 		}
@@ -131,6 +134,13 @@ func Handler(pageMonitResult types.PageMonit, wg *sync.WaitGroup) {
 			logger.SlogErrorLocal(fmt.Sprintf("PageMonitoringBody2 findone error: %v", err))
 			return
 		}
+		oldBody := ""
+		if len(tmp.Content) != 0 {
+			oldBody = tmp.Content[len(tmp.Content)-1]
+		}
+		if pageMonitResult.Hash[len(pageMonitResult.Hash)-1] != newHash {
+			recordChange(pageMonitResult, oldBody, response.Body, oldStatus, response.StatusCode, newHash, 0, flag)
+		}
 		if len(tmp.Content) == 2 {
 			tmp.Content = tmp.Content[1:]
 		}
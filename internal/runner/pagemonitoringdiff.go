@@ -0,0 +1,302 @@
+// runner-------------------------------------
+// @file      : pagemonitoringdiff.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/20 19:20
+// -------------------------------------------
+
+package runner
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/mongodb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/notification"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/modules/urlsecurity/jsanalysis"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// diffContext 统一格式差异的上下文行数
+	diffContext = 3
+	// maxDiffSize 保存的差异最大长度
+	maxDiffSize = 64 * 1024
+	// minifiedLineLength 超过该长度的行认为是压缩后的代码，按语句拆分后再比较
+	minifiedLineLength = 500
+	// maxSecretMatches 每条规则在差异中最多提取的数量
+	maxSecretMatches = 20
+)
+
+var (
+	// 前端路由配置，如 vue-router、react-router 中的 path: '/admin'
+	routeRe = regexp.MustCompile(`(?:path|route)\s*:\s*["'\x60](/[a-zA-Z0-9_\-./:*]*)["'\x60]`)
+	// 压缩代码按语句和代码块拆分
+	minifiedReplacer = strings.NewReplacer(";", ";\n", "{", "{\n", "}", "\n}")
+)
+
+type diffLine struct {
+	op   byte // ' ' 不变，'-' 删除，'+' 新增
+	text string
+}
+
+// recordChange 记录页面的一次变化，保存统一格式差异，js文件对比提取出的接口、host、路由和凭据
+// 新增安全相关内容时发送通知，js文件从不可用恢复时只记录差异
+func recordChange(page types.PageMonit, oldBody string, newBody string, oldStatus int, newStatus int, newHash string, similarity float64, isJs bool) {
+	change := types.PageMonitChange{
+		Url:        page.Url,
+		Md5:        page.Md5,
+		NewHash:    newHash,
+		StatusCode: []int{oldStatus, newStatus},
+		Similarity: similarity,
+		Project:    page.Project,
+		RootDomain: page.RootDomain,
+		Time:       utils.Tools.GetTimeNow(),
+	}
+	if len(page.Hash) != 0 {
+		change.OldHash = page.Hash[len(page.Hash)-1]
+	}
+	oldText, newText := oldBody, newBody
+	if isJs {
+		oldText, newText = splitMinified(oldBody), splitMinified(newBody)
+	}
+	diff, addedLines := unifiedDiff(oldText, newText)
+	if len(diff) > maxDiffSize {
+		diff = diff[:maxDiffSize] + "\n... (truncated)\n"
+	}
+	change.Diff = diff
+	// 任意一侧为空或者状态码为0（js地址返回了html页面）时，提取结果的差异是整个文件，不做对比也不通知
+	if isJs && oldBody != "" && newBody != "" && oldStatus != 0 && newStatus != 0 {
+		oldItems, newItems := jsItems(oldBody), jsItems(newBody)
+		change.Added, change.Removed = diffItems(oldItems, newItems)
+		// 新增行中匹配敏感信息规则
+		change.Added.Secrets = mergeStrings(change.Added.Secrets, sensitiveMatches(strings.Join(addedLines, "\n")))
+		change.Security = len(change.Added.Endpoints) != 0 || len(change.Added.Hosts) != 0 ||
+			len(change.Added.Routes) != 0 || len(change.Added.Secrets) != 0
+	}
+	if _, err := mongodb.MongodbClient.InsertOne("PageMonitoringChange", change); err != nil {
+		logger.SlogWarnLocal(fmt.Sprintf("PageMonitoringChange insert error: %v", err))
+	}
+	if change.Security && global.NotificationConfig.PageMonNotification {
+		notification.Send(&types.NotificationMessage{
+			Module:  "PageMonitor",
+			Project: change.Project,
+			Text:    fmt.Sprintf("Page Monitoring:\n%v\n%v", change.Url, changeSummary(change)),
+			Fields: map[string]string{
+				"url":              change.Url,
+				"similarity":       fmt.Sprintf("%v", change.Similarity),
+				"addedEndpoints":   strings.Join(change.Added.Endpoints, ","),
+				"addedHosts":       strings.Join(change.Added.Hosts, ","),
+				"addedRoutes":      strings.Join(change.Added.Routes, ","),
+				"addedSecrets":     strings.Join(change.Added.Secrets, ","),
+				"removedEndpoints": strings.Join(change.Removed.Endpoints, ","),
+				"removedHosts":     strings.Join(change.Removed.Hosts, ","),
+				"removedRoutes":    strings.Join(change.Removed.Routes, ","),
+				"removedSecrets":   strings.Join(change.Removed.Secrets, ","),
+			},
+			Key: change.Url + ":" + change.NewHash,
+		})
+	}
+}
+
+// changeSummary 通知中展示的变化内容
+func changeSummary(change types.PageMonitChange) string {
+	var b strings.Builder
+	items := []struct {
+		name    string
+		added   []string
+		removed []string
+	}{
+		{"endpoints", change.Added.Endpoints, change.Removed.Endpoints},
+		{"hosts", change.Added.Hosts, change.Removed.Hosts},
+		{"routes", change.Added.Routes, change.Removed.Routes},
+		{"secrets", change.Added.Secrets, change.Removed.Secrets},
+	}
+	for _, item := range items {
+		if len(item.added) != 0 {
+			fmt.Fprintf(&b, "+ %v: %v\n", item.name, strings.Join(item.added, ", "))
+		}
+		if len(item.removed) != 0 {
+			fmt.Fprintf(&b, "- %v: %v\n", item.name, strings.Join(item.removed, ", "))
+		}
+	}
+	return b.String()
+}
+
+// splitMinified 压缩的js只有一行，直接按行比较没有意义，按语句拆分成多行
+func splitMinified(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if len(line) > minifiedLineLength {
+			return minifiedReplacer.Replace(content)
+		}
+	}
+	return content
+}
+
+// unifiedDiff 按行比较生成统一格式的差异，同时返回新增的行
+func unifiedDiff(oldText string, newText string) (string, []string) {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+	var all []diffLine
+	var added []string
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n") {
+			all = append(all, diffLine{op: op, text: line})
+			if op == '+' {
+				added = append(added, line)
+			}
+		}
+	}
+	// 每一行之前已经出现的旧、新行数，用于计算hunk的起始行号
+	oldPos := make([]int, len(all)+1)
+	newPos := make([]int, len(all)+1)
+	for i, line := range all {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if line.op != '+' {
+			oldPos[i+1]++
+		}
+		if line.op != '-' {
+			newPos[i+1]++
+		}
+	}
+	var out strings.Builder
+	for i := 0; i < len(all); {
+		if all[i].op == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// 间隔不超过两倍上下文的变化合并到同一个hunk
+		end := i
+		for j := i; j < len(all) && j-end-1 <= 2*diffContext; j++ {
+			if all[j].op != ' ' {
+				end = j
+			}
+		}
+		stop := end + diffContext + 1
+		if stop > len(all) {
+			stop = len(all)
+		}
+		if out.Len() == 0 {
+			out.WriteString("--- old\n+++ new\n")
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldPos[start]+1, oldPos[stop]-oldPos[start], newPos[start]+1, newPos[stop]-newPos[start])
+		for _, line := range all[start:stop] {
+			out.WriteByte(line.op)
+			out.WriteString(line.text)
+			out.WriteByte('\n')
+		}
+		i = stop
+	}
+	return out.String(), added
+}
+
+// jsItems 提取js中的接口、host、路由和凭据
+func jsItems(content string) types.PageMonitItems {
+	var items types.PageMonitItems
+	if content == "" {
+		return items
+	}
+	extraction := jsanalysis.Analyze(content)
+	for _, endpoint := range extraction.Endpoints {
+		if endpoint.Method != "" {
+			items.Endpoints = append(items.Endpoints, endpoint.Method+" "+endpoint.Url)
+		} else {
+			items.Endpoints = append(items.Endpoints, endpoint.Url)
+		}
+	}
+	for _, op := range extraction.GraphQL {
+		items.Endpoints = append(items.Endpoints, "GRAPHQL "+op.Type+" "+op.Name)
+	}
+	items.Hosts = extraction.Hosts
+	for _, secret := range extraction.Secrets {
+		items.Secrets = append(items.Secrets, secret.Key+"="+secret.Value)
+	}
+	for _, m := range routeRe.FindAllStringSubmatch(content, -1) {
+		items.Routes = append(items.Routes, m[1])
+	}
+	items.Routes = mergeStrings(nil, items.Routes)
+	return items
+}
+
+// diffItems 对比两次提取的结果，返回新增和删除的内容
+func diffItems(oldItems types.PageMonitItems, newItems types.PageMonitItems) (types.PageMonitItems, types.PageMonitItems) {
+	var added, removed types.PageMonitItems
+	added.Endpoints, removed.Endpoints = diffStrings(oldItems.Endpoints, newItems.Endpoints)
+	added.Hosts, removed.Hosts = diffStrings(oldItems.Hosts, newItems.Hosts)
+	added.Routes, removed.Routes = diffStrings(oldItems.Routes, newItems.Routes)
+	added.Secrets, removed.Secrets = diffStrings(oldItems.Secrets, newItems.Secrets)
+	return added, removed
+}
+
+func diffStrings(oldList []string, newList []string) ([]string, []string) {
+	oldSet := make(map[string]bool, len(oldList))
+	for _, s := range oldList {
+		oldSet[s] = true
+	}
+	newSet := make(map[string]bool, len(newList))
+	var added, removed []string
+	for _, s := range newList {
+		newSet[s] = true
+		if !oldSet[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range oldList {
+		if !newSet[s] {
+			removed = append(removed, s)
+		}
+	}
+	return mergeStrings(nil, added), mergeStrings(nil, removed)
+}
+
+// sensitiveMatches 使用敏感信息规则匹配文本，返回 规则名: 匹配内容
+func sensitiveMatches(text string) []string {
+	if text == "" {
+		return nil
+	}
+	var result []string
+	for _, rule := range global.SensitiveRules {
+		if !rule.State || rule.RuleCompile == nil {
+			continue
+		}
+		count := 0
+		m, _ := rule.RuleCompile.FindStringMatch(text)
+		for m != nil && count < maxSecretMatches {
+			result = append(result, rule.Name+": "+m.String())
+			count++
+			m, _ = rule.RuleCompile.FindNextMatch(m)
+		}
+	}
+	return result
+}
+
+// mergeStrings 合并去重并排序
+func mergeStrings(a []string, b []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, s := range append(a, b...) {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -8,10 +8,14 @@
 package runner
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
@@ -34,6 +38,8 @@ func Run(op options.TaskOptions) error {
 	handler.TaskHandle.ProgressStart("scan", op.Target, op.ID, 1)
 	// 设置PassiveScan的input
 	op.ModuleRunWg = &wg
+	// 加载任务的认证配置
+	authmanager.GlobalAuthManager.AddTask(op.ID, op.Target, op.AuthProfiles)
 	switch op.Type {
 	case "subdomainSource":
 	case "assetSource":
@@ -44,7 +50,15 @@ func Run(op options.TaskOptions) error {
 	default:
 		op.TargetHandler = append(op.TargetHandler, "7bbaec6487f51a9aafeff4720c7643f0")
 	}
+	// 记录目标在各模块中的处理过程
+	traceCtx, span := tracing.StartTarget(op.ID, op.TaskName, op.Target, op.Type)
+	defer span.End()
+	op.TraceCtx = traceCtx
 	process := modules.CreateScanProcess(&op)
+	// 统计各模块输入通道的积压
+	defer metrics.TrackInputs(op.InputChan)()
+	// 登记正在运行的目标，供本地控制接口查询
+	defer track(&op)()
 	ch := make(chan interface{})
 	process.SetInput(ch)
 	go func() {
@@ -60,6 +74,12 @@ func Run(op options.TaskOptions) error {
 			Type: "A",
 			Host: op.Target,
 		}
+		// 其他节点积压的子域名，保留解析结果
+		if op.SubWork != "" {
+			if err := json.Unmarshal([]byte(op.SubWork), &tmp); err != nil {
+				logger.SlogError(fmt.Sprintf("task %v sub work parse error: %v", op.ID, err))
+			}
+		}
 		op.InputChan["SubdomainSecurity"] <- tmp
 	case "assetSource", "asset":
 		var resultArray []interface{}
@@ -94,16 +114,30 @@ func Run(op options.TaskOptions) error {
 	wg.Wait()
 	end = time.Now()
 	duration := end.Sub(start)
+	ctx := contextmanager.GlobalContextManagers.GetContext(op.ID)
 	select {
-	case <-contextmanager.GlobalContextManagers.GetContext(op.ID).Done():
+	case <-ctx.Done():
+		if contextmanager.BudgetExhausted(ctx) {
+			// 任务时间预算用尽，目标记录为跳过并按完成处理
+			handler.TaskHandle.ProgressSkip("scan", op.Target, op.ID, "deadline")
+			tracing.Skip(traceCtx, "scan", "task time budget exhausted", op.Target)
+			handler.TaskHandle.ProgressEnd("scan", op.Target, op.ID, 1, duration)
+			if op.SubWork == "" {
+				handler.TaskHandle.TaskEnd(op.Target, op.ID)
+			}
+			handler.TaskHandle.EndTask()
+			return nil
+		}
 		// 增加完成计数
 		handler.TaskHandle.EndTask()
 		return fmt.Errorf("task Cancel")
 	default:
 		// 记录模块完成日志
 		handler.TaskHandle.ProgressEnd("scan", op.Target, op.ID, 1, duration)
-		// 记录完成时间以及完成目标
-		handler.TaskHandle.TaskEnd(op.Target, op.ID)
+		// 记录完成时间以及完成目标，子任务不计入目标进度
+		if op.SubWork == "" {
+			handler.TaskHandle.TaskEnd(op.Target, op.ID)
+		}
 		// 增加完成计数
 		handler.TaskHandle.EndTask()
 		return nil
@@ -0,0 +1,132 @@
+// scheduler-------------------------------------
+// @file      : cron_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 17:20
+// -------------------------------------------
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every 30s",
+		"@every soon",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) accepted an invalid expression", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-10-21 是周三
+	from := time.Date(2026, 10, 21, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 21, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 21, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 21, 10, 25, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2026, 10, 21, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 10, 22, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * SAT", time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC)},
+		// 周日可以写作 0 或 7
+		{"0 0 * * 7", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// 日和周都不是 * 时满足任意一个即可
+		{"0 0 13 * fri", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 22, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 21, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2026, 10, 21, 10, 30, 0, 0, time.UTC)},
+		// 不存在的日期
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q Next(%v) = %v, want %v", tt.spec, from, got, tt.want)
+		}
+	}
+}
+
+// TestNextEvery 不同节点在同一个间隔内的不同时间计算出相同的运行时间
+func TestNextEvery(t *testing.T) {
+	c, err := ParseCron("@every 90m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 10, 21, 10, 30, 0, 0, time.UTC)
+	// 一个节点接收任务时注册，另一个节点在相邻分钟的检查中计算
+	for _, from := range []time.Time{
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 21, 9, 0, 1, 0, time.UTC),
+		time.Date(2026, 10, 21, 9, 41, 10, 0, time.UTC),
+		time.Date(2026, 10, 21, 9, 42, 40, 0, time.UTC),
+		time.Date(2026, 10, 21, 10, 29, 59, 0, time.UTC),
+		time.Date(2026, 10, 21, 17, 41, 10, 0, time.FixedZone("CST", 8*3600)),
+	} {
+		if got := c.Next(from); !got.Equal(want) {
+			t.Errorf("Next(%v) = %v, want %v", from, got, want)
+		}
+	}
+	// 在运行时间之后检查的节点得到相同的下一次运行时间
+	for _, from := range []time.Time{want, want.Add(30 * time.Second), want.Add(time.Minute + 10*time.Second)} {
+		if got := c.Next(from); !got.Equal(want.Add(90 * time.Minute)) {
+			t.Errorf("Next(%v) = %v, want %v", from, got, want.Add(90*time.Minute))
+		}
+	}
+}
+
+// TestNextExact 正好在运行时间时返回下一次运行时间
+func TestNextExact(t *testing.T) {
+	c, err := ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC)
+	if got, want := c.Next(from), from.Add(time.Hour); !got.Equal(want) {
+		t.Fatalf("Next(%v) = %v, want %v", from, got, want)
+	}
+}
+
+func TestNextLocation(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	c, err := ParseCron("0 8 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 10, 21, 9, 0, 0, 0, loc)
+	want := time.Date(2026, 10, 22, 8, 0, 0, 0, loc)
+	if got := c.Next(from); !got.Equal(want) {
+		t.Fatalf("Next(%v) = %v, want %v", from, got, want)
+	}
+}
@@ -0,0 +1,264 @@
+// scheduler-------------------------------------
+// @file      : scheduler.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/21 10:40
+// -------------------------------------------
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/stream"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/workqueue"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"time"
+)
+
+const (
+	// checkInterval 检查到期计划的间隔
+	checkInterval = 30 * time.Second
+	// lockExpiration 单次触发的选主锁过期时间
+	lockExpiration = time.Hour
+)
+
+// Schedule 保存在本地pebbledb中的周期任务
+type Schedule struct {
+	ID       string    `json:"id"`
+	TaskName string    `json:"taskName"`
+	Cron     string    `json:"cron"`
+	Task     string    `json:"task"`    // 服务端下发的原始任务信息
+	Targets  []string  `json:"targets"` // 每次运行的目标
+	NextRun  time.Time `json:"nextRun"`
+	LastRun  time.Time `json:"lastRun"`
+}
+
+func scheduleKey(id string) []byte {
+	return []byte("schedule:" + id)
+}
+
+// Register 保存周期任务，在任务目标被消费之前调用，记录本次下发的目标供之后的运行使用
+// 同一任务下发到多个节点时，每个节点都会保存，触发时通过redis选出一个节点下发
+func Register(taskInfo string, option options.TaskOptions) {
+	cron, err := ParseCron(option.Schedule)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("task %v schedule error: %v", option.TaskName, err))
+		return
+	}
+	if cron.Next(time.Now()).IsZero() {
+		logger.SlogError(fmt.Sprintf("task %v schedule error: %v never runs", option.TaskName, option.Schedule))
+		return
+	}
+	targets, err := redis.RedisClient.LRange(context.Background(), "TaskInfo:"+option.ID, 0, -1)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v get targets error: %v", option.ID, err))
+	}
+	s := Schedule{
+		ID:       option.ID,
+		TaskName: option.TaskName,
+		Cron:     option.Schedule,
+		Task:     taskInfo,
+		NextRun:  cron.Next(time.Now()),
+	}
+	s.Targets = utils.Tools.RemoveStringDuplicates(targets)
+	if old, err := load(option.ID); err == nil && old != nil {
+		s.LastRun = old.LastRun
+		if old.Cron == s.Cron {
+			s.NextRun = old.NextRun
+		}
+		// 重复下发时目标可能已经被其他节点消费完
+		if len(s.Targets) == 0 {
+			s.Targets = old.Targets
+		}
+	}
+	if err := save(&s); err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v save error: %v", option.ID, err))
+		return
+	}
+	ctx := context.Background()
+	// 节点可能只消费了部分目标，目标和参与的节点记录到redis中，触发时合并
+	if len(targets) != 0 {
+		members := make([]interface{}, len(targets))
+		for i, t := range targets {
+			members[i] = t
+		}
+		if _, err := redis.RedisClient.SAdd(ctx, "ScheduleTargets:"+option.ID, members...); err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("schedule %v save targets error: %v", option.ID, err))
+		}
+	}
+	if _, err := redis.RedisClient.SAdd(ctx, "ScheduleNodes:"+option.ID, global.AppConfig.NodeName); err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v save node error: %v", option.ID, err))
+	}
+	logger.SlogInfo(fmt.Sprintf("task %v schedule registered: %v next run %v", option.TaskName, option.Schedule, s.NextRun.Format("2006-01-02 15:04:05")))
+}
+
+// Delete 删除周期任务
+func Delete(id string) {
+	value, _ := pebbledb.PebbleStore.Get(scheduleKey(id))
+	if value == nil {
+		return
+	}
+	if err := pebbledb.PebbleStore.Delete(scheduleKey(id)); err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v delete error: %v", id, err))
+	}
+	ctx := context.Background()
+	_, _ = redis.RedisClient.SRem(ctx, "ScheduleNodes:"+id, global.AppConfig.NodeName)
+	nodes, err := redis.RedisClient.SMembers(ctx, "ScheduleNodes:"+id)
+	if err == nil && len(nodes) == 0 {
+		_ = redis.RedisClient.Del(ctx, "ScheduleTargets:"+id)
+	}
+	logger.SlogInfo(fmt.Sprintf("schedule deleted: %v", id))
+}
+
+// Run 定时检查本地保存的周期任务，到期时触发
+func Run() {
+	ticker := time.Tick(checkInterval)
+	for {
+		<-ticker
+		schedules, err := pebbledb.PebbleStore.GetKeysWithPrefix("schedule:")
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("schedule load error: %v", err))
+			continue
+		}
+		now := time.Now()
+		for key, value := range schedules {
+			var s Schedule
+			if err := json.Unmarshal(value, &s); err != nil {
+				logger.SlogErrorLocal(fmt.Sprintf("schedule %v parse error: %v", key, err))
+				continue
+			}
+			cron, err := ParseCron(s.Cron)
+			if err != nil {
+				logger.SlogErrorLocal(fmt.Sprintf("schedule %v cron error: %v", key, err))
+				continue
+			}
+			if s.NextRun.IsZero() {
+				continue
+			}
+			// 旧版本保存的 @every 运行时间与节点有关，对齐到共同的运行时间，保证各节点使用相同的锁
+			s.NextRun = cron.Next(s.NextRun.Add(-time.Nanosecond))
+			if now.Before(s.NextRun) {
+				continue
+			}
+			if now.Sub(s.NextRun) > checkInterval*2 {
+				logger.SlogInfoLocal(fmt.Sprintf("schedule %v missed run at %v, run now", s.TaskName, s.NextRun.Format("2006-01-02 15:04:05")))
+			}
+			fire(&s)
+			s.NextRun = cron.Next(now)
+			if err := save(&s); err != nil {
+				logger.SlogErrorLocal(fmt.Sprintf("schedule %v save error: %v", s.ID, err))
+			}
+		}
+	}
+}
+
+// fire 触发一次周期任务，同一次触发只有获得锁的节点将目标和任务下发到参与的节点
+func fire(s *Schedule) {
+	ctx := context.Background()
+	lockKey := fmt.Sprintf("ScheduleLock:%v:%v", s.ID, s.NextRun.Unix())
+	ok, err := redis.RedisClient.SetNX(ctx, lockKey, global.AppConfig.NodeName, lockExpiration)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v lock error: %v", s.ID, err))
+		return
+	}
+	if !ok {
+		// 其他节点已经触发
+		s.LastRun = s.NextRun
+		return
+	}
+	// 上一次运行还有未领取的目标、未释放的租约或积压的子任务，跳过本次
+	running, err := workqueue.Active(ctx, s.ID)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v check running error: %v", s.ID, err))
+		return
+	}
+	if running {
+		logger.SlogInfo(fmt.Sprintf("schedule %v previous run not finished, skip", s.TaskName))
+		return
+	}
+	targets := s.Targets
+	if shared, err := redis.RedisClient.SMembers(ctx, "ScheduleTargets:"+s.ID); err == nil {
+		targets = utils.Tools.RemoveStringDuplicates(append(append([]string{}, targets...), shared...))
+	}
+	if len(targets) == 0 {
+		logger.SlogInfo(fmt.Sprintf("schedule %v has no targets, skip", s.TaskName))
+		return
+	}
+	var task map[string]interface{}
+	if err := json.Unmarshal([]byte(s.Task), &task); err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v task parse error: %v", s.ID, err))
+		return
+	}
+	task["scheduleRun"] = true
+	task["isStart"] = false
+	taskInfo, err := json.Marshal(task)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("schedule %v task marshal error: %v", s.ID, err))
+		return
+	}
+	if err := resetRun(ctx, s.ID); err != nil {
+		logger.SlogError(fmt.Sprintf("schedule %v reset previous run error: %v", s.TaskName, err))
+		return
+	}
+	values := make([]interface{}, len(targets))
+	for i, t := range targets {
+		values[i] = t
+	}
+	if _, err := redis.RedisClient.PushToList(ctx, "TaskInfo:"+s.ID, values...); err != nil {
+		logger.SlogError(fmt.Sprintf("schedule %v push targets error: %v", s.TaskName, err))
+		return
+	}
+	nodes, err := redis.RedisClient.SMembers(ctx, "ScheduleNodes:"+s.ID)
+	if err != nil || len(nodes) == 0 {
+		nodes = []string{global.AppConfig.NodeName}
+	}
+	for _, node := range nodes {
+		if err := stream.Publish(ctx, redis.RedisClient.Client(), stream.TaskStream(node), string(taskInfo)); err != nil {
+			logger.SlogError(fmt.Sprintf("schedule %v push task to %v error: %v", s.TaskName, node, err))
+		}
+	}
+	s.LastRun = s.NextRun
+	logger.SlogInfo(fmt.Sprintf("schedule %v fired: %v targets, nodes %v", s.TaskName, len(targets), nodes))
+}
+
+// resetRun 清除上一次运行在redis中留下的去重记录、进度和报告锁，节点本地的去重缓存在收到任务时清除
+func resetRun(ctx context.Context, id string) error {
+	for _, pattern := range []string{"duplicates:" + id + ":*", "TaskInfo:progress:" + id + ":*"} {
+		if _, err := redis.RedisClient.DelPattern(ctx, pattern); err != nil {
+			return err
+		}
+	}
+	for _, key := range []string{"TaskInfo:tmp:" + id, "TaskInfo:time:" + id, "TaskReport:" + id} {
+		if err := redis.RedisClient.Del(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func load(id string) (*Schedule, error) {
+	value, err := pebbledb.PebbleStore.Get(scheduleKey(id))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	var s Schedule
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func save(s *Schedule) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return pebbledb.PebbleStore.Put(scheduleKey(s.ID), data)
+}
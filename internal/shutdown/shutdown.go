@@ -0,0 +1,204 @@
+// shutdown-------------------------------------
+// @file      : shutdown.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/24 15:30
+// -------------------------------------------
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 节点退出分为三个阶段，每个阶段按注册顺序运行
+const (
+	StageDrain = iota // 停止领取新的目标，在宽限期内等待运行中的插件完成
+	StageForce        // 宽限期超时，取消运行中的任务，未完成的目标保留在本地检查点
+	StageFlush        // 写入结果队列和本地检查点，注销节点
+)
+
+// 进程退出码
+const (
+	ExitDrained = 0 // 运行中的目标在宽限期内完成
+	ExitForced  = 3 // 宽限期超时，中断的目标在重启后从本地检查点继续运行
+	ExitFailed  = 4 // 写入结果或检查点失败
+)
+
+const (
+	defaultGrace = 30 * time.Second
+	forceTimeout = 15 * time.Second
+	flushTimeout = 30 * time.Second
+)
+
+type hook struct {
+	stage int
+	name  string
+	fn    func(ctx context.Context) error
+}
+
+var (
+	mu       sync.Mutex
+	hooks    []hook
+	draining = make(chan struct{})
+	once     sync.Once
+	finished = make(chan struct{})
+	status   int
+	// exit 测试时替换，避免退出测试进程
+	exit = os.Exit
+)
+
+// Register 注册节点退出时运行的函数
+func Register(stage int, name string, fn func(ctx context.Context) error) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, hook{stage: stage, name: name, fn: fn})
+}
+
+// Draining 节点开始退出时关闭
+func Draining() <-chan struct{} {
+	return draining
+}
+
+// IsDraining 节点是否正在退出
+func IsDraining() bool {
+	select {
+	case <-draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithDrain 返回节点开始退出时取消的上下文，用于停止领取新的目标
+func WithDrain(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-draining:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// Wait 在上下文结束前等待 fn 运行完毕，用于不支持上下文的关闭方法
+func Wait(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Grace 等待运行中的目标完成的宽限期
+func Grace() time.Duration {
+	if global.AppConfig.ShutdownGrace > 0 {
+		return time.Duration(global.AppConfig.ShutdownGrace) * time.Second
+	}
+	return defaultGrace
+}
+
+// Shutdown 退出节点，返回进程退出码，重复调用时等待第一次调用完成
+func Shutdown(reason string, grace time.Duration) int {
+	once.Do(func() {
+		status = run(reason, grace)
+		close(finished)
+	})
+	<-finished
+	return status
+}
+
+// Exit 退出节点并结束进程
+func Exit(reason string) {
+	exit(Shutdown(reason, Grace()))
+}
+
+// Listen 收到 SIGINT 或 SIGTERM 时退出节点，退出过程中再次收到信号时立即结束进程
+func Listen() {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		go func() {
+			sig := <-sigs
+			logger.SlogWarnLocal(fmt.Sprintf("shutdown: received %v again, exit now", sig))
+			exit(ExitForced)
+		}()
+		Exit(fmt.Sprintf("signal %v", sig))
+	}()
+}
+
+func run(reason string, grace time.Duration) int {
+	logger.SlogWarnLocal(fmt.Sprintf("shutdown begin: %v, grace period %v", reason, grace))
+	close(draining)
+	code := ExitDrained
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	err := runStage(ctx, StageDrain)
+	cancel()
+	if err != nil {
+		logger.SlogWarnLocal(fmt.Sprintf("shutdown: grace period exceeded, interrupt running tasks: %v", err))
+		code = ExitForced
+		ctx, cancel = context.WithTimeout(context.Background(), forceTimeout)
+		err = runStage(ctx, StageForce)
+		cancel()
+		if err != nil {
+			logger.SlogErrorLocal(fmt.Sprintf("shutdown: interrupt running tasks: %v", err))
+		}
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), flushTimeout)
+	err = runStage(ctx, StageFlush)
+	cancel()
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("shutdown: flush: %v", err))
+		if code == ExitDrained {
+			code = ExitFailed
+		}
+	}
+	logger.SlogWarnLocal(fmt.Sprintf("shutdown end: %v, exit status %v", reason, code))
+	if logger.ZapLog != nil {
+		_ = logger.ZapLog.Sync()
+	}
+	return code
+}
+
+// runStage 运行阶段中的所有函数，返回遇到的错误
+func runStage(ctx context.Context, stage int) error {
+	mu.Lock()
+	stageHooks := make([]hook, 0, len(hooks))
+	for _, h := range hooks {
+		if h.stage == stage {
+			stageHooks = append(stageHooks, h)
+		}
+	}
+	mu.Unlock()
+	var errs []error
+	for _, h := range stageHooks {
+		start := time.Now()
+		err := h.fn(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", h.name, err))
+			continue
+		}
+		logger.SlogInfoLocal(fmt.Sprintf("shutdown: %v done in %v", h.name, time.Since(start).Round(time.Millisecond)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,170 @@
+// shutdown-------------------------------------
+// @file      : shutdown_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 16:00
+// -------------------------------------------
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"go.uber.org/zap"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reset 恢复包的初始状态，每个测试都从未退出的节点开始
+func reset(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	hooks = nil
+	draining = make(chan struct{})
+	once = sync.Once{}
+	finished = make(chan struct{})
+	status = 0
+	t.Cleanup(func() { exit = os.Exit })
+}
+
+// recorder 记录各阶段函数的运行顺序
+type recorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *recorder) hook(name string, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		r.order = append(r.order, name)
+		r.mu.Unlock()
+		return err
+	}
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.order...)
+}
+
+// listened Listen 注册的信号处理在进程结束前一直有效，每个进程只测试一次
+var listened bool
+
+func TestSignal(t *testing.T) {
+	if listened {
+		t.Skip("signal handler already registered in this process")
+	}
+	listened = true
+	reset(t)
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+
+	// 模拟正在运行的目标，节点开始退出后完成
+	rec := &recorder{}
+	claim, cancel := WithDrain(context.Background())
+	defer cancel()
+	targetDone := make(chan struct{})
+	go func() {
+		<-claim.Done()
+		time.Sleep(20 * time.Millisecond)
+		close(targetDone)
+	}()
+	Register(StageDrain, "targets", func(ctx context.Context) error {
+		rec.hook("targets", nil)(ctx)
+		select {
+		case <-targetDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	Register(StageForce, "interrupt", rec.hook("interrupt", nil))
+	Register(StageFlush, "results", rec.hook("results", nil))
+	Register(StageFlush, "checkpoint", rec.hook("checkpoint", nil))
+
+	Listen()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-codes:
+		if code != ExitDrained {
+			t.Fatalf("exit status %v, want %v", code, ExitDrained)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGTERM did not shut down the node")
+	}
+	if !IsDraining() {
+		t.Fatal("node is not draining after SIGTERM")
+	}
+	want := []string{"targets", "results", "checkpoint"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stages ran %v, want %v", got, want)
+	}
+}
+
+func TestGraceExceeded(t *testing.T) {
+	reset(t)
+	rec := &recorder{}
+	Register(StageDrain, "targets", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	Register(StageForce, "interrupt", rec.hook("interrupt", nil))
+	Register(StageFlush, "results", rec.hook("results", nil))
+
+	if code := Shutdown("test", 20*time.Millisecond); code != ExitForced {
+		t.Fatalf("exit status %v, want %v", code, ExitForced)
+	}
+	want := []string{"interrupt", "results"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stages ran %v, want %v", got, want)
+	}
+}
+
+func TestFlushFailed(t *testing.T) {
+	reset(t)
+	rec := &recorder{}
+	Register(StageDrain, "targets", rec.hook("targets", nil))
+	Register(StageFlush, "results", rec.hook("results", errors.New("mongodb unavailable")))
+	// 写入失败时后面的函数继续运行
+	Register(StageFlush, "checkpoint", rec.hook("checkpoint", nil))
+
+	if code := Shutdown("test", time.Second); code != ExitFailed {
+		t.Fatalf("exit status %v, want %v", code, ExitFailed)
+	}
+	want := []string{"targets", "results", "checkpoint"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stages ran %v, want %v", got, want)
+	}
+}
+
+func TestShutdownOnce(t *testing.T) {
+	reset(t)
+	rec := &recorder{}
+	Register(StageFlush, "results", rec.hook("results", nil))
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = Shutdown("test", time.Second)
+		}(i)
+	}
+	wg.Wait()
+	for _, code := range codes {
+		if code != ExitDrained {
+			t.Fatalf("exit status %v, want %v", codes, ExitDrained)
+		}
+	}
+	if got := rec.get(); len(got) != 1 {
+		t.Fatalf("flush ran %v times, want 1", len(got))
+	}
+}
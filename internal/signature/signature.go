@@ -0,0 +1,67 @@
+// signature-------------------------------------
+// @file      : signature.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 20:10
+// -------------------------------------------
+
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"strings"
+)
+
+// 服务端使用与节点相同的密钥计算签名，签名内容为各字段使用换行连接后的 HMAC-SHA256，十六进制编码
+// 控制消息: node \n name \n type \n content \n timestamp \n nonce，node 为接收消息的节点
+// 插件源码: module \n hash \n source
+
+var (
+	ErrNoKey    = errors.New("signature key is not configured")
+	ErrUnsigned = errors.New("missing signature")
+	ErrMismatch = errors.New("signature mismatch")
+)
+
+// Enabled 节点是否配置了签名密钥，配置后拒绝没有签名或者签名错误的消息和插件
+func Enabled() bool {
+	return global.AppConfig.Signature.Key != ""
+}
+
+// Unsigned 没有配置密钥并且明确允许不签名，此时不校验签名
+func Unsigned() bool {
+	return !Enabled() && global.AppConfig.Signature.AllowUnsigned
+}
+
+// Sign 使用密钥计算签名
+func Sign(key string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 使用节点配置的密钥校验签名
+// 没有配置密钥时返回 ErrNoKey，只有配置 allowUnsigned 时不校验
+func Verify(sig string, parts ...string) error {
+	if Unsigned() {
+		return nil
+	}
+	if !Enabled() {
+		return ErrNoKey
+	}
+	if sig == "" {
+		return ErrUnsigned
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrMismatch
+	}
+	expected, _ := hex.DecodeString(Sign(global.AppConfig.Signature.Key, parts...))
+	if !hmac.Equal(got, expected) {
+		return ErrMismatch
+	}
+	return nil
+}
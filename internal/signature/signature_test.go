@@ -0,0 +1,72 @@
+// signature-------------------------------------
+// @file      : signature_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 11:00
+// -------------------------------------------
+
+package signature
+
+import (
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"testing"
+)
+
+func setConfig(t *testing.T, cfg global.SignatureConfig) {
+	old := global.AppConfig.Signature
+	global.AppConfig.Signature = cfg
+	t.Cleanup(func() { global.AppConfig.Signature = old })
+}
+
+func TestVerify(t *testing.T) {
+	parts := []string{"node1", "n", "install_plugin", "id", "1700000000", "abc"}
+	setConfig(t, global.SignatureConfig{Key: "secret"})
+	sig := Sign("secret", parts...)
+
+	if err := Verify(sig, parts...); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := Verify("", parts...); !errors.Is(err, ErrUnsigned) {
+		t.Fatalf("empty signature: got %v, want ErrUnsigned", err)
+	}
+	if err := Verify("zz", parts...); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("invalid hex: got %v, want ErrMismatch", err)
+	}
+	if err := Verify(Sign("other", parts...), parts...); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("wrong key: got %v, want ErrMismatch", err)
+	}
+	// 签名绑定接收节点，其他节点不能使用
+	other := append([]string{"node2"}, parts[1:]...)
+	if err := Verify(sig, other...); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("other node: got %v, want ErrMismatch", err)
+	}
+	// 字段之间使用换行分隔，移动分隔位置后签名不同
+	if err := Verify(Sign("secret", "ab", "c"), "a", "bc"); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("shifted parts: got %v, want ErrMismatch", err)
+	}
+}
+
+func TestVerifyWithoutKey(t *testing.T) {
+	setConfig(t, global.SignatureConfig{})
+	if err := Verify("", "a"); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("no key: got %v, want ErrNoKey", err)
+	}
+	if Enabled() || Unsigned() {
+		t.Fatalf("no key: Enabled=%v Unsigned=%v", Enabled(), Unsigned())
+	}
+
+	setConfig(t, global.SignatureConfig{AllowUnsigned: true})
+	if err := Verify("", "a"); err != nil {
+		t.Fatalf("allowUnsigned: got %v, want nil", err)
+	}
+
+	// 配置密钥后 allowUnsigned 不再生效
+	setConfig(t, global.SignatureConfig{Key: "secret", AllowUnsigned: true})
+	if Unsigned() {
+		t.Fatal("key configured but Unsigned reports true")
+	}
+	if err := Verify("", "a"); !errors.Is(err, ErrUnsigned) {
+		t.Fatalf("key with allowUnsigned: got %v, want ErrUnsigned", err)
+	}
+}
@@ -0,0 +1,207 @@
+// stream-------------------------------------
+// @file      : stream.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 15:20
+// -------------------------------------------
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	goRedis "github.com/redis/go-redis/v9"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Redis中的键
+// NodeControlStream:<node>   服务端下发的控制消息，内容与 refresh_config:<node> 列表中的消息相同
+// NodeTaskStream:<node>      服务端下发的任务，内容与 NodeTask:<node> 列表中的消息相同
+// 每个节点在自己的流中使用 Group 消费者组，消费者名称为节点名称
+
+// Field 消息内容所在的字段，内容与原来列表中的消息相同
+const Field = "message"
+
+// Group 节点使用的消费者组
+const Group = "scan"
+
+const (
+	// defaultBlock 阻塞等待新消息的时间，节点退出时最多等待该时间
+	defaultBlock = 5 * time.Second
+	// retryInterval redis出错后重试的间隔
+	retryInterval = 3 * time.Second
+	// batchSize 每次读取的消息数量
+	batchSize = 20
+)
+
+// ControlStream 节点的控制消息流
+func ControlStream(node string) string {
+	return "NodeControlStream:" + node
+}
+
+// TaskStream 节点的任务流
+func TaskStream(node string) string {
+	return "NodeTaskStream:" + node
+}
+
+// Publish 向流中写入消息
+func Publish(ctx context.Context, client goRedis.Cmdable, name string, body string) error {
+	return client.XAdd(ctx, &goRedis.XAddArgs{
+		Stream: name,
+		Values: map[string]interface{}{Field: body},
+	}).Err()
+}
+
+// Backend 消费者使用的redis命令，*goRedis.Client 实现了该接口，测试时可以替换为本地实现
+type Backend interface {
+	XGroupCreateMkStream(ctx context.Context, stream, group, start string) *goRedis.StatusCmd
+	XReadGroup(ctx context.Context, a *goRedis.XReadGroupArgs) *goRedis.XStreamSliceCmd
+	XAck(ctx context.Context, stream, group string, ids ...string) *goRedis.IntCmd
+}
+
+// Message 流中的一条消息
+type Message struct {
+	ID   string
+	Body string
+}
+
+// Consumer 消费者组中的消费者
+// 消息在确认前保留在消费者的待确认列表中，节点崩溃重启后先重新投递待确认的消息，再读取新的消息
+type Consumer struct {
+	Backend Backend
+	Stream  string
+	Group   string
+	Name    string
+	// Block 阻塞等待新消息的时间，默认5秒
+	Block time.Duration
+	// Handle 处理消息，返回true时立即确认，返回false时由调用方在处理完成后调用 Ack
+	Handle func(ctx context.Context, msg Message) bool
+
+	received atomic.Bool
+}
+
+// Received 是否收到过消息，服务端通过流下发消息时，节点降低旧列表的轮询频率
+func (c *Consumer) Received() bool {
+	return c.received.Load()
+}
+
+// Run 创建消费者组并持续读取消息，直到上下文取消
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		err := c.Backend.XGroupCreateMkStream(ctx, c.Stream, c.Group, "0").Err()
+		if err == nil || strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.SlogErrorLocal(fmt.Sprintf("stream %v create group error: %v", c.Stream, err))
+		if !sleep(ctx, retryInterval) {
+			return ctx.Err()
+		}
+	}
+	// 上次运行时投递但没有确认的消息
+	if err := c.pending(ctx); err != nil {
+		return err
+	}
+	block := c.Block
+	if block <= 0 {
+		block = defaultBlock
+	}
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		_, err := c.read(ctx, ">", block)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.SlogErrorLocal(fmt.Sprintf("stream %v read error: %v", c.Stream, err))
+			if !sleep(ctx, retryInterval) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// pending 重新投递当前消费者待确认的消息
+func (c *Consumer) pending(ctx context.Context) error {
+	start := "0"
+	for {
+		last, err := c.read(ctx, start, -1)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.SlogErrorLocal(fmt.Sprintf("stream %v read pending error: %v", c.Stream, err))
+			if !sleep(ctx, retryInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+		if last == "" {
+			return nil
+		}
+		start = last
+	}
+}
+
+// read 读取消息并投递，block 小于0时不阻塞，返回最后一条消息的ID，没有消息时返回空
+// start 为 ">" 时读取新的消息，为消息ID时读取该ID之后待确认的消息
+func (c *Consumer) read(ctx context.Context, start string, block time.Duration) (string, error) {
+	streams, err := c.Backend.XReadGroup(ctx, &goRedis.XReadGroupArgs{
+		Group:    c.Group,
+		Consumer: c.Name,
+		Streams:  []string{c.Stream, start},
+		Count:    batchSize,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, goRedis.Nil) {
+			return "", nil
+		}
+		return "", err
+	}
+	last := ""
+	for _, s := range streams {
+		for _, m := range s.Messages {
+			c.deliver(ctx, m)
+			last = m.ID
+		}
+	}
+	return last, nil
+}
+
+func (c *Consumer) deliver(ctx context.Context, m goRedis.XMessage) {
+	c.received.Store(true)
+	// 已经从流中删除的待确认消息内容为空，由 Handle 确认丢弃
+	body, _ := m.Values[Field].(string)
+	if c.Handle(ctx, Message{ID: m.ID, Body: body}) {
+		c.Ack(m.ID)
+	}
+}
+
+// Ack 确认消息，确认后的消息不会再次投递
+func (c *Consumer) Ack(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	err := c.Backend.XAck(context.Background(), c.Stream, c.Group, ids...).Err()
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("stream %v ack %v error: %v", c.Stream, ids, err))
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
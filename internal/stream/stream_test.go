@@ -0,0 +1,311 @@
+// stream-------------------------------------
+// @file      : stream_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/26 16:40
+// -------------------------------------------
+
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	goRedis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memBackend 内存中的单个流和消费者组，按 redis 的语义处理待确认列表
+type memBackend struct {
+	mu       sync.Mutex
+	seq      int
+	entries  []goRedis.XMessage
+	group    bool
+	last     int                       // 组中最后投递的消息序号
+	pending  map[string]map[string]int // 消费者 -> 待确认的消息ID -> 序号
+	added    chan struct{}
+	failRead int // 接下来失败的读取次数
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{pending: make(map[string]map[string]int), added: make(chan struct{})}
+}
+
+func seqOf(id string) int {
+	n, _ := strconv.Atoi(strings.SplitN(id, "-", 2)[0])
+	return n
+}
+
+func (b *memBackend) add(body string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	id := fmt.Sprintf("%d-0", b.seq)
+	b.entries = append(b.entries, goRedis.XMessage{ID: id, Values: map[string]interface{}{Field: body}})
+	close(b.added)
+	b.added = make(chan struct{})
+	return id
+}
+
+// del 从流中删除消息，待确认列表中保留ID
+func (b *memBackend) del(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, m := range b.entries {
+		if m.ID == id {
+			b.entries = append(b.entries[:i], b.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *memBackend) pendingCount(consumer string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending[consumer])
+}
+
+func (b *memBackend) XGroupCreateMkStream(ctx context.Context, stream, group, start string) *goRedis.StatusCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.group {
+		return goRedis.NewStatusResult("", errors.New("BUSYGROUP Consumer Group name already exists"))
+	}
+	b.group = true
+	return goRedis.NewStatusResult("OK", nil)
+}
+
+func (b *memBackend) XReadGroup(ctx context.Context, a *goRedis.XReadGroupArgs) *goRedis.XStreamSliceCmd {
+	stream, start := a.Streams[0], a.Streams[1]
+	for {
+		b.mu.Lock()
+		if b.failRead > 0 {
+			b.failRead--
+			b.mu.Unlock()
+			return goRedis.NewXStreamSliceCmdResult(nil, errors.New("connection refused"))
+		}
+		if b.pending[a.Consumer] == nil {
+			b.pending[a.Consumer] = make(map[string]int)
+		}
+		var msgs []goRedis.XMessage
+		if start != ">" {
+			// 待确认的消息，已删除的消息内容为空
+			after := seqOf(start)
+			for seq := after + 1; seq <= b.seq && int64(len(msgs)) < a.Count; seq++ {
+				id := fmt.Sprintf("%d-0", seq)
+				if _, ok := b.pending[a.Consumer][id]; !ok {
+					continue
+				}
+				msg := goRedis.XMessage{ID: id}
+				for _, m := range b.entries {
+					if m.ID == id {
+						msg = m
+					}
+				}
+				msgs = append(msgs, msg)
+			}
+			b.mu.Unlock()
+			return goRedis.NewXStreamSliceCmdResult([]goRedis.XStream{{Stream: stream, Messages: msgs}}, nil)
+		}
+		for _, m := range b.entries {
+			if seqOf(m.ID) > b.last && int64(len(msgs)) < a.Count {
+				msgs = append(msgs, m)
+				b.last = seqOf(m.ID)
+				b.pending[a.Consumer][m.ID] = b.last
+			}
+		}
+		added := b.added
+		b.mu.Unlock()
+		if len(msgs) != 0 {
+			return goRedis.NewXStreamSliceCmdResult([]goRedis.XStream{{Stream: stream, Messages: msgs}}, nil)
+		}
+		select {
+		case <-ctx.Done():
+			return goRedis.NewXStreamSliceCmdResult(nil, ctx.Err())
+		case <-time.After(a.Block):
+			return goRedis.NewXStreamSliceCmdResult(nil, goRedis.Nil)
+		case <-added:
+		}
+	}
+}
+
+func (b *memBackend) XAck(ctx context.Context, stream, group string, ids ...string) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, id := range ids {
+		for _, p := range b.pending {
+			if _, ok := p[id]; ok {
+				delete(p, id)
+				n++
+			}
+		}
+	}
+	return goRedis.NewIntResult(int64(n), nil)
+}
+
+// collector 记录收到的消息
+type collector struct {
+	mu   sync.Mutex
+	msgs []Message
+	got  chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{got: make(chan struct{}, 100)}
+}
+
+func (c *collector) handle(ack bool) func(ctx context.Context, msg Message) bool {
+	return func(ctx context.Context, msg Message) bool {
+		c.mu.Lock()
+		c.msgs = append(c.msgs, msg)
+		c.mu.Unlock()
+		c.got <- struct{}{}
+		return ack
+	}
+}
+
+func (c *collector) wait(t *testing.T, n int) []Message {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %v messages, want %v", i, n)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.msgs...)
+}
+
+// start 运行消费者，返回停止并等待消费者退出的函数
+func start(b Backend, name string, handle func(ctx context.Context, msg Message) bool) (*Consumer, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Consumer{Backend: b, Stream: TaskStream("node1"), Group: Group, Name: name, Block: 20 * time.Millisecond, Handle: handle}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Run(ctx)
+	}()
+	return c, func() {
+		cancel()
+		<-done
+	}
+}
+
+func bodies(msgs []Message) string {
+	var list []string
+	for _, m := range msgs {
+		list = append(list, m.Body)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestDelivery(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	b := newMemBackend()
+	b.add("a")
+	b.add("b")
+	col := newCollector()
+	c, stop := start(b, "node1", col.handle(true))
+	defer stop()
+
+	if got := bodies(col.wait(t, 2)); got != "a,b" {
+		t.Fatalf("received %v, want a,b", got)
+	}
+	// 阻塞读取期间写入的消息
+	b.add("c")
+	if got := bodies(col.wait(t, 1)); got != "a,b,c" {
+		t.Fatalf("received %v, want a,b,c", got)
+	}
+	if !c.Received() {
+		t.Fatal("Received reports false after delivery")
+	}
+	if n := b.pendingCount("node1"); n != 0 {
+		t.Fatalf("%v messages not acknowledged", n)
+	}
+}
+
+func TestRedelivery(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	b := newMemBackend()
+	b.add("a")
+	b.add("b")
+	b.add("c")
+
+	// 第一次运行时处理了消息但没有确认，模拟节点崩溃
+	col := newCollector()
+	c, stop := start(b, "node1", col.handle(false))
+	msgs := col.wait(t, 3)
+	c.Ack(msgs[1].ID)
+	stop()
+	if n := b.pendingCount("node1"); n != 2 {
+		t.Fatalf("pending = %v, want 2", n)
+	}
+	b.del(msgs[2].ID)
+	b.add("d")
+
+	// 重启后先重新投递待确认的消息，已删除的消息内容为空，再读取新的消息
+	col = newCollector()
+	_, stop = start(b, "node1", col.handle(true))
+	defer stop()
+	msgs = col.wait(t, 3)
+	if got := bodies(msgs); got != "a,,d" {
+		t.Fatalf("received %q, want %q", got, "a,,d")
+	}
+	if msgs[1].ID != "3-0" {
+		t.Fatalf("deleted message id = %v, want 3-0", msgs[1].ID)
+	}
+	if n := b.pendingCount("node1"); n != 0 {
+		t.Fatalf("%v messages not acknowledged", n)
+	}
+}
+
+// TestPendingBatches 待确认的消息超过一次读取的数量时分批重新投递
+func TestPendingBatches(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	b := newMemBackend()
+	total := batchSize*2 + 5
+	for i := 0; i < total; i++ {
+		b.add(strconv.Itoa(i))
+	}
+	col := newCollector()
+	col.got = make(chan struct{}, total*2)
+	_, stop := start(b, "node1", col.handle(false))
+	col.wait(t, total)
+	stop()
+
+	col = newCollector()
+	col.got = make(chan struct{}, total*2)
+	_, stop = start(b, "node1", col.handle(true))
+	defer stop()
+	msgs := col.wait(t, total)
+	for i, m := range msgs {
+		if m.Body != strconv.Itoa(i) {
+			t.Fatalf("message %v body = %v", i, m.Body)
+		}
+	}
+}
+
+func TestReadError(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	b := newMemBackend()
+	b.failRead = 1
+	b.add("a")
+	col := newCollector()
+	_, stop := start(b, "node1", col.handle(true))
+	defer stop()
+	// 读取出错后等待重试间隔再读取
+	select {
+	case <-col.got:
+	case <-time.After(retryInterval + 2*time.Second):
+		t.Fatal("message not delivered after read error")
+	}
+}
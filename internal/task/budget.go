@@ -0,0 +1,103 @@
+// task-------------------------------------
+// @file      : budget.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/22 15:10
+// -------------------------------------------
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/workqueue"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyModuleBudget 任务没有配置的模块使用节点配置的默认时间预算
+func applyModuleBudget(op *options.TaskOptions) {
+	if config.ModulesConfig == nil || len(config.ModulesConfig.ModuleBudget) == 0 {
+		return
+	}
+	budget := make(map[string]int, len(config.ModulesConfig.ModuleBudget)+len(op.ModuleBudget))
+	for module, seconds := range config.ModulesConfig.ModuleBudget {
+		budget[module] = seconds
+	}
+	for module, seconds := range op.ModuleBudget {
+		budget[module] = seconds
+	}
+	op.ModuleBudget = budget
+}
+
+// applyTimeBudget 任务设置了时间预算时为任务上下文设置截止时间
+// 截止时间由第一个开始运行的节点写入redis，其他节点以及重启后的节点使用同一个截止时间
+// 任务暂停中时使用暂停时剩余的时间预算
+func applyTimeBudget(op options.TaskOptions) {
+	if op.TimeBudget <= 0 {
+		return
+	}
+	ctx := context.Background()
+	if value, err := redis.RedisClient.Get(ctx, handler.PausedBudgetKey(op.ID)); err == nil {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			contextmanager.GlobalContextManagers.SetDeadline(op.ID, time.Now().Add(time.Duration(seconds)*time.Second))
+			return
+		}
+	}
+	budget := time.Duration(op.TimeBudget) * time.Second
+	deadline := time.Now().Add(budget)
+	ok, err := redis.RedisClient.SetNX(ctx, handler.DeadlineKey(op.ID), deadline.Unix(), budget)
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("task %v set deadline error: %v", op.ID, err))
+	} else if !ok {
+		value, err := redis.RedisClient.Get(ctx, handler.DeadlineKey(op.ID))
+		if err == nil {
+			if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+				deadline = time.Unix(unix, 0)
+			}
+		}
+	}
+	contextmanager.GlobalContextManagers.SetDeadline(op.ID, deadline)
+}
+
+// skipRemainingTargets 任务时间预算用尽后，领取剩余的目标和本地缓存中没有运行的目标并记录为跳过，使任务可以正常结束
+func skipRemainingTargets(op options.TaskOptions, queue *workqueue.Queue) {
+	ctx := context.Background()
+	skipped := 0
+	for {
+		item, ok, err := queue.Claim(ctx)
+		if err != nil {
+			logger.SlogError(fmt.Sprintf("task %v skip targets error: %v", op.ID, err))
+			break
+		}
+		if !ok {
+			break
+		}
+		if item.Kind == "" {
+			handler.TaskHandle.ProgressSkip("scan", item.Value, op.ID, "target")
+			handler.TaskHandle.TaskEnd(item.Value, op.ID)
+			skipped++
+		}
+		queue.Done(item)
+	}
+	targets, err := pebbledb.PebbleStore.GetKeysWithPrefix(op.ID + ":")
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("pebbledb get task targets error: %v", err))
+	}
+	for idTarget := range targets {
+		target := strings.SplitN(idTarget, ":", 2)[1]
+		handler.TaskHandle.ProgressSkip("scan", target, op.ID, "target")
+		handler.TaskHandle.TaskEnd(target, op.ID)
+		DeletePebbleTarget(pebbledb.PebbleStore, []byte(idTarget))
+		skipped++
+	}
+	logger.SlogInfo(fmt.Sprintf("task %v time budget exhausted, %v targets skipped", op.TaskName, skipped))
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/runner"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/workqueue"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"strconv"
@@ -29,6 +31,27 @@ func DeletePebbleTarget(PebbleStore *pebbledb.PebbleDB, targetKey []byte) {
 	}
 }
 
+// runModules 运行目标经过的模块，任务取消时返回错误，测试时替换
+var runModules = runner.Run
+
+// runTarget 运行一个目标，运行完毕后删除本地检查点
+// 任务取消或节点退出中断的目标保留检查点，重启后继续运行，其他节点积压的子任务没有检查点
+func runTarget(op options.TaskOptions) {
+	select {
+	case <-contextmanager.GlobalContextManagers.GetContext(op.ID).Done():
+		// 任务取消直接返回
+		return
+	default:
+	}
+	if err := runModules(op); err != nil {
+		// 说明该任务取消了，直接返回不进行删除目标
+		return
+	}
+	if op.SubWork == "" {
+		DeletePebbleTarget(pebbledb.PebbleStore, []byte(op.ID+":"+op.Target))
+	}
+}
+
 func RunPebbleTarget(runnerOption options.TaskOptions) {
 	var wg sync.WaitGroup
 	prefix := fmt.Sprintf("%s:", runnerOption.ID)
@@ -41,30 +64,39 @@ func RunPebbleTarget(runnerOption options.TaskOptions) {
 		}
 		return
 	}
+	queue := workqueue.Get(runnerOption.ID)
+	// 本地缓存的目标同样占用任务份额中的协程，节点退出时停止运行新的目标
+	shareCtx, shareCancel := shutdown.WithDrain(contextmanager.GlobalContextManagers.GetContext(runnerOption.ID))
+	defer shareCancel()
 	for idTarget, _ := range targets {
-		wg.Add(1)
+		// 节点退出时不再运行本地缓存的目标
+		if shutdown.IsDraining() {
+			break
+		}
 		// 创建 runnerOption 的副本
 		optionCopy := runnerOption
 		target := strings.SplitN(idTarget, ":", 2)
 		optionCopy.Target = target[1]
+		if pool.Share.Acquire(shareCtx, runnerOption.ID, "task") != nil {
+			break
+		}
+		// 节点重启前领取的目标租约已过期并被其他节点接管，不再重复运行
+		if queue != nil && !queue.Acquire(optionCopy.Target) {
+			logger.SlogInfoLocal(fmt.Sprintf("task %v target %v taken over by other node", runnerOption.ID, optionCopy.Target))
+			pool.Share.Release(runnerOption.ID, "task")
+			DeletePebbleTarget(pebbledb.PebbleStore, []byte(idTarget))
+			continue
+		}
+		wg.Add(1)
 		// 使用局部变量创建闭包
 		taskFunc := func(op options.TaskOptions) func() {
 			return func() {
 				defer wg.Done()
-				select {
-				case <-contextmanager.GlobalContextManagers.GetContext(op.ID).Done():
-					// 任务取消直接返回
-					return
-				default:
-					err := runner.Run(op)
-					if err != nil {
-						// 说明该任务取消了，直接返回不进行删除目标
-						return
-					} else {
-						// 目标运行完毕删除目标
-						DeletePebbleTarget(pebbledb.PebbleStore, []byte(op.ID+":"+op.Target))
-					}
+				defer pool.Share.Release(op.ID, "task")
+				if queue != nil {
+					defer queue.DoneTarget(op.Target)
 				}
+				runTarget(op)
 			}
 		}(optionCopy)
 
@@ -73,6 +105,10 @@ func RunPebbleTarget(runnerOption options.TaskOptions) {
 		if err != nil {
 			logger.SlogError(fmt.Sprintf("task pool error: %v", err))
 			// 如果提交任务失败，手动减少计数
+			if queue != nil {
+				queue.DoneTarget(optionCopy.Target)
+			}
+			pool.Share.Release(runnerOption.ID, "task")
 			wg.Done()
 		}
 	}
@@ -80,13 +116,14 @@ func RunPebbleTarget(runnerOption options.TaskOptions) {
 	wg.Wait()
 }
 
+// InitTaskOption 初始化任务使用的资源，每个任务使用自己的参数
 func InitTaskOption(runnerOption options.TaskOptions) {
 	// 初始化httpx
 	parameter, _ := utils.Tools.GetParameter(runnerOption.Parameters, "AssetMapping", "3a0d994a12305cb15a5cb7104d819623")
-	initHttpx(parameter)
+	initHttpx(runnerOption.ID, parameter)
 }
 
-func initHttpx(parameter string) {
+func initHttpx(taskId string, parameter string) {
 	cdncheck := "false"
 	screenshot := false
 	tlsprobe := false
@@ -130,9 +167,10 @@ func initHttpx(parameter string) {
 			}
 		}
 	}
-	utils.InitHttpx(cdncheck, screenshot, screenshotTimeout, tlsprobe, FollowRedirects, bypassHeader, threads)
+	utils.InitHttpx(taskId, cdncheck, screenshot, screenshotTimeout, tlsprobe, FollowRedirects, bypassHeader, threads)
 }
 
-func OptionClose() {
-	utils.HttpxClose()
+// OptionClose 关闭任务使用的资源
+func OptionClose(taskId string) {
+	utils.HttpxClose(taskId)
 }
@@ -0,0 +1,61 @@
+// task-------------------------------------
+// @file      : handler_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/11/03 15:40
+// -------------------------------------------
+
+package task
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+)
+
+// TestRunPebbleTargetShare 本地缓存的目标运行时占用任务的份额，运行完毕后释放
+func TestRunPebbleTargetShare(t *testing.T) {
+	logger.ZapLog = zap.NewNop()
+	oldStore, oldPool, oldRun := pebbledb.PebbleStore, pool.PoolManage, runModules
+	pebbledb.PebbleStore = openStore(t, t.TempDir())
+	contextmanager.NewContextManager()
+	pool.Initialize()
+	pool.PoolManage.InitializeModulesPools(&config.ModulesConfigStruct{MaxGoroutineCount: 4})
+	t.Cleanup(func() {
+		_ = pebbledb.PebbleStore.Close()
+		pebbledb.PebbleStore, pool.PoolManage, runModules = oldStore, oldPool, oldRun
+	})
+	targets := []string{"a.example.com", "b.example.com"}
+	for _, target := range targets {
+		if err := pebbledb.PebbleStore.Put([]byte("resume:"+target), []byte("")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Share.Register("resume", 0, 1)
+	defer pool.Share.Unregister("resume")
+
+	var mu sync.Mutex
+	used := make(map[string]int)
+	runModules = func(op options.TaskOptions) error {
+		mu.Lock()
+		used[op.Target] = pool.Share.Used(op.ID, "task")
+		mu.Unlock()
+		return nil
+	}
+	RunPebbleTarget(options.TaskOptions{ID: "resume"})
+
+	for _, target := range targets {
+		if used[target] < 1 {
+			t.Errorf("target %v ran without a task share slot", target)
+		}
+	}
+	if n := pool.Share.Used("resume", "task"); n != 0 {
+		t.Fatalf("share slots not released: %v", n)
+	}
+}
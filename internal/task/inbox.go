@@ -0,0 +1,96 @@
+// task-------------------------------------
+// @file      : inbox.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/25 16:10
+// -------------------------------------------
+
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/stream"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"sync"
+)
+
+// taskInbox 通过任务流收到的任务
+// 任务消息在任务运行结束或者被停止时确认，节点退出或崩溃时保持未确认，重启后重新投递
+type taskInbox struct {
+	mu       sync.Mutex
+	consumer *stream.Consumer
+	tasks    map[string]pendingTask // 等待运行的任务
+	acks     map[string][]string    // 任务id -> 未确认的消息id
+	wake     chan struct{}
+}
+
+var inbox = &taskInbox{
+	tasks: make(map[string]pendingTask),
+	acks:  make(map[string][]string),
+	wake:  make(chan struct{}, 1),
+}
+
+func init() {
+	handler.TaskRemoved = inbox.remove
+}
+
+// receive 处理任务流中的消息，任务结束时再确认
+func (b *taskInbox) receive(ctx context.Context, msg stream.Message) bool {
+	var runnerOption options.TaskOptions
+	err := json.Unmarshal([]byte(msg.Body), &runnerOption)
+	if err != nil || runnerOption.ID == "" {
+		logger.SlogError(fmt.Sprintf("Task message %v parse error: %v", msg.ID, err))
+		return true
+	}
+	b.mu.Lock()
+	b.acks[runnerOption.ID] = append(b.acks[runnerOption.ID], msg.ID)
+	if !runningTasks.has(runnerOption.ID) {
+		b.tasks[runnerOption.ID] = pendingTask{info: msg.Body, option: runnerOption}
+	}
+	b.mu.Unlock()
+	b.notify()
+	return false
+}
+
+// pending 等待运行的任务
+func (b *taskInbox) pending() []pendingTask {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := make([]pendingTask, 0, len(b.tasks))
+	for _, p := range b.tasks {
+		list = append(list, p)
+	}
+	return list
+}
+
+// started 任务开始运行，消息在任务结束时确认
+func (b *taskInbox) started(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tasks, id)
+}
+
+// remove 任务运行结束或者被停止，确认任务的所有消息
+func (b *taskInbox) remove(id string) {
+	b.mu.Lock()
+	delete(b.tasks, id)
+	ids := b.acks[id]
+	delete(b.acks, id)
+	consumer := b.consumer
+	b.mu.Unlock()
+	if consumer != nil {
+		consumer.Ack(ids...)
+	}
+}
+
+// notify 有新的任务或者任务结束时唤醒任务循环
+func (b *taskInbox) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
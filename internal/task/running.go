@@ -0,0 +1,98 @@
+// task-------------------------------------
+// @file      : running.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/22 11:20
+// -------------------------------------------
+
+package task
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/config"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/metrics"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"sync"
+)
+
+// defaultMaxTaskCount 未配置时节点同时运行的任务数
+const defaultMaxTaskCount = 3
+
+type pendingTask struct {
+	info   string
+	option options.TaskOptions
+}
+
+// taskRegistry 当前节点正在运行的任务及其优先级
+type taskRegistry struct {
+	mu    sync.Mutex
+	tasks map[string]int
+}
+
+var runningTasks = &taskRegistry{tasks: make(map[string]int)}
+
+func init() {
+	metrics.Gauge("running_tasks", "Tasks running on this node.", func() float64 {
+		return float64(runningTasks.count())
+	})
+}
+
+func maxTaskCount() int {
+	if config.ModulesConfig != nil && config.ModulesConfig.MaxTaskCount > 0 {
+		return config.ModulesConfig.MaxTaskCount
+	}
+	return defaultMaxTaskCount
+}
+
+// Running 当前节点正在运行的任务及其优先级
+func Running() map[string]int {
+	runningTasks.mu.Lock()
+	defer runningTasks.mu.Unlock()
+	tasks := make(map[string]int, len(runningTasks.tasks))
+	for id, priority := range runningTasks.tasks {
+		tasks[id] = priority
+	}
+	return tasks
+}
+
+func (r *taskRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.tasks)
+}
+
+func (r *taskRegistry) has(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tasks[id]
+	return ok
+}
+
+// begin 登记开始运行的任务，返回是否可以开始
+// 达到最大任务数时，只有优先级高于所有运行中任务的任务可以开始，在目标边界处抢占低优先级任务
+func (r *taskRegistry) begin(id string, priority int, max int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[id]; ok {
+		return false
+	}
+	if len(r.tasks) >= max {
+		for _, p := range r.tasks {
+			if p >= priority {
+				return false
+			}
+		}
+	}
+	r.tasks[id] = priority
+	return true
+}
+
+// end 移除任务，返回是否为最后一个运行的任务，重复调用返回false
+func (r *taskRegistry) end(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tasks[id]; !ok {
+		return false
+	}
+	delete(r.tasks, id)
+	return len(r.tasks) == 0
+}
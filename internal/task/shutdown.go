@@ -0,0 +1,38 @@
+// task-------------------------------------
+// @file      : shutdown.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/24 16:10
+// -------------------------------------------
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"time"
+)
+
+// Drain 等待当前节点正在运行的任务结束，节点退出时任务不再领取新的目标
+func Drain(ctx context.Context) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		n := runningTasks.count()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v tasks still running: %w", n, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Interrupt 取消正在运行的任务并等待结束，未完成的目标保留在本地，重启后继续运行
+func Interrupt(ctx context.Context) error {
+	contextmanager.GlobalContextManagers.CancelAllContexts()
+	return Drain(ctx)
+}
@@ -0,0 +1,182 @@
+// task-------------------------------------
+// @file      : shutdown_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/28 16:20
+// -------------------------------------------
+
+package task
+
+import (
+	"context"
+	"errors"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/cockroachdb/pebble"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePlugin 目标经过的插件，每个插件产生一个结果，block 为true时一直运行到任务取消
+type fakePlugin struct {
+	name  string
+	delay time.Duration
+	block bool
+}
+
+// fakeModules 按任务运行插件，替换 runner.Run
+type fakeModules struct {
+	plugins map[string][]fakePlugin
+	started chan string
+}
+
+func (f *fakeModules) run(op options.TaskOptions) error {
+	ctx := contextmanager.GlobalContextManagers.GetContext(op.ID)
+	f.started <- op.ID
+	for _, p := range f.plugins[op.ID] {
+		select {
+		case <-ctx.Done():
+			return errors.New("task Cancel")
+		case <-time.After(p.delay):
+		}
+		results.ResultQueues["URLScan"].Queue <- types.UrlResult{Input: op.Target, Output: op.Target + "/" + p.name}
+		if p.block {
+			<-ctx.Done()
+			return errors.New("task Cancel")
+		}
+	}
+	return nil
+}
+
+// resultStore 记录写入数据库的结果
+type resultStore struct {
+	mu   sync.Mutex
+	docs map[string][]string
+}
+
+func (s *resultStore) insert(name string, documents []interface{}) (*mongo.InsertManyResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range documents {
+		if r, ok := doc.(types.UrlResult); ok {
+			s.docs[name] = append(s.docs[name], r.Output)
+		}
+	}
+	return &mongo.InsertManyResult{}, nil
+}
+
+func (s *resultStore) get(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	docs := append([]string(nil), s.docs[name]...)
+	sort.Strings(docs)
+	return docs
+}
+
+func openStore(t *testing.T, dir string) *pebbledb.PebbleDB {
+	db, err := pebbledb.NewPebbleDB(&pebble.Options{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// shutdownRan Shutdown 和结果队列的关闭每个进程只运行一次
+var shutdownRan bool
+
+// TestShutdownPreservesCheckpoint 节点退出时，宽限期内完成的目标删除检查点，超时被中断的目标保留检查点，两者的结果都写入数据库
+func TestShutdownPreservesCheckpoint(t *testing.T) {
+	if shutdownRan {
+		t.Skip("shutdown already ran in this process")
+	}
+	shutdownRan = true
+	logger.ZapLog = zap.NewNop()
+	dir := t.TempDir()
+	pebbledb.PebbleStore = openStore(t, dir)
+	contextmanager.NewContextManager()
+	results.InitializeResultQueue()
+	store := &resultStore{docs: make(map[string][]string)}
+	oldInsert, oldRun := results.InsertMany, runModules
+	results.InsertMany = store.insert
+	modules := &fakeModules{
+		started: make(chan string, 2),
+		plugins: map[string][]fakePlugin{
+			// 在宽限期内完成
+			"fast": {{name: "httpx", delay: 100 * time.Millisecond}, {name: "crawler", delay: 200 * time.Millisecond}},
+			// 超过宽限期，被中断
+			"slow": {{name: "httpx"}, {name: "dirscan", block: true}},
+		},
+	}
+	runModules = modules.run
+	t.Cleanup(func() {
+		results.InsertMany, runModules = oldInsert, oldRun
+	})
+
+	shutdown.Register(shutdown.StageDrain, "tasks", Drain)
+	shutdown.Register(shutdown.StageForce, "tasks", Interrupt)
+	shutdown.Register(shutdown.StageFlush, "results", func(ctx context.Context) error {
+		return shutdown.Wait(ctx, results.Close)
+	})
+	shutdown.Register(shutdown.StageFlush, "pebbledb", func(ctx context.Context) error {
+		return pebbledb.PebbleStore.Flush()
+	})
+
+	targets := map[string]string{"fast": "a.example.com", "slow": "b.example.com"}
+	var wg sync.WaitGroup
+	for id, target := range targets {
+		if !runningTasks.begin(id, 0, len(targets)) {
+			t.Fatalf("task %v not started", id)
+		}
+		contextmanager.GlobalContextManagers.AddContext(id)
+		if err := pebbledb.PebbleStore.Put([]byte(id+":"+target), []byte("")); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(op options.TaskOptions) {
+			defer wg.Done()
+			defer runningTasks.end(op.ID)
+			runTarget(op)
+		}(options.TaskOptions{ID: id, Target: target})
+	}
+	for range targets {
+		<-modules.started
+	}
+
+	code := shutdown.Shutdown("test", 1500*time.Millisecond)
+	wg.Wait()
+	if code != shutdown.ExitForced {
+		t.Fatalf("exit status: got %v, want %v", code, shutdown.ExitForced)
+	}
+
+	// 中断前产生的结果在退出时写入，没有等待定时写入
+	want := []string{"a.example.com/crawler", "a.example.com/httpx", "b.example.com/dirscan", "b.example.com/httpx"}
+	if got := store.get("UrlScan"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("flushed results: got %v, want %v", got, want)
+	}
+
+	// 重新打开本地数据库，中断的目标重启后继续运行
+	if err := pebbledb.PebbleStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	pebbledb.PebbleStore = openStore(t, dir)
+	defer pebbledb.PebbleStore.Close()
+	if _, err := pebbledb.PebbleStore.Get([]byte("fast:a.example.com")); !errors.Is(err, pebble.ErrNotFound) {
+		t.Fatalf("checkpoint of the finished target: got %v, want not found", err)
+	}
+	if _, err := pebbledb.PebbleStore.Get([]byte("slow:b.example.com")); err != nil {
+		t.Fatalf("checkpoint of the interrupted target lost: %v", err)
+	}
+	if runningTasks.count() != 0 {
+		t.Fatalf("%v tasks still running after shutdown", runningTasks.count())
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/bigcache"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
@@ -20,16 +21,31 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pebbledb"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/report"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/runner"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/scheduler"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/shutdown"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/stream"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/workqueue"
 	"github.com/Autumn-27/ScopeSentry-Scan/modules/passivescan"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	goRedis "github.com/redis/go-redis/v9"
 	"os"
+	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	// idleClaimInterval 没有可领取的目标但其他节点还在运行时，重新检查的间隔
+	idleClaimInterval = 5 * time.Second
+	// legacyPollInterval 服务端通过流下发任务后，旧列表的轮询间隔
+	legacyPollInterval = 30 * time.Second
+)
+
 func GetTask() {
 	// 运行本地缓存的任务
 	//RunPebbledbTask()
@@ -44,6 +60,11 @@ func RunPebbledbTask(value []byte) {
 	err := utils.Tools.JSONToStruct(value, &runnerOption)
 	// 任务增加全局上下文
 	contextmanager.GlobalContextManagers.AddContext(runnerOption.ID)
+	// 节点重启前任务处于暂停状态时保持暂停
+	handler.TaskHandle.RestorePause(runnerOption.ID)
+	// 时间预算使用第一次开始运行时的截止时间
+	applyTimeBudget(runnerOption)
+	applyModuleBudget(&runnerOption)
 	// 设置为本地获取的任务
 	runnerOption.IsRestart = true
 	if err != nil {
@@ -72,186 +93,380 @@ func RunPebbledbTask(value []byte) {
 	//}
 }
 
-// RunRedisTask 从redis中获取任务
+// RunRedisTask 从redis中获取任务，同时运行多个任务，优先运行优先级高的任务
+// 任务通过节点的任务流推送，兼容旧版本服务端，继续轮询 NodeTask:<node> 列表，收到流消息后降低轮询频率
 func RunRedisTask() {
-	ticker := time.Tick(3 * time.Second)
+	// 节点退出时不再接收新的任务，未确认的任务重启后重新投递
+	ctx, cancel := shutdown.WithDrain(context.Background())
+	defer cancel()
+	consumer := &stream.Consumer{
+		Backend: redis.RedisClient.Client(),
+		Stream:  stream.TaskStream(global.AppConfig.NodeName),
+		Group:   stream.Group,
+		Name:    global.AppConfig.NodeName,
+		Handle:  inbox.receive,
+	}
+	inbox.mu.Lock()
+	inbox.consumer = consumer
+	inbox.mu.Unlock()
+	go func() {
+		if err := consumer.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.SlogErrorLocal(fmt.Sprintf("task stream error: %v", err))
+		}
+	}()
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	lastPoll := time.Time{}
 	for {
-		<-ticker
-		TaskNodeName := "NodeTask:" + global.AppConfig.NodeName
-		exists, err := redis.RedisClient.Exists(context.Background(), TaskNodeName)
+		var listed []pendingTask
+		select {
+		case <-ctx.Done():
+			return
+		case <-inbox.wake:
+		case <-ticker.C:
+			if !consumer.Received() || time.Since(lastPoll) >= legacyPollInterval {
+				lastPoll = time.Now()
+				listed = listTasks()
+			}
+		}
+		pending := inbox.pending()
+		for _, p := range listed {
+			if !containsTask(pending, p.option.ID) {
+				pending = append(pending, p)
+			}
+		}
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].option.Priority > pending[j].option.Priority
+		})
+		for _, p := range pending {
+			if runningTasks.has(p.option.ID) {
+				inbox.started(p.option.ID)
+				continue
+			}
+			if !startTask(p.option) {
+				break
+			}
+			inbox.started(p.option.ID)
+			logger.SlogInfo(fmt.Sprintf("Get a new task: %v", p.info))
+			go runTask(p.info, p.option)
+		}
+	}
+}
 
+// listTasks 读取旧版本服务端写入 NodeTask:<node> 列表中的任务，任务运行结束时从列表中移除
+func listTasks() []pendingTask {
+	TaskNodeName := "NodeTask:" + global.AppConfig.NodeName
+	values, err := redis.RedisClient.LRange(context.Background(), TaskNodeName, 0, -1)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("GetTask Error: %v", err))
+		return nil
+	}
+	var pending []pendingTask
+	for _, taskInfo := range values {
+		var runnerOption options.TaskOptions
+		err = json.Unmarshal([]byte(taskInfo), &runnerOption)
 		if err != nil {
-			logger.SlogError(fmt.Sprintf("GetTask Error: %v", err))
+			logger.SlogError(fmt.Sprintf("Task parse error: %s", err))
+			// 无法解析的任务直接移除，防止阻塞后边的任务
+			_ = redis.RedisClient.LRem(context.Background(), TaskNodeName, 1, taskInfo)
 			continue
 		}
-		if exists {
-			var wg sync.WaitGroup
+		if runningTasks.has(runnerOption.ID) {
+			continue
+		}
+		pending = append(pending, pendingTask{info: taskInfo, option: runnerOption})
+	}
+	return pending
+}
 
-			taskInfo, err := redis.RedisClient.GetFirstFromList(context.Background(), TaskNodeName)
-			if err != nil {
-				logger.SlogError(fmt.Sprintf("GetTask info error: %v", err))
-				continue
-			}
+func containsTask(pending []pendingTask, id string) bool {
+	for _, p := range pending {
+		if p.option.ID == id {
+			return true
+		}
+	}
+	return false
+}
 
-			logger.SlogInfo(fmt.Sprintf("Get a new task: %v", taskInfo))
-			var runnerOption options.TaskOptions
-			err = json.Unmarshal([]byte(taskInfo), &runnerOption)
-			if err != nil {
-				logger.SlogError(fmt.Sprintf("Task parse error: %s", err))
-				continue
-			}
-			// 运行一些任务初始化的方法
-			InitTaskOption(runnerOption)
-			global.TaskName = runnerOption.TaskName
+// lifecycle 任务登记和初始化与最后一个任务结束后的清理互斥，防止清理掉新任务刚初始化的资源
+var lifecycle sync.Mutex
 
-			taskKey := fmt.Sprintf("task:%v", runnerOption.ID)
+// startTask 登记任务并初始化任务使用的资源，返回是否可以开始
+func startTask(runnerOption options.TaskOptions) bool {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	if !runningTasks.begin(runnerOption.ID, runnerOption.Priority, maxTaskCount()) {
+		return false
+	}
+	InitTaskOption(runnerOption)
+	return true
+}
 
-			logger.SlogInfo(fmt.Sprintf("Task begin: %v %v", runnerOption.ID, runnerOption.TaskName))
-			if runnerOption.Type == "page_monitoring" {
-				// 运行页面监控程序
-				go func() {
-					for {
-						targets, err := redis.RedisClient.BatchGetAndDelete(context.Background(), "TaskInfo:"+runnerOption.ID, 50)
-						if len(targets) == 0 {
-							break
-						}
-						if err != nil {
-							// 如果 err 不为空，并且不是 redis.Nil 错误，则打印错误信息
-							if !errors.Is(err, goRedis.Nil) {
-								logger.SlogError(fmt.Sprintf("GetRedisTask BatchGetAndDelete error: %v", err))
-								// 如果获取任务出错了 直接退出 防止删除本地任务 重启之后重新获取本地任务开始执行
-								os.Exit(0)
-							}
-							break
-						}
-						runner.PageMonitoringRunner(targets)
-					}
-				}()
-			} else {
-				// 开启被动扫描
-				passiveOptionCopy := runnerOption
-				passivescan.SetPassiveScanChan(&passiveOptionCopy)
+// finishTask 关闭任务使用的资源，最后一个运行的任务结束时清理全局资源
+func finishTask(id string) {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	OptionClose(id)
+	if !runningTasks.end(id) {
+		return
+	}
+	handler.CloseNucleiEngine()
+	// 任务结束重新初始化缓存
+	err := bigcache.Initialize()
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("bigcache Initialize error: %v", err))
+	}
+	// 清除全局变量
+	CleanGlobal()
+	// 清空文件锁
+	utils.Tools.ClearAllLocks()
+}
 
-				cacheRunFlag := false
-				// 如果本地存在该任务 后台运行本地缓存任务，这里主要是在节点崩溃重启后可以继续运行，如果是任务暂停，本地的任务信息会被删除，这里不会运行，不会造成暂停失败
-				value, _ := pebbledb.PebbleStore.Get([]byte(taskKey))
-				if value != nil {
-					cacheRunFlag = true
-					wg.Add(1)
-					go func() {
-						defer wg.Done()
-						RunPebbledbTask(value)
-					}()
-					time.Sleep(5 * time.Second)
-				}
+// runTask 运行单个任务，任务已经由 startTask 登记并初始化
+func runTask(taskInfo string, runnerOption options.TaskOptions) {
+	var wg sync.WaitGroup
+	var err error
+	defer func() {
+		finishTask(runnerOption.ID)
+		// 有等待的任务时立即开始
+		inbox.notify()
+	}()
+	// 周期任务保存到本地，由调度器定时触发
+	if runnerOption.Schedule != "" && !runnerOption.ScheduleRun {
+		scheduler.Register(taskInfo, runnerOption)
+	}
+	// 合并节点配置的模块默认时间预算
+	applyModuleBudget(&runnerOption)
+	// 登记任务的优先级和权重，按份额使用各模块的协程
+	pool.Share.Register(runnerOption.ID, runnerOption.Priority, runnerOption.Weight)
+	defer pool.Share.Unregister(runnerOption.ID)
+	// 页面监控任务不领取目标，不阻塞低优先级任务
+	if runnerOption.Type == "page_monitoring" {
+		pool.Share.SetBusy(runnerOption.ID, false)
+	}
 
-				// 将任务配置写入本地
-				runnerOption.IsRestart = false
-				err = pebbledb.PebbleStore.Put([]byte(taskKey), []byte(taskInfo))
-				if err != nil {
-					logger.SlogError(fmt.Sprintf("PebbleStore.Put Task error: %s", err))
-					continue
-				}
+	taskKey := fmt.Sprintf("task:%v", runnerOption.ID)
 
-				// 任务增加全局上下文
-				contextmanager.GlobalContextManagers.AddContext(runnerOption.ID)
-				// 如果任务是暂停后开始的并且前边没有缓存的本地任务，则先运行本地缓存的目标
-				if runnerOption.IsStart && !cacheRunFlag {
-					runnerOption.IsRestart = false
-					wg.Add(1)
-					go func() {
-						defer wg.Done()
-						logger.SlogInfoLocal(fmt.Sprintf("[stop to start]task start run pebbledb: %v", runnerOption.ID))
-						RunPebbleTarget(runnerOption)
-						logger.SlogInfoLocal(fmt.Sprintf("[stop to start]task end run pebbledb: %v", runnerOption.ID))
-					}()
+	logger.SlogInfo(fmt.Sprintf("Task begin: %v %v", runnerOption.ID, runnerOption.TaskName))
+	if runnerOption.Type == "page_monitoring" {
+		// 运行页面监控程序
+		go func() {
+			for {
+				// 任务暂停时不再获取页面
+				contextmanager.GlobalContextManagers.WaitIfPaused(runnerOption.ID)
+				targets, err := redis.RedisClient.BatchGetAndDelete(context.Background(), "TaskInfo:"+runnerOption.ID, 50)
+				if len(targets) == 0 {
+					break
 				}
-			loop:
-				for {
-					select {
-					case <-contextmanager.GlobalContextManagers.GetContext(runnerOption.ID).Done():
-						break loop
-					default:
-						target, err := redis.RedisClient.PopFromListR(context.Background(), "TaskInfo:"+runnerOption.ID)
-						if err != nil {
-							// 如果 err 不为空，并且不是 redis.Nil 错误，则打印错误信息
-							if !errors.Is(err, goRedis.Nil) {
-								logger.SlogError(fmt.Sprintf("GetRedisTask redis error: %v", err))
-								// 如果获取任务出错了 直接退出 防止删除本地任务 重启之后重新获取本地任务开始执行
-								os.Exit(0)
-							}
-							break loop
-						}
-						optionCopy := runnerOption
-						optionCopy.Target = target
-						taskFunc := func(op options.TaskOptions) func() {
-							return func() {
-								wg.Add(1)
-								defer wg.Done()
-								select {
-								case <-contextmanager.GlobalContextManagers.GetContext(op.ID).Done():
-									// 任务取消直接返回
-									return
-								default:
-									err := runner.Run(op)
-									if err != nil {
-										// 说明该任务取消了，直接返回不进行删除目标
-										return
-									} else {
-										// 目标运行完毕删除目标
-										DeletePebbleTarget(pebbledb.PebbleStore, []byte(op.ID+":"+op.Target))
-									}
-								}
-							}
-						}(optionCopy)
-						// 将任务目标写入本地
-						err = pebbledb.PebbleStore.Put([]byte(fmt.Sprintf("%v:%v", runnerOption.ID, target)), []byte(""))
-						if err != nil {
-							logger.SlogError(fmt.Sprintf("PebbleStore.Put target error: %v", err))
-						}
-						// 提交任务
-						err = pool.PoolManage.SubmitTask("task", taskFunc)
-						if err != nil {
-							logger.SlogError(fmt.Sprintf("task pool error: %v", err))
-							wg.Done()
-						}
-						// 防止单个节点刷新目标太快 导致其他节点没有分配到目标
-						time.Sleep(3 * time.Second)
-						logger.SlogInfoLocal(fmt.Sprintf("task target pool running goroutines: %v", pool.PoolManage.GetModuleRunningGoroutines("task")))
+				if err != nil {
+					// 如果 err 不为空，并且不是 redis.Nil 错误，则打印错误信息
+					if !errors.Is(err, goRedis.Nil) {
+						logger.SlogError(fmt.Sprintf("GetRedisTask BatchGetAndDelete error: %v", err))
+						// 如果获取任务出错了 直接退出 防止删除本地任务 重启之后重新获取本地任务开始执行
+						os.Exit(0)
 					}
+					break
 				}
-				time.Sleep(3 * time.Second)
-				wg.Wait()
-				passivescan.PassiveScanChanDone(runnerOption.ID)
-				passivescan.PassiveScanWgMap[runnerOption.ID].Wait()
-				// 删除任务上下文
-				contextmanager.GlobalContextManagers.DeleteContext(runnerOption.ID)
+				runner.PageMonitoringRunner(targets)
+			}
+		}()
+	} else {
+		// 开启被动扫描
+		passiveOptionCopy := runnerOption
+		passivescan.SetPassiveScanChan(&passiveOptionCopy)
+
+		// 任务目标的租约队列，本地缓存的目标也需要重新获取租约
+		queue := workqueue.New(runnerOption.ID)
+		cacheRunFlag := false
+		// 如果本地存在该任务 后台运行本地缓存任务，这里主要是在节点崩溃重启后可以继续运行，如果是任务暂停，本地的任务信息会被删除，这里不会运行，不会造成暂停失败
+		value, _ := pebbledb.PebbleStore.Get([]byte(taskKey))
+		// 周期任务的新一次运行，清除上一次运行留在本地的去重缓存，redis中的记录由触发的节点清除
+		if value == nil && runnerOption.ScheduleRun {
+			if n := results.Duplicate.ResetTaskLocal(runnerOption.ID); n != 0 {
+				logger.SlogInfoLocal(fmt.Sprintf("task %v schedule run, %v local duplicate records cleared", runnerOption.ID, n))
+			}
+		}
+		if value != nil {
+			cacheRunFlag = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				RunPebbledbTask(value)
+			}()
+			time.Sleep(5 * time.Second)
+		}
+
+		// 将任务配置写入本地
+		runnerOption.IsRestart = false
+		err = pebbledb.PebbleStore.Put([]byte(taskKey), []byte(taskInfo))
+		if err != nil {
+			logger.SlogError(fmt.Sprintf("PebbleStore.Put Task error: %s", err))
+			return
+		}
+
+		// 任务增加全局上下文
+		contextmanager.GlobalContextManagers.AddContext(runnerOption.ID)
+		handler.TaskHandle.RestorePause(runnerOption.ID)
+		applyTimeBudget(runnerOption)
+		// 如果任务是暂停后开始的并且前边没有缓存的本地任务，则先运行本地缓存的目标
+		if runnerOption.IsStart && !cacheRunFlag {
+			runnerOption.IsRestart = false
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				logger.SlogInfoLocal(fmt.Sprintf("[stop to start]task start run pebbledb: %v", runnerOption.ID))
+				RunPebbleTarget(runnerOption)
+				logger.SlogInfoLocal(fmt.Sprintf("[stop to start]task end run pebbledb: %v", runnerOption.ID))
+			}()
+		}
+		// 每个目标占用任务份额中的一个协程，领取的目标持有租约直到运行完毕
+		taskCtx := contextmanager.GlobalContextManagers.GetContext(runnerOption.ID)
+		// 节点退出时停止领取目标，已领取的目标继续运行
+		claimCtx, claimCancel := shutdown.WithDrain(taskCtx)
+		defer claimCancel()
+	loop:
+		for {
+			// 任务暂停时不再领取目标，已领取的目标保持租约
+			if !contextmanager.GlobalContextManagers.WaitIfPaused(runnerOption.ID) {
+				break loop
+			}
+			// 有更高优先级的任务正在领取目标时，在目标边界处让出
+			if pool.Share.WaitTurn(claimCtx, runnerOption.ID) != nil {
+				break loop
+			}
+			if pool.Share.Acquire(claimCtx, runnerOption.ID, "task") != nil {
+				break loop
 			}
-			logger.SlogInfo(fmt.Sprintf("Task end: %v - %v", runnerOption.ID, runnerOption.TaskName))
-			handler.CloseNucleiEngine()
-			// 目标运行完毕 删除任务信息
-			// 删除本地缓存任务信息
-			err = pebbledb.PebbleStore.Delete([]byte(taskKey))
+			item, ok, err := queue.Claim(claimCtx)
 			if err != nil {
-				logger.SlogErrorLocal(fmt.Sprintf("PebbleStore Delete %v error: %v", taskKey, err))
+				pool.Share.Release(runnerOption.ID, "task")
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					break loop
+				}
+				logger.SlogError(fmt.Sprintf("GetRedisTask redis error: %v", err))
+				// 如果获取任务出错了 直接退出 防止删除本地任务 重启之后重新获取本地任务开始执行
+				os.Exit(0)
+			}
+			if !ok {
+				pool.Share.Release(runnerOption.ID, "task")
+				pool.Share.SetBusy(runnerOption.ID, false)
+				active, err := queue.Active(claimCtx)
+				if err != nil && claimCtx.Err() == nil {
+					logger.SlogError(fmt.Sprintf("GetRedisTask check active error: %v", err))
+				}
+				if !active {
+					break loop
+				}
+				// 其他节点还有未完成的目标，等待租约过期或者有可以窃取的子任务
+				select {
+				case <-claimCtx.Done():
+					break loop
+				case <-time.After(idleClaimInterval):
+				}
+				continue
+			}
+			pool.Share.SetBusy(runnerOption.ID, true)
+			optionCopy := runnerOption
+			if item.Kind == "" {
+				optionCopy.Target = item.Value
+				// 将任务目标写入本地
+				err = pebbledb.PebbleStore.Put([]byte(fmt.Sprintf("%v:%v", runnerOption.ID, item.Value)), []byte(""))
+				if err != nil {
+					logger.SlogError(fmt.Sprintf("PebbleStore.Put target error: %v", err))
+				}
+			} else {
+				// 窃取的子任务，从对应的模块开始运行
+				var sub types.SubdomainResult
+				_ = json.Unmarshal([]byte(item.Value), &sub)
+				optionCopy.Type = item.Kind
+				optionCopy.Target = sub.Host
+				optionCopy.SubWork = item.Value
 			}
-			// 任务结束重新初始化缓存
-			err = bigcache.Initialize()
+			taskFunc := func(op options.TaskOptions, item workqueue.Item) func() {
+				return func() {
+					defer wg.Done()
+					defer func() {
+						queue.Done(item)
+						pool.Share.Release(op.ID, "task")
+					}()
+					runTarget(op)
+				}
+			}(optionCopy, item)
+			// 提交任务
+			wg.Add(1)
+			err = pool.PoolManage.SubmitTask("task", taskFunc)
 			if err != nil {
-				logger.SlogErrorLocal(fmt.Sprintf("bigcache Initialize error: %v", err))
+				logger.SlogError(fmt.Sprintf("task pool error: %v", err))
+				queue.Done(item)
+				pool.Share.Release(runnerOption.ID, "task")
+				wg.Done()
 			}
-			// 弹出任务信息
-			_ = handler.TaskHandle.PopTaskId(runnerOption.ID)
-			// 任务结束运行关闭方法
-			OptionClose()
-			// 清除全局变量
-			CleanGlobal()
-			// 清空文件锁
-			utils.Tools.ClearAllLocks()
-			// 运行任务结束相关的方法
-			logger.SlogInfo(fmt.Sprintf("Task clean end: %v", runnerOption.ID))
+			logger.SlogInfoLocal(fmt.Sprintf("task target pool running goroutines: %v", pool.PoolManage.GetModuleRunningGoroutines("task")))
+		}
+		wg.Wait()
+		// 节点退出时保留任务信息和本地检查点，重启后继续运行
+		if shutdown.IsDraining() {
+			queue.Close()
+			passivescan.PassiveScanChanDone(runnerOption.ID)
+			passivescan.PassiveScanWgMap[runnerOption.ID].Wait()
+			logger.SlogInfo(fmt.Sprintf("Task interrupted by shutdown: %v - %v", runnerOption.ID, runnerOption.TaskName))
+			return
+		}
+		// 时间预算用尽，剩余的目标记录为跳过
+		if contextmanager.BudgetExhausted(taskCtx) {
+			skipRemainingTargets(runnerOption, queue)
 		}
+		_ = redis.RedisClient.Del(context.Background(), handler.DeadlineKey(runnerOption.ID))
+		_ = redis.RedisClient.Del(context.Background(), handler.PausedBudgetKey(runnerOption.ID))
+		queue.Close()
+		passivescan.PassiveScanChanDone(runnerOption.ID)
+		passivescan.PassiveScanWgMap[runnerOption.ID].Wait()
+		// 删除任务上下文
+		contextmanager.GlobalContextManagers.DeleteContext(runnerOption.ID)
+		// 删除任务认证会话
+		authmanager.GlobalAuthManager.DeleteTask(runnerOption.ID)
+		// 生成任务报告
+		generateReport(runnerOption, queue)
+	}
+	logger.SlogInfo(fmt.Sprintf("Task end: %v - %v", runnerOption.ID, runnerOption.TaskName))
+	// 目标运行完毕 删除任务信息
+	// 删除本地缓存任务信息
+	err = pebbledb.PebbleStore.Delete([]byte(taskKey))
+	if err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("PebbleStore Delete %v error: %v", taskKey, err))
+	}
+	_ = pebbledb.PebbleStore.Delete([]byte("pause:" + runnerOption.ID))
+	// 弹出任务信息
+	_ = handler.TaskHandle.PopTaskId(runnerOption.ID)
+	logger.SlogInfo(fmt.Sprintf("Task clean end: %v", runnerOption.ID))
+}
+
+// generateReport 任务在所有节点上结束后生成报告
+// 先写入本节点缓存的结果，任务没有未完成的工作时由最先获取到报告锁的节点生成，其他节点跳过
+func generateReport(op options.TaskOptions, queue *workqueue.Queue) {
+	if len(op.Report) == 0 {
+		return
+	}
+	results.Flush()
+	ctx := context.Background()
+	active, err := queue.Active(ctx)
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("task %v report check task state error: %v", op.ID, err))
+		return
+	}
+	if active {
+		logger.SlogInfoLocal(fmt.Sprintf("task %v is still running on other nodes, report is generated by the last node", op.ID))
+		return
+	}
+	ok, err := redis.RedisClient.Client().SetNX(ctx, "TaskReport:"+op.ID, global.AppConfig.NodeName, 24*time.Hour).Result()
+	if err != nil {
+		logger.SlogError(fmt.Sprintf("task %v report lock error: %v", op.ID, err))
+		return
+	}
+	if !ok {
+		return
 	}
+	report.GenerateTaskReport(op.ID, op.TaskName, op.Report)
 }
 
 func CleanGlobal() {
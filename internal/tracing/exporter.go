@@ -0,0 +1,294 @@
+// tracing-------------------------------------
+// @file      : exporter.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 15:40
+// -------------------------------------------
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanRecord 本地文件中每行记录的span
+type SpanRecord struct {
+	TraceID    string            `json:"traceId"`
+	SpanID     string            `json:"spanId"`
+	ParentID   string            `json:"parentSpanId,omitempty"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Events     []EventRecord     `json:"events,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// EventRecord span中的事件
+type EventRecord struct {
+	Name       string            `json:"name"`
+	Time       time.Time         `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func attrMap(kvs []attribute.KeyValue) map[string]string {
+	if len(kvs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+func toRecord(s sdktrace.ReadOnlySpan) SpanRecord {
+	rec := SpanRecord{
+		TraceID:    s.SpanContext().TraceID().String(),
+		SpanID:     s.SpanContext().SpanID().String(),
+		Name:       s.Name(),
+		Start:      s.StartTime(),
+		End:        s.EndTime(),
+		Attributes: attrMap(s.Attributes()),
+	}
+	if s.Parent().IsValid() {
+		rec.ParentID = s.Parent().SpanID().String()
+	}
+	for _, e := range s.Events() {
+		if e.Name == "exception" {
+			continue
+		}
+		rec.Events = append(rec.Events, EventRecord{Name: e.Name, Time: e.Time, Attributes: attrMap(e.Attributes)})
+	}
+	if s.Status().Code == codes.Error {
+		rec.Error = s.Status().Description
+	}
+	return rec
+}
+
+// fileExporter 以JSON Lines格式写入本地文件，超过大小后轮转保留一个备份
+type fileExporter struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	size    int64
+	file    *os.File
+	writer  *bufio.Writer
+}
+
+func newFileExporter(path string, maxSizeMB int) (*fileExporter, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+	e := &fileExporter{path: path, maxSize: int64(maxSizeMB) * 1024 * 1024}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *fileExporter) open() error {
+	f, err := os.OpenFile(e.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	e.file = f
+	e.size = info.Size()
+	e.writer = bufio.NewWriter(f)
+	return nil
+}
+
+func (e *fileExporter) rotate() error {
+	e.writer.Flush()
+	e.file.Close()
+	if err := os.Rename(e.path, e.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return e.open()
+}
+
+func (e *fileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	for _, s := range spans {
+		line, err := json.Marshal(toRecord(s))
+		if err != nil {
+			continue
+		}
+		if e.size+int64(len(line))+1 > e.maxSize && e.size > 0 {
+			if err := e.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := e.writer.Write(append(line, '\n'))
+		e.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return e.writer.Flush()
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	e.writer.Flush()
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+// otlpExporter 通过 OTLP/HTTP JSON 发送到 collector
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	return &otlpExporter{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+func otlpAttrs(kvs []attribute.KeyValue) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		out = append(out, otlpKeyValue{Key: string(kv.Key), Value: otlpValue{StringValue: kv.Value.Emit()}})
+	}
+	return out
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	var resAttrs []otlpKeyValue
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		if resAttrs == nil && s.Resource() != nil {
+			resAttrs = otlpAttrs(s.Resource().Attributes())
+		}
+		span := otlpSpan{
+			TraceID:           s.SpanContext().TraceID().String(),
+			SpanID:            s.SpanContext().SpanID().String(),
+			Name:              s.Name(),
+			Kind:              int(s.SpanKind()),
+			StartTimeUnixNano: unixNano(s.StartTime()),
+			EndTimeUnixNano:   unixNano(s.EndTime()),
+			Attributes:        otlpAttrs(s.Attributes()),
+		}
+		if s.Parent().IsValid() {
+			span.ParentSpanID = s.Parent().SpanID().String()
+		}
+		for _, ev := range s.Events() {
+			span.Events = append(span.Events, otlpEvent{TimeUnixNano: unixNano(ev.Time), Name: ev.Name, Attributes: otlpAttrs(ev.Attributes)})
+		}
+		if s.Status().Code == codes.Error {
+			span.Status = otlpStatus{Code: 2, Message: s.Status().Description}
+		}
+		out = append(out, span)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": resAttrs},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "github.com/Autumn-27/ScopeSentry-Scan"},
+						"spans": out,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp export: %v", resp.Status)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
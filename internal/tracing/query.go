@@ -0,0 +1,171 @@
+// tracing-------------------------------------
+// @file      : query.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 16:20
+// -------------------------------------------
+
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Step 数据在流水线中经过的一步，span 或 span 中的事件
+type Step struct {
+	Time    time.Time
+	TraceID string
+	Target  string
+	Module  string
+	Plugin  string
+	Kind    string // module / plugin / dropped / skipped
+	Reason  string
+	Item    string
+}
+
+// ReadSpans 读取本地追踪文件，包括轮转后的备份
+func ReadSpans(path string) ([]SpanRecord, error) {
+	var spans []SpanRecord
+	found := false
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		spans, err = decodeSpans(f, spans)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", p, err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("trace file %v not found", path)
+	}
+	return spans, nil
+}
+
+func decodeSpans(r io.Reader, spans []SpanRecord) ([]SpanRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec SpanRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// 进程退出时可能写入了不完整的行
+			continue
+		}
+		spans = append(spans, rec)
+	}
+	return spans, scanner.Err()
+}
+
+// Journey 查询数据（域名、host:port、url）在各目标流水线中经过的步骤，按时间排序
+func Journey(spans []SpanRecord, item string) []Step {
+	targets := make(map[string]string)
+	for _, s := range spans {
+		if s.Name == "target" {
+			targets[s.TraceID] = s.Attributes["target"]
+		}
+	}
+	var steps []Step
+	for _, s := range spans {
+		if s.Name != "target" && strings.Contains(s.Attributes["item"], item) {
+			kind := "module"
+			if s.Attributes["plugin"] != "" {
+				kind = "plugin"
+			}
+			steps = append(steps, Step{
+				Time:    s.Start,
+				TraceID: s.TraceID,
+				Target:  targets[s.TraceID],
+				Module:  s.Attributes["module"],
+				Plugin:  s.Attributes["plugin"],
+				Kind:    kind,
+				Reason:  s.Error,
+				Item:    s.Attributes["item"],
+			})
+		}
+		for _, e := range s.Events {
+			if e.Name != EventDropped && e.Name != EventSkipped {
+				continue
+			}
+			if !strings.Contains(e.Attributes["item"], item) {
+				continue
+			}
+			steps = append(steps, Step{
+				Time:    e.Time,
+				TraceID: s.TraceID,
+				Target:  targets[s.TraceID],
+				Module:  e.Attributes["module"],
+				Plugin:  s.Attributes["plugin"],
+				Kind:    e.Name,
+				Reason:  e.Attributes["reason"],
+				Item:    e.Attributes["item"],
+			})
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Time.Before(steps[j].Time)
+	})
+	return steps
+}
+
+// Run trace子命令，查询数据在流水线中的经过以及被丢弃的原因，返回进程退出码
+func Run(args []string) int {
+	fs := flag.NewFlagSet("trace", flag.ContinueOnError)
+	file := fs.String("file", "", "trace file written by the file exporter (default data/traces.jsonl)")
+	item := fs.String("item", "", "domain, host:port or url to look up")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *item == "" {
+		fmt.Fprintln(os.Stderr, "usage: ScopeSentry trace -item <domain|host:port|url> [-file <traces.jsonl>]")
+		return 2
+	}
+	if *file == "" {
+		// 子命令不加载配置，默认路径与程序所在目录相同
+		dir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+		*file = filepath.Join(dir, "data", "traces.jsonl")
+	}
+	spans, err := ReadSpans(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read error: %v\n", err)
+		return 1
+	}
+	steps := Journey(spans, *item)
+	if len(steps) == 0 {
+		fmt.Printf("%v not found in traces, it never reached a module\n", *item)
+		return 0
+	}
+	var last *Step
+	for i, s := range steps {
+		name := s.Module
+		if s.Plugin != "" {
+			name += "/" + s.Plugin
+		}
+		line := fmt.Sprintf("%v  [%v] %-8v %-40v %v", s.Time.Format("2006-01-02 15:04:05.000"), s.Target, s.Kind, name, s.Item)
+		if s.Reason != "" {
+			line += "  reason: " + s.Reason
+		}
+		fmt.Println(line)
+		if s.Kind == EventDropped {
+			last = &steps[i]
+		}
+	}
+	if last != nil {
+		fmt.Printf("\n%v was dropped in %v: %v\n", last.Item, last.Module, last.Reason)
+	}
+	return 0
+}
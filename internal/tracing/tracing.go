@@ -0,0 +1,205 @@
+// tracing-------------------------------------
+// @file      : tracing.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/23 15:10
+// -------------------------------------------
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// 追踪结构
+// target <目标>                        每个目标一个trace，丢弃的数据作为 dropped 事件记录在这里
+// ├── <模块>                           模块处理的每条数据，属性 item 为数据的标识（域名、host:port、url）
+// │   └── <模块>/<插件>                  插件执行，返回错误时状态为 error
+// 模块之间通过 TaskOptions.TraceCtx 共享目标的追踪上下文
+
+const (
+	// EventDropped 数据在模块中被丢弃，不会发送到后续模块
+	EventDropped = "dropped"
+	// EventSkipped 时间预算等原因跳过了部分处理
+	EventSkipped = "skipped"
+)
+
+var (
+	tracer   trace.Tracer = noop.NewTracerProvider().Tracer("")
+	provider *sdktrace.TracerProvider
+	enabled  bool
+)
+
+// Initialize 根据配置开启追踪，导出到本地文件或OTLP collector，没有配置导出方式时不记录
+func Initialize(cfg global.TracingConfig) error {
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "":
+		return nil
+	case "file":
+		path := cfg.Path
+		if path == "" {
+			path = filepath.Join(global.AbsolutePath, "data", "traces.jsonl")
+		}
+		exp, err := newFileExporter(path, cfg.MaxSize)
+		if err != nil {
+			return err
+		}
+		exporter = exp
+	case "otlp":
+		if cfg.Endpoint == "" {
+			return fmt.Errorf("tracing endpoint is empty")
+		}
+		exporter = newOTLPExporter(cfg.Endpoint)
+	default:
+		return fmt.Errorf("unknown tracing exporter: %v", cfg.Exporter)
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "ScopeSentry-Scan"),
+		attribute.String("service.version", global.VERSION),
+		attribute.String("node", global.AppConfig.NodeName),
+	)
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	tracer = provider.Tracer("github.com/Autumn-27/ScopeSentry-Scan")
+	enabled = true
+	return nil
+}
+
+// Shutdown 导出剩余的span并关闭
+func Shutdown() {
+	if provider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = provider.Shutdown(ctx)
+}
+
+// StartTarget 开始记录目标的追踪，返回带有目标span的上下文
+func StartTarget(taskId string, taskName string, target string, typ string) (context.Context, trace.Span) {
+	return tracer.Start(context.Background(), "target",
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			attribute.String("task.id", taskId),
+			attribute.String("task.name", taskName),
+			attribute.String("target", target),
+			attribute.String("task.type", typ),
+		))
+}
+
+// StartItem 模块开始处理一条数据，ctx 提供取消和超时，parent 为目标的追踪上下文
+func StartItem(ctx context.Context, parent context.Context, module string, data interface{}) (context.Context, trace.Span) {
+	if !enabled || parent == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	ctx = trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(parent))
+	return tracer.Start(ctx, module, trace.WithAttributes(
+		attribute.String("module", module),
+		attribute.String("item", Item(data)),
+	))
+}
+
+// StartPlugin 开始记录插件的一次执行，ctx 中没有模块的span时不记录
+func StartPlugin(ctx context.Context, module string, plugin string, input interface{}) (context.Context, trace.Span) {
+	if !enabled || ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return tracer.Start(ctx, module+"/"+plugin, trace.WithAttributes(
+		attribute.String("module", module),
+		attribute.String("plugin", plugin),
+		attribute.String("item", Item(input)),
+	))
+}
+
+// End 结束span，err不为空时记录错误
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Drop 记录数据在模块中被丢弃的原因
+func Drop(ctx context.Context, module string, reason string, data interface{}) {
+	event(ctx, EventDropped, module, reason, data)
+}
+
+// Skip 记录跳过的处理
+func Skip(ctx context.Context, module string, reason string, data interface{}) {
+	event(ctx, EventSkipped, module, reason, data)
+}
+
+func event(ctx context.Context, name string, module string, reason string, data interface{}) {
+	if !enabled || ctx == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(
+		attribute.String("module", module),
+		attribute.String("reason", reason),
+		attribute.String("item", Item(data)),
+	))
+}
+
+// Item 返回数据的标识，用于在追踪中按域名、地址或url查询
+func Item(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		data = v.Elem().Interface()
+	}
+	switch d := data.(type) {
+	case string:
+		return d
+	case types.SubdomainResult:
+		return d.Host
+	case types.DomainResolve:
+		return d.Domain
+	case types.DomainSkip:
+		return d.Domain
+	case types.PortAlive:
+		return d.Host + ":" + d.Port
+	case types.AssetOther:
+		return d.Host + ":" + d.Port
+	case types.AssetHttp:
+		return d.URL
+	case types.UrlResult:
+		return d.Output
+	case types.CrawlerResult:
+		return d.Url
+	case types.DirResult:
+		return d.Url
+	case types.VulnResult:
+		return d.Url
+	case types.SensitiveResult:
+		return d.Url
+	case []interface{}:
+		return fmt.Sprintf("%d items", len(d))
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
@@ -18,6 +18,28 @@ type Fingerprint struct {
 	ParentCategory string `yaml:"parent_category"`
 	Company        string `yaml:"company"`
 	Rules          []Rule `yaml:"rules"`
+	// Version 版本提取规则，命中指纹后执行
+	Version *VersionExtractor `yaml:"version,omitempty"`
+	// CPE 模板，支持{{version}}及规则中save_as保存的变量，为空时根据company和name自动生成
+	CPE string `yaml:"cpe,omitempty"`
+}
+
+// VersionExtractor 版本提取规则
+// From 不为空时直接使用命中规则中extract保存的变量，否则在Location中使用正则Pattern提取
+type VersionExtractor struct {
+	From     string `yaml:"from,omitempty"`
+	Location string `yaml:"location,omitempty"` // 默认为body
+	Pattern  string `yaml:"pattern,omitempty"`
+	Group    int    `yaml:"group,omitempty"` // 为0且正则中存在分组时取第一个分组
+}
+
+// Product 指纹识别出的产品信息
+type Product struct {
+	Name     string `bson:"name" json:"name"`
+	Version  string `bson:"version" json:"version"`
+	Category string `bson:"category" json:"category"`
+	Company  string `bson:"company" json:"company"`
+	CPE      string `bson:"cpe" json:"cpe"`
 }
 
 type FingerprintYaml struct {
@@ -33,8 +55,8 @@ type Rule struct {
 // Condition 条件定义（支持普通条件和嵌套条件组）
 type Condition struct {
 	// 普通条件字段
-	Location    string `yaml:"location,omitempty"`   // body, header, title, request
-	MatchType   string `yaml:"match_type,omitempty"` // regex, contains, extract, active
+	Location    string `yaml:"location,omitempty"`   // body, header, title, favicon_mmh3, favicon_md5, status_code, cookie, server, cert_subject, cert_issuer, jarm, url_path
+	MatchType   string `yaml:"match_type,omitempty"` // regex, contains, not_contains, equals, not_equals, gt, gte, lt, lte, range, extract, active
 	Pattern     string `yaml:"pattern,omitempty"`
 	Group       int    `yaml:"group,omitempty"`
 	SaveAs      string `yaml:"save_as,omitempty"`
@@ -69,6 +91,12 @@ type ACMatcher struct {
 	TitlePatternMap  map[string]int  // pattern字符串 -> PatternInfo索引
 	HeaderPatternMap map[string]int
 	BodyPatternMap   map[string]int
+	// 其他文本位置（cookie、server、证书等）的AC自动机，key为location
+	ExtraMatchers   map[string]*goahocorasick.Machine
+	ExtraPatterns   map[string][]PatternInfo
+	ExtraPatternMap map[string]map[string][]int // location -> pattern字符串 -> PatternInfo索引列表（同一pattern可能属于多个指纹）
+	// 精确值位置（favicon hash、状态码、jarm）的索引：location -> 值 -> fingerprintID列表
+	ExactPatterns map[string]map[string][]string
 	// Fingerprint ID到Fingerprint的映射（优化内存，避免在PatternInfo中重复存储）
 	FingerprintMap map[string]*Fingerprint // fingerprintID -> Fingerprint
 	// 无法使用AC自动机的fingerprint列表
@@ -51,21 +51,22 @@ type AssetHttp struct {
 	Screenshot       string                 `bson:"screenshot"`
 	FavIconMMH3      string                 `bson:"faviconmmh3" csv:"favicon"`
 	//FaviconPath   string                 `bson:"faviconpath" csv:"favicon_path"`
-	RawHeaders    string   `bson:"rawheaders" csv:"rawheaders"`
-	Jarm          string   `bson:"jarm" csv:"jarm"`
-	Technologies  []string `bson:"technologies" csv:"tech"`
-	StatusCode    int      `bson:"statuscode" csv:"status_code"`
-	ContentLength int      `bson:"contentlength" csv:"content_length"`
-	CDN           bool     `bson:"cdn" csv:"cdn"`
-	Webcheck      bool     `bson:"webcheck" csv:"webcheck"`
-	Project       string   `bson:"project" csv:"project"`
-	IconContent   string   `bson:"iconcontent"`
-	Domain        string   `bson:"domain"`
-	TaskName      []string `bson:"taskName"`
-	WebServer     string   `bson:"webServer"`
-	Service       string   `bson:"service"`
-	RootDomain    string   `bson:"rootDomain"`
-	Tags          []string `bson:"tags"`
+	RawHeaders    string    `bson:"rawheaders" csv:"rawheaders"`
+	Jarm          string    `bson:"jarm" csv:"jarm"`
+	Technologies  []string  `bson:"technologies" csv:"tech"`
+	Products      []Product `bson:"products"`
+	StatusCode    int       `bson:"statuscode" csv:"status_code"`
+	ContentLength int       `bson:"contentlength" csv:"content_length"`
+	CDN           bool      `bson:"cdn" csv:"cdn"`
+	Webcheck      bool      `bson:"webcheck" csv:"webcheck"`
+	Project       string    `bson:"project" csv:"project"`
+	IconContent   string    `bson:"iconcontent"`
+	Domain        string    `bson:"domain"`
+	TaskName      []string  `bson:"taskName"`
+	WebServer     string    `bson:"webServer"`
+	Service       string    `bson:"service"`
+	RootDomain    string    `bson:"rootDomain"`
+	Tags          []string  `bson:"tags"`
 }
 
 type IPAssetTmp struct {
@@ -89,6 +90,9 @@ type Project struct {
 	Target          []string         `bson:"target"`
 	IgnoreList      []string         `bson:"ignoreList"`
 	IgnoreRegexList []*regexp.Regexp `yaml:"ignoreRegexList"`
+	// 敏感信息允许列表，命中的敏感信息自动忽略
+	SensitiveAllowList      []string         `bson:"sensitiveAllowList"`
+	SensitiveAllowRegexList []*regexp.Regexp `yaml:"sensitiveAllowRegexList"`
 }
 
 type AssetOther struct {
@@ -120,6 +124,20 @@ type AssetChangeLog struct {
 	AssetId   string `json:"assetId"`
 	Timestamp string `json:"timestamp" csv:"timestamp"`
 	Change    []ChangeLog
+	Host      string        `json:"host"`
+	Port      string        `json:"port"`
+	Severity  string        `json:"severity"` // 变化事件中最高的等级
+	Events    []ChangeEvent `json:"events"`
+}
+
+// ChangeEvent 资产变化事件，对字段变化进行分类并给出等级
+type ChangeEvent struct {
+	Type        string `json:"type"`
+	Severity    string `json:"severity"` // info low medium high critical
+	FieldName   string `json:"fieldName"`
+	Old         string `json:"old"`
+	New         string `json:"new"`
+	Description string `json:"description"`
 }
 
 type UrlResult struct {
@@ -159,13 +177,14 @@ type CrawlerResult struct {
 	Method     string
 	Body       string
 	Project    string
-	ResBody    string   `bson:"-"`
-	TaskName   string   `bson:"taskName"`
-	ResultId   string   `bson:"resultId"`
-	RootDomain string   `bson:"rootDomain"`
-	Time       string   `json:"time"`
-	Tags       []string `bson:"tags"`
-	Source     string   `json:"source"`
+	ResBody    string            `bson:"-"`
+	TaskName   string            `bson:"taskName"`
+	ResultId   string            `bson:"resultId"`
+	RootDomain string            `bson:"rootDomain"`
+	Time       string            `json:"time"`
+	Tags       []string          `bson:"tags"`
+	Source     string            `json:"source"`
+	Headers    map[string]string `bson:"headers,omitempty"` // 发送请求需要的请求头，例如接口文档中声明的 Content-Type
 }
 
 type PortDict struct {
@@ -223,7 +242,15 @@ type SensitiveResult struct {
 	TaskName   string   `bson:"taskName"`
 	RootDomain string   `bson:"rootDomain"`
 	Tags       []string `bson:"tags"`
-	Status     int      `bson:"status"` // 1表示未处理 2表示处理中 3表示忽略 4表示疑似 5表示确认
+	Status     int      `bson:"status"` // 1表示未处理 2表示处理中 3表示忽略 4表示疑似 5表示确认 6表示复扫未复现已关闭
+	// 跨任务去重信息
+	Fingerprint string `bson:"fingerprint" json:"fingerprint"` // 规则ID+规范化URL
+	FirstSeen   string `bson:"firstSeen" json:"firstSeen"`
+	LastSeen    string `bson:"lastSeen" json:"lastSeen"`
+	Count       int    `bson:"count" json:"count"` // 发现次数
+	// 误报判定
+	Reason string `bson:"reason" json:"reason"` // 自动调整状态的原因
+	Verify bool   `bson:"-" json:"-"`           // 是否在线验证密钥
 }
 
 type VulnResult struct {
@@ -239,8 +266,28 @@ type VulnResult struct {
 	TaskName   string   `bson:"taskName"`
 	RootDomain string   `yaml:"rootDomain"`
 	Tags       []string `bson:"tags"`
-	Status     int      `bson:"status"` // 1表示未处理 2表示处理中 3表示忽略 4表示疑似 5表示确认
+	Status     int      `bson:"status"` // 1表示未处理 2表示处理中 3表示忽略 4表示疑似 5表示确认 6表示复扫未复现已关闭
 	Hash       string   `bson:"hash" json:"hash"`
+	// 跨任务去重信息
+	MatcherName string `bson:"matcherName" json:"matcherName"`
+	Source      string `bson:"source" json:"source"`           // 结果来源插件，复扫关闭时只关闭同一来源的漏洞
+	Host        string `bson:"host" json:"host"`               // 规范化后的host[:port]
+	Fingerprint string `bson:"fingerprint" json:"fingerprint"` // 模板ID+规范化URL+匹配器名称
+	FirstSeen   string `bson:"firstSeen" json:"firstSeen"`
+	LastSeen    string `bson:"lastSeen" json:"lastSeen"`
+	Count       int    `bson:"count" json:"count"` // 发现次数
+}
+
+// FindingClose 复扫结束后关闭本次扫描范围内未复现的结果
+type FindingClose struct {
+	TaskName string   // 执行复扫的任务，只用于日志
+	Source   string   // 漏洞来源插件
+	Hosts    []string // 本次扫描的host，用于漏洞
+	Url      string   // 本次扫描的URL，用于敏感信息
+	Rules    []string // 本次扫描使用的敏感信息规则，只关闭这些规则的结果
+	VulnIds  []string // 本次扫描运行的漏洞模板，只关闭这些模板的结果
+	Keep     []string // 本次扫描发现的指纹，不关闭
+	Before   string   // 扫描开始时间，在此之后出现过的结果不关闭
 }
 
 type VulnerabilityDetail struct {
@@ -288,6 +335,8 @@ type NotificationConfig struct {
 	VulNotification               bool   `bson:"vulNotification"`
 	VulLevel                      string `bson:"vulLevel"`
 	NewAsset                      bool   `bson:"newAsset"`
+	AssetChangeNotification       bool   `bson:"assetChangeNotification"`
+	AssetChangeLevel              string `bson:"assetChangeLevel"` // 达到该等级的资产变化事件才会通知，默认high
 }
 
 type NotificationApi struct {
@@ -296,6 +345,41 @@ type NotificationApi struct {
 	ContentType string `bson:"contentType"`
 	Data        string `bson:"data"`
 	State       bool   `bson:"state"`
+	Name        string `bson:"name"`
+	// Type 通道类型，为空时为自定义接口（*msg*替换），可选 webhook、slack、dingtalk、feishu、wecom、email
+	Type   string `bson:"type"`
+	Secret string `bson:"secret"` // 钉钉、飞书加签密钥
+	// Template Go模板，Templates 按模块覆盖，均为空时使用默认格式
+	Template  string            `bson:"template"`
+	Templates map[string]string `bson:"templates"`
+	// 路由规则，为空表示不限制；未设置等级的消息不受MinSeverity限制
+	Modules     []string `bson:"modules"`
+	Projects    []string `bson:"projects"`
+	MinSeverity string   `bson:"minSeverity"`
+	// Retry 发送失败的重试次数，SuppressWindow 相同消息的抑制时间（秒）
+	Retry          int               `bson:"retry"`
+	SuppressWindow int               `bson:"suppressWindow"`
+	Email          NotificationEmail `bson:"email"`
+}
+
+type NotificationEmail struct {
+	Host     string   `bson:"host"`
+	Port     int      `bson:"port"`
+	Username string   `bson:"username"`
+	Password string   `bson:"password"`
+	From     string   `bson:"from"`
+	To       []string `bson:"to"`
+	SSL      bool     `bson:"ssl"`
+}
+
+// NotificationMessage 结构化通知消息
+type NotificationMessage struct {
+	Module   string            `json:"module"`
+	Project  string            `json:"project,omitempty"`
+	Severity string            `json:"severity,omitempty"`
+	Text     string            `json:"text"` // 单行文本格式，用于默认模板和自定义接口
+	Fields   map[string]string `json:"fields,omitempty"`
+	Key      string            `json:"-"` // 抑制窗口的去重键，为空时使用Text
 }
 
 type PocData struct {
@@ -365,6 +449,31 @@ type PageMonitBody struct {
 	Md5     string   `bson:"md5"`
 }
 
+// PageMonitChange 页面监控的一次变化事件
+type PageMonitChange struct {
+	Url        string         `bson:"url" json:"url"`
+	Md5        string         `bson:"md5" json:"md5"`
+	OldHash    string         `bson:"oldHash" json:"oldHash"`
+	NewHash    string         `bson:"newHash" json:"newHash"`
+	StatusCode []int          `bson:"statusCode" json:"statusCode"` // 变化前后的状态码
+	Similarity float64        `bson:"similarity" json:"similarity"`
+	Diff       string         `bson:"diff" json:"diff"` // 统一格式的差异，过大时截断
+	Added      PageMonitItems `bson:"added" json:"added"`
+	Removed    PageMonitItems `bson:"removed" json:"removed"`
+	Security   bool           `bson:"security" json:"security"` // 是否新增了安全相关的内容
+	Project    string         `bson:"project" json:"project"`
+	RootDomain string         `bson:"rootDomain" json:"rootDomain"`
+	Time       string         `bson:"time" json:"time"`
+}
+
+// PageMonitItems js变化中新增或删除的内容
+type PageMonitItems struct {
+	Endpoints []string `bson:"endpoints" json:"endpoints"`
+	Hosts     []string `bson:"hosts" json:"hosts"`
+	Routes    []string `bson:"routes" json:"routes"`
+	Secrets   []string `bson:"secrets" json:"secrets"`
+}
+
 type BulkUpdateOperation struct {
 	Selector bson.M // 条件选择器数组
 	Update   bson.M // 更新内容数组
@@ -0,0 +1,440 @@
+// workqueue-------------------------------------
+// @file      : workqueue.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/21 15:20
+// -------------------------------------------
+
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/redis"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	goRedis "github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LeaseTTL 租约有效期，节点超过该时间没有续约，目标会被其他节点接管
+	LeaseTTL = 60 * time.Second
+	// heartbeatInterval 续约间隔
+	heartbeatInterval = LeaseTTL / 3
+	// workPrefix 子任务在队列和租约中的前缀 work:<kind>:<value>
+	workPrefix = "work:"
+)
+
+// Redis中的键
+// TaskInfo:<id>              服务端下发的目标列表
+// TaskLease:{<id>}           租约，zset 成员为目标，分数为过期时间(毫秒)
+// TaskLeaseOwner:{<id>}      租约所属节点，hash 目标 -> 节点
+// TaskWork:{<id>}:<node>     节点积压的子任务，本节点从头部取，其他节点从尾部窃取
+// TaskWorkNodes:{<id>}       存在子任务队列的节点
+// 脚本使用的键带有 {<id>} 哈希标签，在 Redis Cluster 中位于同一个槽
+// 目标列表由服务端创建，不在同一个槽中，不在脚本中使用
+var (
+	// 为目标列表尾部的目标加租约，目标已被其他节点持有且租约未过期时返回0
+	claimScript = goRedis.NewScript(`
+local owner = redis.call('HGET', KEYS[2], ARGV[3])
+if owner and owner ~= ARGV[2] then
+	local score = redis.call('ZSCORE', KEYS[1], ARGV[3])
+	if score and tonumber(score) > tonumber(ARGV[4]) then
+		return 0
+	end
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[3])
+redis.call('HSET', KEYS[2], ARGV[3], ARGV[2])
+return 1
+`)
+	// 接管过期的租约
+	expiredScript = goRedis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #expired == 0 then
+	return false
+end
+local item = expired[1]
+redis.call('ZADD', KEYS[1], ARGV[2], item)
+redis.call('HSET', KEYS[2], item, ARGV[3])
+return item
+`)
+	// 从子任务队列中取出并加租约，本节点从头部取，窃取时从尾部取
+	takeScript = goRedis.NewScript(`
+local item = redis.call(ARGV[3], KEYS[1])
+if not item then
+	return false
+end
+redis.call('ZADD', KEYS[2], ARGV[1], item)
+redis.call('HSET', KEYS[3], item, ARGV[2])
+return item
+`)
+	// 重新获取本地缓存目标的租约，已被其他节点接管时返回0
+	acquireScript = goRedis.NewScript(`
+local owner = redis.call('HGET', KEYS[2], ARGV[3])
+if owner and owner ~= ARGV[2] then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[3])
+redis.call('HSET', KEYS[2], ARGV[3], ARGV[2])
+return 1
+`)
+	// 续约本节点持有的租约
+	renewScript = goRedis.NewScript(`
+for i = 3, #ARGV do
+	if redis.call('HGET', KEYS[2], ARGV[i]) == ARGV[2] then
+		redis.call('ZADD', KEYS[1], 'XX', ARGV[1], ARGV[i])
+	end
+end
+return 1
+`)
+	// 完成后释放本节点持有的租约
+	releaseScript = goRedis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[2]) == ARGV[1] then
+	redis.call('ZREM', KEYS[1], ARGV[2])
+	redis.call('HDEL', KEYS[2], ARGV[2])
+end
+return 1
+`)
+)
+
+// Item 领取到的工作项，Kind为空表示服务端下发的目标，否则为其他节点积压的子任务
+type Item struct {
+	Kind  string
+	Value string
+	raw   string
+}
+
+// Backend 队列使用的redis命令，*goRedis.Client 实现了该接口，测试时可以替换为本地实现
+type Backend interface {
+	goRedis.Scripter
+	LIndex(ctx context.Context, key string, index int64) *goRedis.StringCmd
+	LRem(ctx context.Context, key string, count int64, value interface{}) *goRedis.IntCmd
+	LLen(ctx context.Context, key string) *goRedis.IntCmd
+	RPush(ctx context.Context, key string, values ...interface{}) *goRedis.IntCmd
+	ZCard(ctx context.Context, key string) *goRedis.IntCmd
+	SAdd(ctx context.Context, key string, members ...interface{}) *goRedis.IntCmd
+	SRem(ctx context.Context, key string, members ...interface{}) *goRedis.IntCmd
+	SMembers(ctx context.Context, key string) *goRedis.StringSliceCmd
+}
+
+func defaultBackend() Backend {
+	if client := redis.RedisClient.Client(); client != nil {
+		return client
+	}
+	return nil
+}
+
+// Queue 任务的租约队列，领取的目标在完成前持续续约，节点失联后由其他节点接管
+type Queue struct {
+	taskId  string
+	node    string
+	backend Backend
+	mu      sync.Mutex
+	held    map[string]int
+	stop    chan struct{}
+	once    sync.Once
+}
+
+var (
+	queues   = make(map[string]*Queue)
+	queuesMu sync.Mutex
+)
+
+// New 创建任务的租约队列并开始续约
+func New(taskId string) *Queue {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	if q, ok := queues[taskId]; ok {
+		return q
+	}
+	q := newQueue(taskId, global.AppConfig.NodeName, defaultBackend())
+	queues[taskId] = q
+	go q.heartbeat()
+	return q
+}
+
+func newQueue(taskId string, node string, backend Backend) *Queue {
+	return &Queue{
+		taskId:  taskId,
+		node:    node,
+		backend: backend,
+		held:    make(map[string]int),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Get 获取任务的租约队列，不存在时返回nil
+func Get(taskId string) *Queue {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	return queues[taskId]
+}
+
+// Close 停止续约并移除队列
+func (q *Queue) Close() {
+	q.once.Do(func() {
+		close(q.stop)
+		queuesMu.Lock()
+		delete(queues, q.taskId)
+		queuesMu.Unlock()
+		ctx := context.Background()
+		// 本节点的子任务已经处理完时不再参与窃取
+		if n, err := q.backend.LLen(ctx, q.workKey(q.node)).Result(); err == nil && n == 0 {
+			q.backend.SRem(ctx, q.workNodesKey(), q.node)
+		}
+	})
+}
+
+func (q *Queue) pendingKey() string {
+	return "TaskInfo:" + q.taskId
+}
+
+func (q *Queue) leaseKey() string {
+	return "TaskLease:{" + q.taskId + "}"
+}
+
+func (q *Queue) ownerKey() string {
+	return "TaskLeaseOwner:{" + q.taskId + "}"
+}
+
+func (q *Queue) workKey(node string) string {
+	return fmt.Sprintf("TaskWork:{%v}:%v", q.taskId, node)
+}
+
+func (q *Queue) workNodesKey() string {
+	return "TaskWorkNodes:{" + q.taskId + "}"
+}
+
+func expireAt() int64 {
+	return time.Now().Add(LeaseTTL).UnixMilli()
+}
+
+// Claim 领取一个工作项：先领取目标，其次接管过期的租约，最后窃取其他节点积压的子任务
+// 没有可领取的工作项时返回false
+func (q *Queue) Claim(ctx context.Context) (Item, bool, error) {
+	if q.backend == nil {
+		return Item{}, false, errors.New("redis client nil")
+	}
+	item, ok, err := q.claimTarget(ctx)
+	if err != nil || ok {
+		return item, ok, err
+	}
+	res, err := expiredScript.Run(ctx, q.backend, []string{q.leaseKey(), q.ownerKey()},
+		time.Now().UnixMilli(), expireAt(), q.node).Text()
+	if err == nil {
+		return q.hold(res), true, nil
+	}
+	if !errors.Is(err, goRedis.Nil) {
+		return Item{}, false, err
+	}
+	return q.steal(ctx)
+}
+
+// claimTarget 领取目标列表尾部的目标
+// 先加租约再从目标列表中移除，两步之间节点崩溃时目标仍在列表中，租约过期后其他节点可以重新领取
+// 目标已被其他节点持有时只从列表中移除，继续领取下一个
+func (q *Queue) claimTarget(ctx context.Context) (Item, bool, error) {
+	for {
+		target, err := q.backend.LIndex(ctx, q.pendingKey(), -1).Result()
+		if err != nil {
+			if errors.Is(err, goRedis.Nil) {
+				return Item{}, false, nil
+			}
+			return Item{}, false, err
+		}
+		leased, err := claimScript.Run(ctx, q.backend, []string{q.leaseKey(), q.ownerKey()},
+			expireAt(), q.node, target, time.Now().UnixMilli()).Int()
+		if err != nil {
+			return Item{}, false, err
+		}
+		if err := q.backend.LRem(ctx, q.pendingKey(), -1, target).Err(); err != nil {
+			if leased == 1 {
+				// 目标仍在列表中，释放租约，由下一次领取处理
+				q.release(target)
+			}
+			return Item{}, false, err
+		}
+		if leased == 1 {
+			return q.hold(target), true, nil
+		}
+	}
+}
+
+// steal 从积压最多的节点窃取子任务
+func (q *Queue) steal(ctx context.Context) (Item, bool, error) {
+	nodes, err := q.backend.SMembers(ctx, q.workNodesKey()).Result()
+	if err != nil {
+		return Item{}, false, err
+	}
+	victim, most := "", int64(0)
+	for _, node := range nodes {
+		if node == q.node {
+			continue
+		}
+		n, err := q.backend.LLen(ctx, q.workKey(node)).Result()
+		if err != nil {
+			return Item{}, false, err
+		}
+		if n > most {
+			victim, most = node, n
+		}
+	}
+	if victim == "" {
+		return Item{}, false, nil
+	}
+	res, err := takeScript.Run(ctx, q.backend,
+		[]string{q.workKey(victim), q.leaseKey(), q.ownerKey()},
+		expireAt(), q.node, "RPOP").Text()
+	if err != nil {
+		if errors.Is(err, goRedis.Nil) {
+			return Item{}, false, nil
+		}
+		return Item{}, false, err
+	}
+	logger.SlogInfoLocal(fmt.Sprintf("task %v steal work from %v", q.taskId, victim))
+	return q.hold(res), true, nil
+}
+
+// Acquire 重新获取本地缓存目标的租约，节点重启前领取的目标已被其他节点接管时返回false
+func (q *Queue) Acquire(target string) bool {
+	res, err := acquireScript.Run(context.Background(), q.backend,
+		[]string{q.leaseKey(), q.ownerKey()},
+		expireAt(), q.node, target).Int()
+	if err != nil {
+		// 无法判断时按原来的逻辑继续运行
+		logger.SlogErrorLocal(fmt.Sprintf("task %v acquire %v error: %v", q.taskId, target, err))
+		return true
+	}
+	if res == 0 {
+		return false
+	}
+	q.hold(target)
+	return true
+}
+
+// Done 工作项完成或任务取消，释放租约
+func (q *Queue) Done(item Item) {
+	q.mu.Lock()
+	q.held[item.raw]--
+	if q.held[item.raw] <= 0 {
+		delete(q.held, item.raw)
+	}
+	q.mu.Unlock()
+	q.release(item.raw)
+}
+
+// release 释放本节点持有的租约
+func (q *Queue) release(raw string) {
+	err := releaseScript.Run(context.Background(), q.backend,
+		[]string{q.leaseKey(), q.ownerKey()}, q.node, raw).Err()
+	if err != nil && !errors.Is(err, goRedis.Nil) {
+		logger.SlogErrorLocal(fmt.Sprintf("task %v release %v error: %v", q.taskId, raw, err))
+	}
+}
+
+// DoneTarget 释放目标的租约
+func (q *Queue) DoneTarget(target string) {
+	q.Done(Item{Value: target, raw: target})
+}
+
+// Active 任务是否还有未完成的工作，不需要创建队列，可以在任务没有在本节点运行时使用
+func Active(ctx context.Context, taskId string) (bool, error) {
+	return (&Queue{taskId: taskId, backend: defaultBackend()}).Active(ctx)
+}
+
+// Active 任务是否还有未完成的工作：待领取的目标、未释放的租约或积压的子任务
+func (q *Queue) Active(ctx context.Context) (bool, error) {
+	if q.backend == nil {
+		return false, errors.New("redis client nil")
+	}
+	pending, err := q.backend.LLen(ctx, q.pendingKey()).Result()
+	if err != nil || pending != 0 {
+		return pending != 0, err
+	}
+	leases, err := q.backend.ZCard(ctx, q.leaseKey()).Result()
+	if err != nil || leases != 0 {
+		return leases != 0, err
+	}
+	nodes, err := q.backend.SMembers(ctx, q.workNodesKey()).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, node := range nodes {
+		n, err := q.backend.LLen(ctx, q.workKey(node)).Result()
+		if err != nil || n != 0 {
+			return n != 0, err
+		}
+	}
+	return false, nil
+}
+
+// Offer 本节点处理不过来的子任务放入队列，其他空闲节点可以窃取
+func (q *Queue) Offer(kind string, value string) error {
+	ctx := context.Background()
+	if err := q.backend.SAdd(ctx, q.workNodesKey(), q.node).Err(); err != nil {
+		return err
+	}
+	return q.backend.RPush(ctx, q.workKey(q.node), workPrefix+kind+":"+value).Err()
+}
+
+// Take 从本节点积压的子任务头部取出一个并加租约，处理完成后调用 Done 释放，节点失联时由其他节点接管
+func (q *Queue) Take() (Item, bool) {
+	res, err := takeScript.Run(context.Background(), q.backend,
+		[]string{q.workKey(q.node), q.leaseKey(), q.ownerKey()},
+		expireAt(), q.node, "LPOP").Text()
+	if err != nil {
+		if !errors.Is(err, goRedis.Nil) {
+			logger.SlogErrorLocal(fmt.Sprintf("task %v take work error: %v", q.taskId, err))
+		}
+		return Item{}, false
+	}
+	return q.hold(res), true
+}
+
+func (q *Queue) hold(raw string) Item {
+	q.mu.Lock()
+	q.held[raw]++
+	q.mu.Unlock()
+	return parseItem(raw)
+}
+
+func parseItem(raw string) Item {
+	item := Item{Value: raw, raw: raw}
+	if strings.HasPrefix(raw, workPrefix) {
+		if kv := strings.SplitN(strings.TrimPrefix(raw, workPrefix), ":", 2); len(kv) == 2 {
+			item.Kind, item.Value = kv[0], kv[1]
+		}
+	}
+	return item
+}
+
+// heartbeat 定时续约本节点持有的租约
+func (q *Queue) heartbeat() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+			q.mu.Lock()
+			args := []interface{}{expireAt(), q.node}
+			for raw := range q.held {
+				args = append(args, raw)
+			}
+			q.mu.Unlock()
+			if len(args) == 2 {
+				continue
+			}
+			err := renewScript.Run(context.Background(), q.backend,
+				[]string{q.leaseKey(), q.ownerKey()}, args...).Err()
+			if err != nil && !errors.Is(err, goRedis.Nil) {
+				logger.SlogErrorLocal(fmt.Sprintf("task %v renew lease error: %v", q.taskId, err))
+			}
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// workqueue-------------------------------------
+// @file      : workqueue_test.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/28 14:30
+// -------------------------------------------
+
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	goRedis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memBackend 内存中的列表、集合、有序集合和哈希，脚本按照 lua 的语义在本地执行，执行期间持有锁，与 redis 中脚本的原子性相同
+type memBackend struct {
+	mu      sync.Mutex
+	lists   map[string][]string
+	sets    map[string]map[string]struct{}
+	zsets   map[string]map[string]float64
+	hashes  map[string]map[string]string
+	scripts map[string]func(keys []string, args []string) (interface{}, error)
+	failRem int // 接下来失败的 LREM 次数
+}
+
+func newMemBackend() *memBackend {
+	b := &memBackend{
+		lists:  make(map[string][]string),
+		sets:   make(map[string]map[string]struct{}),
+		zsets:  make(map[string]map[string]float64),
+		hashes: make(map[string]map[string]string),
+	}
+	b.scripts = map[string]func(keys []string, args []string) (interface{}, error){
+		claimScript.Hash():   b.claim,
+		expiredScript.Hash(): b.expired,
+		takeScript.Hash():    b.take,
+		acquireScript.Hash(): b.acquire,
+		renewScript.Hash():   b.renew,
+		releaseScript.Hash(): b.release,
+	}
+	return b
+}
+
+func (b *memBackend) zset(key string) map[string]float64 {
+	if b.zsets[key] == nil {
+		b.zsets[key] = make(map[string]float64)
+	}
+	return b.zsets[key]
+}
+
+func (b *memBackend) hash(key string) map[string]string {
+	if b.hashes[key] == nil {
+		b.hashes[key] = make(map[string]string)
+	}
+	return b.hashes[key]
+}
+
+func score(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func (b *memBackend) claim(keys []string, args []string) (interface{}, error) {
+	owner, ok := b.hash(keys[1])[args[2]]
+	if ok && owner != args[1] {
+		if s, ok := b.zset(keys[0])[args[2]]; ok && s > score(args[3]) {
+			return int64(0), nil
+		}
+	}
+	b.zset(keys[0])[args[2]] = score(args[0])
+	b.hash(keys[1])[args[2]] = args[1]
+	return int64(1), nil
+}
+
+func (b *memBackend) expired(keys []string, args []string) (interface{}, error) {
+	item, min := "", 0.0
+	for member, s := range b.zset(keys[0]) {
+		if s <= score(args[0]) && (item == "" || s < min) {
+			item, min = member, s
+		}
+	}
+	if item == "" {
+		return nil, goRedis.Nil
+	}
+	b.zset(keys[0])[item] = score(args[1])
+	b.hash(keys[1])[item] = args[2]
+	return item, nil
+}
+
+func (b *memBackend) take(keys []string, args []string) (interface{}, error) {
+	list := b.lists[keys[0]]
+	if len(list) == 0 {
+		return nil, goRedis.Nil
+	}
+	var item string
+	if args[2] == "LPOP" {
+		item, b.lists[keys[0]] = list[0], list[1:]
+	} else {
+		item, b.lists[keys[0]] = list[len(list)-1], list[:len(list)-1]
+	}
+	b.zset(keys[1])[item] = score(args[0])
+	b.hash(keys[2])[item] = args[1]
+	return item, nil
+}
+
+func (b *memBackend) acquire(keys []string, args []string) (interface{}, error) {
+	if owner, ok := b.hash(keys[1])[args[2]]; ok && owner != args[1] {
+		return int64(0), nil
+	}
+	b.zset(keys[0])[args[2]] = score(args[0])
+	b.hash(keys[1])[args[2]] = args[1]
+	return int64(1), nil
+}
+
+func (b *memBackend) renew(keys []string, args []string) (interface{}, error) {
+	for _, item := range args[2:] {
+		if b.hash(keys[1])[item] == args[1] {
+			if _, ok := b.zset(keys[0])[item]; ok {
+				b.zset(keys[0])[item] = score(args[0])
+			}
+		}
+	}
+	return int64(1), nil
+}
+
+func (b *memBackend) release(keys []string, args []string) (interface{}, error) {
+	if b.hash(keys[1])[args[1]] == args[0] {
+		delete(b.zset(keys[0]), args[1])
+		delete(b.hash(keys[1]), args[1])
+	}
+	return int64(1), nil
+}
+
+func (b *memBackend) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *goRedis.Cmd {
+	return goRedis.NewCmdResult(nil, errors.New("EVAL not supported"))
+}
+
+func (b *memBackend) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *goRedis.Cmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fn, ok := b.scripts[sha1]
+	if !ok {
+		return goRedis.NewCmdResult(nil, errors.New("NOSCRIPT No matching script"))
+	}
+	argv := make([]string, len(args))
+	for i, a := range args {
+		argv[i] = fmt.Sprint(a)
+	}
+	return goRedis.NewCmdResult(fn(keys, argv))
+}
+
+func (b *memBackend) EvalRO(ctx context.Context, script string, keys []string, args ...interface{}) *goRedis.Cmd {
+	return b.Eval(ctx, script, keys, args...)
+}
+
+func (b *memBackend) EvalShaRO(ctx context.Context, sha1 string, keys []string, args ...interface{}) *goRedis.Cmd {
+	return b.EvalSha(ctx, sha1, keys, args...)
+}
+
+func (b *memBackend) ScriptExists(ctx context.Context, hashes ...string) *goRedis.BoolSliceCmd {
+	res := make([]bool, len(hashes))
+	for i, h := range hashes {
+		_, res[i] = b.scripts[h]
+	}
+	return goRedis.NewBoolSliceResult(res, nil)
+}
+
+func (b *memBackend) ScriptLoad(ctx context.Context, script string) *goRedis.StringCmd {
+	return goRedis.NewStringResult("", errors.New("SCRIPT LOAD not supported"))
+}
+
+func (b *memBackend) LIndex(ctx context.Context, key string, index int64) *goRedis.StringCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := b.lists[key]
+	if index < 0 {
+		index += int64(len(list))
+	}
+	if index < 0 || index >= int64(len(list)) {
+		return goRedis.NewStringResult("", goRedis.Nil)
+	}
+	return goRedis.NewStringResult(list[index], nil)
+}
+
+// LRem 只实现从尾部删除
+func (b *memBackend) LRem(ctx context.Context, key string, count int64, value interface{}) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failRem > 0 {
+		b.failRem--
+		return goRedis.NewIntResult(0, errors.New("connection reset"))
+	}
+	list := b.lists[key]
+	removed := int64(0)
+	for i := len(list) - 1; i >= 0 && removed < -count; i-- {
+		if list[i] == fmt.Sprint(value) {
+			list = append(list[:i], list[i+1:]...)
+			removed++
+		}
+	}
+	b.lists[key] = list
+	return goRedis.NewIntResult(removed, nil)
+}
+
+func (b *memBackend) LLen(ctx context.Context, key string) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return goRedis.NewIntResult(int64(len(b.lists[key])), nil)
+}
+
+func (b *memBackend) RPush(ctx context.Context, key string, values ...interface{}) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, v := range values {
+		b.lists[key] = append(b.lists[key], fmt.Sprint(v))
+	}
+	return goRedis.NewIntResult(int64(len(b.lists[key])), nil)
+}
+
+func (b *memBackend) ZCard(ctx context.Context, key string) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return goRedis.NewIntResult(int64(len(b.zsets[key])), nil)
+}
+
+func (b *memBackend) SAdd(ctx context.Context, key string, members ...interface{}) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sets[key] == nil {
+		b.sets[key] = make(map[string]struct{})
+	}
+	for _, m := range members {
+		b.sets[key][fmt.Sprint(m)] = struct{}{}
+	}
+	return goRedis.NewIntResult(int64(len(members)), nil)
+}
+
+func (b *memBackend) SRem(ctx context.Context, key string, members ...interface{}) *goRedis.IntCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range members {
+		delete(b.sets[key], fmt.Sprint(m))
+	}
+	return goRedis.NewIntResult(int64(len(members)), nil)
+}
+
+func (b *memBackend) SMembers(ctx context.Context, key string) *goRedis.StringSliceCmd {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var members []string
+	for m := range b.sets[key] {
+		members = append(members, m)
+	}
+	return goRedis.NewStringSliceResult(members, nil)
+}
+
+// expire 使节点持有的租约过期，模拟节点失联
+func (b *memBackend) expire(q *Queue, node string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	past := float64(time.Now().Add(-time.Second).UnixMilli())
+	for item, owner := range b.hash(q.ownerKey()) {
+		if owner == node {
+			b.zset(q.leaseKey())[item] = past
+		}
+	}
+}
+
+func (b *memBackend) owner(q *Queue, item string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.hash(q.ownerKey())[item]
+}
+
+func setup(t *testing.T) (*memBackend, *Queue, *Queue) {
+	logger.ZapLog = zap.NewNop()
+	b := newMemBackend()
+	return b, newQueue("t1", "node1", b), newQueue("t1", "node2", b)
+}
+
+func claim(t *testing.T, q *Queue) (Item, bool) {
+	t.Helper()
+	item, ok, err := q.Claim(context.Background())
+	if err != nil {
+		t.Fatalf("%v claim: %v", q.node, err)
+	}
+	return item, ok
+}
+
+func TestClaimLease(t *testing.T) {
+	b, q1, q2 := setup(t)
+	b.RPush(context.Background(), q1.pendingKey(), "a.com", "b.com")
+
+	item, ok := claim(t, q1)
+	if !ok || item.Value != "b.com" || item.Kind != "" {
+		t.Fatalf("node1 claim: got %+v %v, want b.com", item, ok)
+	}
+	item, ok = claim(t, q2)
+	if !ok || item.Value != "a.com" {
+		t.Fatalf("node2 claim: got %+v %v, want a.com", item, ok)
+	}
+	if owner := b.owner(q1, "b.com"); owner != "node1" {
+		t.Fatalf("b.com owner: got %q, want node1", owner)
+	}
+	// 目标已全部领取，租约都未过期
+	if item, ok := claim(t, q1); ok {
+		t.Fatalf("claim with nothing left: got %+v", item)
+	}
+	if active, _ := q1.Active(context.Background()); !active {
+		t.Fatal("leased targets should keep the task active")
+	}
+	q1.DoneTarget("b.com")
+	q2.DoneTarget("a.com")
+	if active, _ := q1.Active(context.Background()); active {
+		t.Fatal("task still active after all targets are done")
+	}
+}
+
+func TestClaimCrashSafe(t *testing.T) {
+	b, q1, q2 := setup(t)
+	b.RPush(context.Background(), q1.pendingKey(), "a.com")
+
+	// node1 加租约后、从目标列表移除前崩溃
+	leased, err := claimScript.Run(context.Background(), b, []string{q1.leaseKey(), q1.ownerKey()},
+		expireAt(), q1.node, "a.com", time.Now().UnixMilli()).Int()
+	if err != nil || leased != 1 {
+		t.Fatalf("lease: %v %v", leased, err)
+	}
+	// 租约有效期内其他节点不会重复领取
+	if item, ok := claim(t, q2); ok {
+		t.Fatalf("claimed a target leased by another node: %+v", item)
+	}
+	// 租约过期后由其他节点接管
+	b.expire(q1, "node1")
+	item, ok := claim(t, q2)
+	if !ok || item.Value != "a.com" {
+		t.Fatalf("reclaim after crash: got %+v %v, want a.com", item, ok)
+	}
+	if owner := b.owner(q1, "a.com"); owner != "node2" {
+		t.Fatalf("a.com owner: got %q, want node2", owner)
+	}
+}
+
+func TestClaimRemoveError(t *testing.T) {
+	b, q1, q2 := setup(t)
+	b.RPush(context.Background(), q1.pendingKey(), "a.com")
+
+	// 从目标列表移除失败时释放租约，目标保留在列表中
+	b.failRem = 1
+	if _, _, err := q1.Claim(context.Background()); err == nil {
+		t.Fatal("claim should fail when the target can not be removed")
+	}
+	if owner := b.owner(q1, "a.com"); owner != "" {
+		t.Fatalf("lease kept after failed claim: owner %q", owner)
+	}
+	item, ok := claim(t, q2)
+	if !ok || item.Value != "a.com" {
+		t.Fatalf("claim after failed claim: got %+v %v, want a.com", item, ok)
+	}
+}
+
+func TestReclaim(t *testing.T) {
+	b, q1, q2 := setup(t)
+	b.RPush(context.Background(), q1.pendingKey(), "a.com")
+	if _, ok := claim(t, q1); !ok {
+		t.Fatal("node1 claim failed")
+	}
+	b.expire(q1, "node1")
+
+	item, ok := claim(t, q2)
+	if !ok || item.Value != "a.com" {
+		t.Fatalf("reclaim expired lease: got %+v %v, want a.com", item, ok)
+	}
+	// 失联节点恢复后不能重新获取、续约或释放已被接管的租约
+	if q1.Acquire("a.com") {
+		t.Fatal("node1 acquired a lease taken over by node2")
+	}
+	q1.DoneTarget("a.com")
+	if owner := b.owner(q1, "a.com"); owner != "node2" {
+		t.Fatalf("a.com owner after node1 done: got %q, want node2", owner)
+	}
+	q2.DoneTarget("a.com")
+	if active, _ := q2.Active(context.Background()); active {
+		t.Fatal("task still active after reclaimed target is done")
+	}
+}
+
+func TestSteal(t *testing.T) {
+	b, q1, q2 := setup(t)
+	for _, v := range []string{"x1", "x2", "x3"} {
+		if err := q1.Offer("dir", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 本节点从头部取
+	item, ok := q1.Take()
+	if !ok || item.Kind != "dir" || item.Value != "x1" {
+		t.Fatalf("take: got %+v %v, want dir x1", item, ok)
+	}
+	// 空闲节点从尾部窃取
+	item, ok = claim(t, q2)
+	if !ok || item.Kind != "dir" || item.Value != "x3" {
+		t.Fatalf("steal: got %+v %v, want dir x3", item, ok)
+	}
+	if owner := b.owner(q1, workPrefix+"dir:x3"); owner != "node2" {
+		t.Fatalf("stolen work owner: got %q, want node2", owner)
+	}
+	// 不从本节点窃取
+	if item, ok, _ := q1.steal(context.Background()); ok {
+		t.Fatalf("node1 stole its own work: %+v", item)
+	}
+	// 窃取的子任务在窃取节点失联后可以被接管
+	b.expire(q2, "node2")
+	item, ok = claim(t, q1)
+	if !ok || item.Value != "x3" {
+		t.Fatalf("reclaim stolen work: got %+v %v, want x3", item, ok)
+	}
+}
+
+func TestClaimConcurrent(t *testing.T) {
+	b := newMemBackend()
+	logger.ZapLog = zap.NewNop()
+	const targets = 200
+	for i := 0; i < targets; i++ {
+		b.RPush(context.Background(), "TaskInfo:t1", fmt.Sprintf("%d.com", i))
+	}
+	var mu sync.Mutex
+	seen := make(map[string]string)
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		q := newQueue("t1", fmt.Sprintf("node%d", n), b)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok, err := q.Claim(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !ok {
+					return
+				}
+				mu.Lock()
+				if other, dup := seen[item.Value]; dup {
+					t.Errorf("%v claimed by %v and %v", item.Value, other, q.node)
+				}
+				seen[item.Value] = q.node
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != targets {
+		t.Fatalf("claimed %v targets, want %v", len(seen), targets)
+	}
+}
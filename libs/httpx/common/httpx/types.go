@@ -5,4 +5,6 @@ type Target struct {
 	Host       string
 	CustomHost string
 	CustomIP   string
+	// Headers 针对单个目标的额外请求头（例如认证信息）
+	Headers map[string]string
 }
@@ -751,7 +751,7 @@ func (r *Runner) Close() {
 	}
 }
 
-func (r *Runner) RunAnalyze(k string, hp *httpx.HTTPX, resultCallback OnResultCallback) {
+func (r *Runner) RunAnalyze(k string, hp *httpx.HTTPX, resultCallback OnResultCallback, headers ...map[string]string) {
 	protocol := r.options.protocol
 	// attempt to parse url as is
 	if u, err := r.parseURL(k); err == nil {
@@ -765,6 +765,9 @@ func (r *Runner) RunAnalyze(k string, hp *httpx.HTTPX, resultCallback OnResultCa
 		protocols = []string{httpx.HTTPS, httpx.HTTP}
 	}
 	for target := range r.targets(hp, k) {
+		if len(headers) > 0 {
+			target.Headers = headers[0]
+		}
 		// if no custom ports specified then test the default ones
 		if len(customport.Ports) == 0 {
 			for _, method := range scanopts.Methods {
@@ -1679,6 +1682,9 @@ retry:
 	}
 
 	hp.SetCustomHeaders(req, hp.CustomHeaders)
+	if len(target.Headers) > 0 {
+		hp.SetCustomHeaders(req, target.Headers)
+	}
 	// We set content-length even if zero to allow net/http to follow 307/308 redirects (it fails on unknown size)
 	if scanopts.RequestBody != "" {
 		req.ContentLength = int64(len(scanopts.RequestBody))
@@ -11,6 +11,7 @@ import (
 
 	"github.com/projectdiscovery/nuclei/v3/pkg/authprovider"
 	"github.com/projectdiscovery/nuclei/v3/pkg/catalog"
+	providerTypes "github.com/projectdiscovery/nuclei/v3/pkg/input/types"
 	"github.com/projectdiscovery/nuclei/v3/pkg/model/types/severity"
 	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 	"github.com/projectdiscovery/nuclei/v3/pkg/progress"
@@ -18,6 +19,7 @@ import (
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/interactsh"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/utils/vardump"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/headless/engine"
+	"github.com/projectdiscovery/nuclei/v3/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v3/pkg/templates/types"
 )
 
@@ -453,6 +455,23 @@ func WithAuthProvider(provider authprovider.AuthProvider) NucleiSDKOptions {
 	}
 }
 
+// WithTemplatesLoadedCallback is called with the templates selected for an execution before scanning starts
+func WithTemplatesLoadedCallback(callback func(tpls []*templates.Template)) NucleiSDKOptions {
+	return func(e *NucleiEngine) error {
+		e.onTemplatesLoadedCallback = callback
+		return nil
+	}
+}
+
+// WithHttpRequests executes templates on the given requests (method, headers and body) instead of urls,
+// used with DASTMode to fuzz requests collected by crawlers or expanded from api documents
+func WithHttpRequests(requests []*providerTypes.RequestResponse) NucleiSDKOptions {
+	return func(e *NucleiEngine) error {
+		e.httpRequests = requests
+		return nil
+	}
+}
+
 // LoadSecretsFromFile allows loading secrets from file
 func LoadSecretsFromFile(files []string, prefetch bool) NucleiSDKOptions {
 	return func(e *NucleiEngine) error {
@@ -12,6 +12,7 @@ import (
 	"github.com/projectdiscovery/nuclei/v3/pkg/loader/workflow"
 	"github.com/projectdiscovery/nuclei/v3/pkg/output"
 	"github.com/projectdiscovery/nuclei/v3/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v3/pkg/protocols/common/contextargs"
 	"github.com/projectdiscovery/nuclei/v3/pkg/types"
 	"github.com/projectdiscovery/ratelimit"
 	errorutil "github.com/projectdiscovery/utils/errors"
@@ -138,6 +139,12 @@ func (e *ThreadSafeNucleiEngine) ExecuteNucleiWithOptsCtx(ctx context.Context, t
 	// cleanup and stop all resources
 	defer closeEphemeralObjects(unsafeOpts)
 
+	// use per-execution auth provider if given
+	if tmpEngine.authprovider != nil {
+		unsafeOpts.executerOpts.AuthProvider = tmpEngine.authprovider
+		unsafeOpts.engine.SetExecuterOptions(unsafeOpts.executerOpts)
+	}
+
 	// load templates
 	workflowLoader, err := workflow.NewLoader(&unsafeOpts.executerOpts)
 	if err != nil {
@@ -152,6 +159,12 @@ func (e *ThreadSafeNucleiEngine) ExecuteNucleiWithOptsCtx(ctx context.Context, t
 	store.Load()
 
 	inputProvider := provider.NewSimpleInputProviderWithUrls(targets...)
+	for _, rr := range tmpEngine.httpRequests {
+		metaInput := contextargs.NewMetaInput()
+		metaInput.Input = rr.URL.String()
+		metaInput.ReqResp = rr
+		inputProvider.Inputs = append(inputProvider.Inputs, metaInput)
+	}
 
 	if len(store.Templates()) == 0 && len(store.Workflows()) == 0 {
 		return ErrNoTemplatesAvailable
@@ -160,6 +173,10 @@ func (e *ThreadSafeNucleiEngine) ExecuteNucleiWithOptsCtx(ctx context.Context, t
 		return ErrNoTargetsAvailable
 	}
 
+	if tmpEngine.onTemplatesLoadedCallback != nil {
+		tmpEngine.onTemplatesLoadedCallback(store.Templates())
+	}
+
 	engine := core.New(tmpEngine.opts)
 	engine.SetExecuterOptions(unsafeOpts.executerOpts)
 
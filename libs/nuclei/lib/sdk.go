@@ -59,6 +59,8 @@ type NucleiEngine struct {
 	disableTemplatesAutoUpgrade bool
 	enableStats                 bool
 	onUpdateAvailableCallback   func(newVersion string)
+	onTemplatesLoadedCallback   func(tpls []*templates.Template)
+	httpRequests                []*providerTypes.RequestResponse
 
 	// ready-status fields
 	templatesLoaded bool
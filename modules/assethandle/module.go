@@ -20,6 +20,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
@@ -58,6 +59,7 @@ func (r *Runner) ModuleRun() error {
 		dataTmp.ResponseBodyHash = utils.Tools.HashXX64String(dataTmp.ResponseBody)
 		flag, id, bsonData := results.Duplicate.AssetInMongodb(dataTmp.Host, dataTmp.Port)
 		if flag {
+			results.Duplicate.MarkHostKnown(r.Option.ID, dataTmp.Host)
 			var oldAssetHttp types.AssetHttp
 			data, _ := bson.Marshal(bsonData)
 			_ = bson.Unmarshal(data, &oldAssetHttp)
@@ -96,6 +98,10 @@ func (r *Runner) ModuleRun() error {
 			}()
 			// 资产没有变化，不进行操作
 		} else {
+			// 已知主机上出现新的端口
+			if results.Duplicate.HostKnownInTask(r.Option.ID, dataTmp.Host) {
+				newPortChange(dataTmp.Host, dataTmp.Port, dataTmp.Time, utils.Results.NewPortEvents(dataTmp.Host, dataTmp.Port, dataTmp.Service, dataTmp.Title, dataTmp.ResponseBody))
+			}
 			// 数据库中不存在该资产，直接插入。
 			go func() {
 				results.Handler.HttpIcon(dataTmp.FavIconMMH3, dataTmp.IconContent)
@@ -168,6 +174,7 @@ func (r *Runner) ModuleRun() error {
 
 					flag, id, bsonData := results.Duplicate.AssetInMongodb(dataTmp.Host, dataTmp.Port)
 					if flag {
+						results.Duplicate.MarkHostKnown(r.Option.ID, dataTmp.Host)
 						// 数据库中存在该资产，对该资产信息进行diff
 						var oldAsset types.AssetOther
 						data, _ := bson.Marshal(bsonData)
@@ -190,6 +197,10 @@ func (r *Runner) ModuleRun() error {
 						go results.Handler.AssetUpdate(id, dataTmp)
 						// 资产没有变化，不进行操作
 					} else {
+						// 已知主机上出现新的端口
+						if results.Duplicate.HostKnownInTask(r.Option.ID, dataTmp.Host) {
+							newPortChange(dataTmp.Host, dataTmp.Port, dataTmp.Time, utils.Results.NewPortEvents(dataTmp.Host, dataTmp.Port, dataTmp.Service, "", ""))
+						}
 						// 数据库中不存在该资产，直接插入。
 						dataTmp.LastScanTime = dataTmp.Time
 						go results.Handler.AssetOtherInsert(&dataTmp)
@@ -229,6 +240,8 @@ func (r *Runner) ModuleRun() error {
 	var end time.Time
 	doneCalled := false
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		//
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
@@ -296,13 +309,24 @@ func (r *Runner) ModuleRun() error {
 					return
 				}
 				if len(r.Option.AssetHandle) != 0 {
+					// 模块处理单条数据的时间预算，用尽后跳过剩余的插件
+					moduleCtx, moduleCancel := contextmanager.GlobalContextManagers.ModuleContext(r.Option.ID, r.Option.ModuleTimeout(r.GetName()))
+					defer moduleCancel()
+					// 追踪此数据在模块中的处理
+					moduleCtx, moduleSpan := tracing.StartItem(moduleCtx, r.Option.TraceCtx, r.GetName(), data)
+					defer moduleSpan.End()
 					// 调用插件
 					for _, pluginId := range r.Option.AssetHandle {
 						//var plgWg sync.WaitGroup
 						var plgWg sync.WaitGroup
 						plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
 						if flag {
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin start execute", plg.GetName()))
+							if contextmanager.BudgetExhausted(moduleCtx) {
+								handler.TaskHandle.ProgressSkip(r.GetName(), r.Option.Target, r.Option.ID, plg.GetName())
+								tracing.Skip(moduleCtx, r.GetName(), "module time budget exhausted: "+plg.GetName(), data)
+								continue
+							}
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug("plugin start execute")
 							plgWg.Add(1)
 							args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
 							if argsFlag {
@@ -313,6 +337,9 @@ func (r *Runner) ModuleRun() error {
 							plg.SetResult(resultChan)
 							plg.SetTaskId(r.Option.ID)
 							plg.SetTaskName(r.Option.TaskName)
+							if cp, ok := plg.(interfaces.ContextPlugin); ok {
+								cp.SetContext(moduleCtx)
+							}
 							var pluginFunc func()
 							switch ty {
 							case "other":
@@ -320,7 +347,7 @@ func (r *Runner) ModuleRun() error {
 									return func() {
 										defer plgWg.Done()
 										select {
-										case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+										case <-moduleCtx.Done():
 											return
 										default:
 											_, err := plg.Execute(data)
@@ -334,7 +361,7 @@ func (r *Runner) ModuleRun() error {
 									return func() {
 										defer plgWg.Done()
 										select {
-										case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+										case <-moduleCtx.Done():
 											return
 										default:
 											_, err := plg.Execute(data)
@@ -348,7 +375,7 @@ func (r *Runner) ModuleRun() error {
 									return func() {
 										defer plgWg.Done()
 										select {
-										case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+										case <-moduleCtx.Done():
 											return
 										default:
 											_, err := plg.Execute(data)
@@ -362,7 +389,7 @@ func (r *Runner) ModuleRun() error {
 									return func() {
 										defer plgWg.Done()
 										select {
-										case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+										case <-moduleCtx.Done():
 											return
 										default:
 											_, err := plg.Execute(data)
@@ -376,7 +403,7 @@ func (r *Runner) ModuleRun() error {
 									return func() {
 										defer plgWg.Done()
 										select {
-										case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+										case <-moduleCtx.Done():
 											return
 										default:
 											_, err := plg.Execute(data)
@@ -386,13 +413,13 @@ func (r *Runner) ModuleRun() error {
 									}
 								}(&mp)
 							}
-							err := pool.PoolManage.SubmitTask(r.GetName(), pluginFunc)
+							err := pool.PoolManage.SubmitTaskFor(r.Option.ID, r.GetName(), pluginFunc)
 							if err != nil {
 								plgWg.Done()
 								logger.SlogError(fmt.Sprintf("task pool error: %v", err))
 							}
 							plgWg.Wait()
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin end execute", plg.GetName()))
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug("plugin end execute")
 						} else {
 							logger.SlogError(fmt.Sprintf("plugin %v not found", pluginId))
 						}
@@ -432,3 +459,18 @@ func (r *Runner) GetInput() chan interface{} {
 func (r *Runner) CloseInput() {
 	close(r.Input)
 }
+
+// newPortChange 新端口没有历史资产记录，按照主机和端口记录到变化时间线中
+func newPortChange(host string, port string, timestamp string, events []types.ChangeEvent) {
+	if timestamp == "" {
+		timestamp = utils.Tools.GetTimeNow()
+	}
+	changeData := types.AssetChangeLog{
+		Timestamp: timestamp,
+		Host:      host,
+		Port:      port,
+		Severity:  utils.Results.MaxSeverity(events),
+		Events:    events,
+	}
+	go results.Handler.AssetChangeLog(&changeData)
+}
@@ -0,0 +1,176 @@
+// tlsaudit-------------------------------------
+// @file      : audit.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 17:20
+// -------------------------------------------
+
+package tlsaudit
+
+import (
+	"fmt"
+	"github.com/projectdiscovery/tlsx/pkg/tlsx/clients"
+	"net"
+	"strings"
+	"time"
+)
+
+// Finding 单条TLS配置问题
+type Finding struct {
+	Id      string
+	Name    string
+	Level   string
+	Matched string
+}
+
+// 已废弃的协议版本及对应的等级
+var deprecatedVersions = map[string]string{
+	"ssl30": "high",
+	"tls10": "medium",
+	"tls11": "medium",
+}
+
+// Audit 根据tlsx的探测结果评估TLS配置
+// expireDays 证书剩余有效天数小于该值时提示即将过期
+func Audit(resp *clients.Response, expireDays int, now time.Time) []Finding {
+	if resp == nil {
+		return nil
+	}
+	var findings []Finding
+	versions := resp.VersionEnum
+	if len(versions) == 0 && resp.Version != "" {
+		versions = []string{resp.Version}
+	}
+	for _, version := range versions {
+		if level, ok := deprecatedVersions[version]; ok {
+			findings = append(findings, Finding{
+				Id:      "tls-deprecated-protocol-" + version,
+				Name:    "Deprecated TLS protocol " + displayVersion(version),
+				Level:   level,
+				Matched: "supported versions: " + joinVersions(versions),
+			})
+		}
+	}
+	var insecure []string
+	var weak []string
+	for _, tlsCipher := range resp.TlsCiphers {
+		for _, c := range tlsCipher.Ciphers.Insecure {
+			insecure = append(insecure, displayVersion(tlsCipher.Version)+" "+c)
+		}
+		for _, c := range tlsCipher.Ciphers.Weak {
+			weak = append(weak, displayVersion(tlsCipher.Version)+" "+c)
+		}
+	}
+	if len(insecure) != 0 {
+		findings = append(findings, Finding{
+			Id:      "tls-insecure-cipher",
+			Name:    "Insecure TLS cipher suites",
+			Level:   "high",
+			Matched: strings.Join(insecure, "\n"),
+		})
+	}
+	if len(weak) != 0 {
+		findings = append(findings, Finding{
+			Id:      "tls-weak-cipher",
+			Name:    "Weak TLS cipher suites",
+			Level:   "low",
+			Matched: strings.Join(weak, "\n"),
+		})
+	}
+	cert := resp.CertificateResponse
+	if cert == nil {
+		return findings
+	}
+	subject := fmt.Sprintf("subject: %v, issuer: %v, not after: %v", cert.SubjectCN, cert.IssuerCN, cert.NotAfter.Format("2006-01-02"))
+	if cert.Expired || (!cert.NotAfter.IsZero() && now.After(cert.NotAfter)) {
+		findings = append(findings, Finding{
+			Id:      "tls-cert-expired",
+			Name:    "TLS certificate expired",
+			Level:   "high",
+			Matched: subject,
+		})
+	} else if !cert.NotAfter.IsZero() && cert.NotAfter.Sub(now) < time.Duration(expireDays)*24*time.Hour {
+		level := "medium"
+		if cert.NotAfter.Sub(now) < 7*24*time.Hour {
+			level = "high"
+		}
+		findings = append(findings, Finding{
+			Id:      "tls-cert-expiring",
+			Name:    fmt.Sprintf("TLS certificate expires in %v days", int(cert.NotAfter.Sub(now).Hours()/24)),
+			Level:   level,
+			Matched: subject,
+		})
+	}
+	if cert.SelfSigned {
+		findings = append(findings, Finding{
+			Id:      "tls-cert-self-signed",
+			Name:    "Self-signed TLS certificate",
+			Level:   "medium",
+			Matched: subject,
+		})
+	} else if cert.Untrusted {
+		findings = append(findings, Finding{
+			Id:      "tls-cert-untrusted",
+			Name:    "Untrusted TLS certificate",
+			Level:   "medium",
+			Matched: subject,
+		})
+	}
+	// 直接通过ip访问时证书域名必然不匹配，不作为问题
+	if cert.MisMatched && net.ParseIP(resp.Host) == nil {
+		findings = append(findings, Finding{
+			Id:      "tls-cert-mismatched",
+			Name:    "TLS certificate hostname mismatch",
+			Level:   "medium",
+			Matched: fmt.Sprintf("host: %v, certificate domains: %v", resp.Host, strings.Join(cert.Domains, ", ")),
+		})
+	}
+	if cert.Revoked {
+		findings = append(findings, Finding{
+			Id:      "tls-cert-revoked",
+			Name:    "Revoked TLS certificate",
+			Level:   "high",
+			Matched: subject,
+		})
+	}
+	return findings
+}
+
+// AuditHSTS 检查https响应头中是否设置了HSTS
+func AuditHSTS(rawHeaders string) *Finding {
+	for _, line := range strings.Split(rawHeaders, "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "strict-transport-security:") {
+			return nil
+		}
+	}
+	return &Finding{
+		Id:      "tls-missing-hsts",
+		Name:    "Missing HTTP Strict Transport Security header",
+		Level:   "low",
+		Matched: "Strict-Transport-Security header not found",
+	}
+}
+
+func displayVersion(version string) string {
+	switch version {
+	case "ssl30":
+		return "SSLv3"
+	case "tls10":
+		return "TLSv1.0"
+	case "tls11":
+		return "TLSv1.1"
+	case "tls12":
+		return "TLSv1.2"
+	case "tls13":
+		return "TLSv1.3"
+	}
+	return version
+}
+
+func joinVersions(versions []string) string {
+	display := make([]string, 0, len(versions))
+	for _, v := range versions {
+		display = append(display, displayVersion(v))
+	}
+	return strings.Join(display, ", ")
+}
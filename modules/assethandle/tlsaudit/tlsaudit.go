@@ -0,0 +1,289 @@
+// tlsaudit-------------------------------------
+// @file      : tlsaudit.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 17:05
+// -------------------------------------------
+
+package tlsaudit
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/tlsx/pkg/tlsx"
+	"github.com/projectdiscovery/tlsx/pkg/tlsx/clients"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Plugin struct {
+	Name      string
+	Module    string
+	Parameter string
+	PluginId  string
+	Result    chan interface{}
+	Custom    interface{}
+	TaskId    string
+	TaskName  string
+}
+
+var (
+	dialer     *fastdialer.Dialer
+	dialerErr  error
+	dialerOnce sync.Once
+)
+
+func NewPlugin() *Plugin {
+	return &Plugin{
+		Name:     "tlsaudit",
+		Module:   "AssetHandle",
+		PluginId: "41641f05557e64ab4fbd1e82b205f3e1",
+	}
+}
+
+func (p *Plugin) SetTaskName(name string) {
+	p.TaskName = name
+}
+
+func (p *Plugin) GetTaskName() string {
+	return p.TaskName
+}
+
+func (p *Plugin) SetTaskId(id string) {
+	p.TaskId = id
+}
+
+func (p *Plugin) GetTaskId() string {
+	return p.TaskId
+}
+
+func (p *Plugin) Log(msg string, tp ...string) {
+	var logTp string
+	if len(tp) > 0 {
+		logTp = tp[0] // 使用传入的参数
+	} else {
+		logTp = "i"
+	}
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
+}
+func (p *Plugin) SetCustom(cu interface{}) {
+	p.Custom = cu
+}
+
+func (p *Plugin) GetCustom() interface{} {
+	return p.Custom
+}
+
+func (p *Plugin) SetPluginId(id string) {
+	p.PluginId = id
+}
+
+func (p *Plugin) GetPluginId() string {
+	return p.PluginId
+}
+
+func (p *Plugin) SetResult(ch chan interface{}) {
+	p.Result = ch
+}
+
+func (p *Plugin) SetName(name string) {
+	p.Name = name
+}
+
+func (p *Plugin) GetName() string {
+	return p.Name
+}
+
+func (p *Plugin) SetModule(module string) {
+	p.Module = module
+}
+
+func (p *Plugin) GetModule() string {
+	return p.Module
+}
+
+func (p *Plugin) Install() error {
+	return nil
+}
+
+func (p *Plugin) Check() error {
+	return nil
+}
+func (p *Plugin) UnInstall() error {
+	return nil
+}
+func (p *Plugin) SetParameter(args string) {
+	p.Parameter = args
+}
+
+func (p *Plugin) GetParameter() string {
+	return p.Parameter
+}
+
+func (p *Plugin) Execute(input interface{}) (interface{}, error) {
+	var host, ip, port, target, rawHeaders string
+	isHttp := false
+	switch data := input.(type) {
+	case *types.AssetHttp:
+		u, err := url.Parse(data.URL)
+		if err != nil || u.Scheme != "https" {
+			return nil, nil
+		}
+		host = u.Hostname()
+		port = u.Port()
+		if port == "" {
+			port = "443"
+		}
+		ip = data.IP
+		target = data.URL
+		rawHeaders = data.RawHeaders
+		isHttp = true
+	case *types.AssetOther:
+		if !data.TLS {
+			return nil, nil
+		}
+		host = data.Host
+		ip = data.IP
+		port = data.Port
+		target = net.JoinHostPort(data.Host, data.Port)
+	default:
+		return nil, nil
+	}
+	if host == "" || port == "" {
+		return nil, nil
+	}
+	// 同一个端口在当前任务中只检测一次
+	if !results.Duplicate.DuplicateLocalCache("duplicates:" + p.TaskId + ":tlsaudit:" + host + ":" + port) {
+		return nil, nil
+	}
+	expireDays := 30
+	timeout := 10
+	cipherFlag := true
+	hstsFlag := true
+	parameter := p.GetParameter()
+	if parameter != "" {
+		args, err := utils.Tools.ParseArgs(parameter, "expire", "timeout", "ciphers", "hsts")
+		if err != nil {
+		} else {
+			for key, value := range args {
+				if value != "" {
+					switch key {
+					case "expire":
+						expireDays, _ = strconv.Atoi(value)
+					case "timeout":
+						timeout, _ = strconv.Atoi(value)
+					case "ciphers":
+						cipherFlag = value != "false"
+					case "hsts":
+						hstsFlag = value != "false"
+					default:
+						continue
+					}
+				}
+			}
+		}
+	}
+	dialerOnce.Do(func() {
+		dialer, dialerErr = fastdialer.NewDialer(fastdialer.DefaultOptions)
+	})
+	if dialerErr != nil {
+		p.Log(fmt.Sprintf("create dialer error: %v", dialerErr), "e")
+		return nil, dialerErr
+	}
+	service, err := tlsx.New(&clients.Options{
+		ScanMode:        "auto",
+		Timeout:         timeout,
+		Retries:         1,
+		Fastdialer:      dialer,
+		TlsVersionsEnum: true,
+		TlsCiphersEnum:  cipherFlag,
+		// 只枚举存在问题的套件，减少连接次数
+		TLsCipherLevel: []string{"weak", "insecure"},
+	})
+	if err != nil {
+		p.Log(fmt.Sprintf("create tlsx service error: %v", err), "e")
+		return nil, err
+	}
+	connectOptions := clients.ConnectOptions{}
+	if net.ParseIP(host) == nil {
+		connectOptions.SNI = host
+	}
+	start := time.Now()
+	scanTime := utils.Tools.GetTimeNow()
+	resp, err := service.ConnectWithOptions(host, ip, port, connectOptions)
+	if err != nil {
+		p.Log(fmt.Sprintf("%v tls connect error: %v", target, err), "w")
+		return nil, nil
+	}
+	findings := Audit(resp, expireDays, time.Now())
+	if isHttp && hstsFlag {
+		if finding := AuditHSTS(rawHeaders); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	var found []string
+	for _, finding := range findings {
+		tmpResult := types.VulnResult{
+			Url:      target,
+			VulnId:   finding.Id,
+			VulName:  finding.Name,
+			Matched:  finding.Matched,
+			Level:    finding.Level,
+			Time:     utils.Tools.GetTimeNow(),
+			Response: summary(resp),
+			Tags:     []string{"tls"},
+			Source:   p.Name,
+		}
+		tmpResult.TaskName = p.TaskName
+		tmpResult.Status = 1
+		tmpResult.Fingerprint = results.VulnFingerprint(tmpResult.VulnId, tmpResult.Url, tmpResult.MatcherName)
+		found = append(found, tmpResult.Fingerprint)
+		go results.Handler.Vulnerability(&tmpResult)
+	}
+	// 关闭该端口上已修复的问题
+	results.Handler.VulnerabilityClose(&types.FindingClose{
+		TaskName: p.TaskName,
+		Source:   p.Name,
+		Hosts:    []string{results.FindingHost(target)},
+		Keep:     found,
+		Before:   scanTime,
+	})
+	p.Log(fmt.Sprintf("%v audit end, %v findings, time: %v", target, len(findings), time.Since(start)))
+	return nil, nil
+}
+
+// summary 将探测结果整理为文本，作为漏洞详情展示
+func summary(resp *clients.Response) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("host: %v:%v\n", resp.Host, resp.Port))
+	sb.WriteString(fmt.Sprintf("negotiated: %v %v\n", displayVersion(resp.Version), resp.Cipher))
+	sb.WriteString(fmt.Sprintf("supported versions: %v\n", joinVersions(resp.VersionEnum)))
+	if cert := resp.CertificateResponse; cert != nil {
+		sb.WriteString(fmt.Sprintf("subject: %v\n", cert.SubjectDN))
+		sb.WriteString(fmt.Sprintf("issuer: %v\n", cert.IssuerDN))
+		sb.WriteString(fmt.Sprintf("domains: %v\n", strings.Join(cert.Domains, ", ")))
+		sb.WriteString(fmt.Sprintf("not before: %v\n", cert.NotBefore.Format("2006-01-02 15:04:05")))
+		sb.WriteString(fmt.Sprintf("not after: %v\n", cert.NotAfter.Format("2006-01-02 15:04:05")))
+		sb.WriteString(fmt.Sprintf("sha256: %v\n", cert.FingerprintHash.SHA256))
+	}
+	return sb.String()
+}
+
+func (p *Plugin) Clone() interfaces.Plugin {
+	return &Plugin{
+		Name:     p.Name,
+		Module:   p.Module,
+		PluginId: p.PluginId,
+		Custom:   p.Custom,
+		TaskId:   p.TaskId,
+	}
+}
@@ -10,9 +10,11 @@ package webfingerprint
 import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"strings"
 )
 
-func AcRun(testTitle, testHeader, testBody string) []*types.Fingerprint {
+func AcRun(asset *types.AssetHttp) []*types.Fingerprint {
+	testTitle, testHeader, testBody := asset.Title, asset.RawHeaders, asset.ResponseBody
 	// 收集命中的指纹（按ID去重）
 	matchedFingerprintsMap := make(map[string]*types.Fingerprint) // key: fingerprintID
 
@@ -75,6 +77,37 @@ func AcRun(testTitle, testHeader, testBody string) []*types.Fingerprint {
 		}
 	}
 
+	// 匹配其他文本位置并收集指纹
+	for location, locationMatcher := range matcher.ExtraMatchers {
+		data := getDataByLocation(location, asset)
+		if data == "" || locationMatcher == nil {
+			continue
+		}
+		for _, term := range locationMatcher.MultiPatternSearch([]rune(data), false) {
+			for _, patternIndex := range matcher.ExtraPatternMap[location][string(term.Word)] {
+				if patternIndex >= 0 && patternIndex < len(matcher.ExtraPatterns[location]) {
+					p := matcher.ExtraPatterns[location][patternIndex]
+					if fp, exists := matcher.FingerprintMap[p.FingerprintID]; exists {
+						matchedFingerprintsMap[p.FingerprintID] = fp
+					}
+				}
+			}
+		}
+	}
+
+	// 精确值位置直接查找索引
+	for location, values := range matcher.ExactPatterns {
+		data := getDataByLocation(location, asset)
+		if data == "" {
+			continue
+		}
+		for _, fingerprintID := range values[strings.ToLower(strings.TrimSpace(data))] {
+			if fp, exists := matcher.FingerprintMap[fingerprintID]; exists {
+				matchedFingerprintsMap[fingerprintID] = fp
+			}
+		}
+	}
+
 	// 转换为切片并返回
 	result := make([]*types.Fingerprint, 0, len(matchedFingerprintsMap))
 	for _, fp := range matchedFingerprintsMap {
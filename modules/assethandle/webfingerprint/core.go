@@ -8,6 +8,8 @@
 package webfingerprint
 
 import (
+	"crypto/md5"
+	"encoding/base64"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"io"
@@ -15,6 +17,7 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -91,20 +94,119 @@ func NewMatchContext() *MatchContext {
 
 // MatchFingerprint 匹配指纹
 func MatchFingerprint(fingerprint *types.Fingerprint, asset *types.AssetHttp) (bool, error) {
+	matched, _, err := matchFingerprint(fingerprint, asset)
+	return matched, err
+}
+
+// MatchProduct 匹配指纹，命中时返回包含版本和CPE的产品信息，未命中返回nil
+func MatchProduct(fingerprint *types.Fingerprint, asset *types.AssetHttp) (*types.Product, error) {
+	matched, ctx, err := matchFingerprint(fingerprint, asset)
+	if err != nil || !matched {
+		return nil, err
+	}
+	version := extractVersion(fingerprint.Version, ctx, asset)
+	return &types.Product{
+		Name:     fingerprint.Name,
+		Version:  version,
+		Category: fingerprint.Category,
+		Company:  fingerprint.Company,
+		CPE:      buildCPE(fingerprint, version, ctx.Variables),
+	}, nil
+}
+
+// matchFingerprint 匹配指纹，返回命中规则的上下文（包含extract保存的变量）
+func matchFingerprint(fingerprint *types.Fingerprint, asset *types.AssetHttp) (bool, *MatchContext, error) {
 	ctx := NewMatchContext()
 
 	// 规则之间是OR关系，任一规则匹配成功即可
 	for _, rule := range fingerprint.Rules {
 		matched, err := evaluateRule(rule, ctx, asset)
 		if err != nil {
-			return false, err
+			return false, nil, err
 		}
 		if matched {
-			return true, nil
+			return true, ctx, nil
 		}
 	}
 
-	return false, nil
+	return false, nil, nil
+}
+
+// extractVersion 根据版本提取规则获取版本号，提取失败返回空字符串
+func extractVersion(extractor *types.VersionExtractor, ctx *MatchContext, asset *types.AssetHttp) string {
+	if extractor == nil {
+		return ""
+	}
+	if extractor.From != "" {
+		return strings.TrimSpace(ctx.Variables[extractor.From])
+	}
+	if extractor.Pattern == "" {
+		return ""
+	}
+	location := extractor.Location
+	if location == "" {
+		location = "body"
+	}
+	data := getDataByLocation(location, asset)
+	if data == "" {
+		return ""
+	}
+	re, err := regexp.Compile(extractor.Pattern)
+	if err != nil {
+		return ""
+	}
+	group := extractor.Group
+	if group == 0 && re.NumSubexp() > 0 {
+		group = 1
+	}
+	matches := re.FindStringSubmatch(data)
+	if len(matches) <= group {
+		return ""
+	}
+	return strings.TrimSpace(matches[group])
+}
+
+// buildCPE 生成CPE 2.3字符串，版本未知时使用*
+func buildCPE(fingerprint *types.Fingerprint, version string, variables map[string]string) string {
+	cpeVersion := cpeComponent(version)
+	if fingerprint.CPE != "" {
+		cpe := replaceVariables(fingerprint.CPE, variables)
+		return strings.ReplaceAll(cpe, "{{version}}", cpeVersion)
+	}
+	product := cpeComponent(fingerprint.Name)
+	if product == "*" {
+		return ""
+	}
+	vendor := cpeComponent(fingerprint.Company)
+	if vendor == "*" {
+		vendor = product
+	}
+	return fmt.Sprintf("cpe:2.3:a:%s:%s:%s:*:*:*:*:*:*:*", vendor, product, cpeVersion)
+}
+
+// cpeComponent 将字符串转换为CPE组件格式
+func cpeComponent(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "*"
+	}
+	var builder strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			builder.WriteRune(r)
+		case r == ' ':
+			builder.WriteRune('_')
+		case r < 0x80:
+			// CPE中的特殊字符需要转义
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		}
+	}
+	if builder.Len() == 0 {
+		return "*"
+	}
+	return builder.String()
 }
 
 // evaluateRule 评估规则
@@ -195,6 +297,15 @@ func evaluateNormalCondition(condition types.Condition, ctx *MatchContext, baseR
 		return matchContains(condition, ctx, baseResponse)
 	case "not_contains":
 		return matchNotContains(condition, ctx, baseResponse)
+	case "equals":
+		return matchEquals(condition, ctx, baseResponse)
+	case "not_equals":
+		result, err := matchEquals(condition, ctx, baseResponse)
+		return !result, err
+	case "gt", "gte", "lt", "lte":
+		return matchCompare(condition, ctx, baseResponse)
+	case "range":
+		return matchRange(condition, ctx, baseResponse)
 	case "extract":
 		return matchExtract(condition, ctx, baseResponse)
 	case "active":
@@ -214,11 +325,74 @@ func getDataByLocation(location string, response *types.AssetHttp) string {
 		return response.RawHeaders
 	case "title":
 		return response.Title
+	case "favicon_mmh3":
+		return response.FavIconMMH3
+	case "favicon_md5":
+		if response.IconContent == "" {
+			return ""
+		}
+		icon, err := base64.StdEncoding.DecodeString(response.IconContent)
+		if err != nil || len(icon) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%x", md5.Sum(icon))
+	case "status_code":
+		if response.StatusCode == 0 {
+			return ""
+		}
+		return strconv.Itoa(response.StatusCode)
+	case "cookie":
+		return headerValues(response.RawHeaders, "set-cookie")
+	case "server":
+		if response.WebServer != "" {
+			return response.WebServer
+		}
+		return headerValues(response.RawHeaders, "server")
+	case "cert_subject":
+		if response.TLSData == nil || response.TLSData.CertificateResponse == nil {
+			return ""
+		}
+		cert := response.TLSData.CertificateResponse
+		return strings.Join(append([]string{cert.SubjectDN}, cert.Domains...), "\n")
+	case "cert_issuer":
+		if response.TLSData == nil || response.TLSData.CertificateResponse == nil {
+			return ""
+		}
+		return response.TLSData.CertificateResponse.IssuerDN
+	case "jarm":
+		if response.Jarm != "" {
+			return response.Jarm
+		}
+		if response.TLSData != nil {
+			return response.TLSData.JarmHash
+		}
+		return ""
+	case "url_path":
+		parsed, err := url.Parse(response.URL)
+		if err != nil {
+			return ""
+		}
+		if parsed.Path == "" {
+			return "/"
+		}
+		return parsed.Path
 	default:
 		return ""
 	}
 }
 
+// headerValues 获取原始响应头中指定名称的所有值，多个值使用换行分隔
+func headerValues(rawHeaders string, name string) string {
+	var values []string
+	for _, line := range strings.Split(rawHeaders, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), name) {
+			values = append(values, strings.TrimSpace(parts[1]))
+		}
+	}
+	return strings.Join(values, "\n")
+}
+
 // formatHeaders 格式化headers为字符串
 func formatHeaders(headers map[string]string) string {
 	var builder strings.Builder
@@ -266,6 +440,68 @@ func matchNotContains(condition types.Condition, ctx *MatchContext, baseResponse
 	return !strings.Contains(data, condition.Pattern), nil
 }
 
+// matchEquals 完全相等匹配，忽略大小写和首尾空白（适用于hash、状态码等）
+func matchEquals(condition types.Condition, ctx *MatchContext, baseResponse *types.AssetHttp) (bool, error) {
+	data := getDataByLocation(condition.Location, baseResponse)
+	if data == "" {
+		return false, nil
+	}
+	return strings.EqualFold(strings.TrimSpace(data), strings.TrimSpace(condition.Pattern)), nil
+}
+
+// matchCompare 数值比较，gt/gte/lt/lte
+func matchCompare(condition types.Condition, ctx *MatchContext, baseResponse *types.AssetHttp) (bool, error) {
+	expected, err := strconv.ParseFloat(strings.TrimSpace(condition.Pattern), 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid numeric pattern: %s", condition.Pattern)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(getDataByLocation(condition.Location, baseResponse)), 64)
+	if err != nil {
+		// 数据不是数字时不匹配
+		return false, nil
+	}
+	switch condition.MatchType {
+	case "gt":
+		return value > expected, nil
+	case "gte":
+		return value >= expected, nil
+	case "lt":
+		return value < expected, nil
+	default:
+		return value <= expected, nil
+	}
+}
+
+// matchRange 数值范围匹配，pattern格式为 "200-299" 或 "200,301,302" 或两者组合
+func matchRange(condition types.Condition, ctx *MatchContext, baseResponse *types.AssetHttp) (bool, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(getDataByLocation(condition.Location, baseResponse)), 64)
+	if err != nil {
+		return false, nil
+	}
+	for _, part := range strings.Split(condition.Pattern, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		low, err := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid range pattern: %s", condition.Pattern)
+		}
+		high := low
+		if len(bounds) == 2 {
+			high, err = strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid range pattern: %s", condition.Pattern)
+			}
+		}
+		if value >= low && value <= high {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // matchExtract 提取并保存变量
 func matchExtract(condition types.Condition, ctx *MatchContext, baseResponse *types.AssetHttp) (bool, error) {
 	data := getDataByLocation(condition.Location, baseResponse)
@@ -398,6 +634,11 @@ func sendHTTPRequest(ctx *MatchContext, method, url string) (*types.AssetHttp, e
 	}
 	defer resp.Body.Close()
 
+	return ResponseToAsset(url, resp)
+}
+
+// ResponseToAsset 将HTTP响应转换为用于匹配的资产数据
+func ResponseToAsset(url string, resp *http.Response) (*types.AssetHttp, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
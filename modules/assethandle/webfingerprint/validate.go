@@ -0,0 +1,197 @@
+// webfingerprint-------------------------------------
+// @file      : validate.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 21:10
+// -------------------------------------------
+
+package webfingerprint
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 支持的匹配位置，与getDataByLocation保持一致
+var validLocations = map[string]bool{
+	"body":         true,
+	"header":       true,
+	"title":        true,
+	"favicon_mmh3": true,
+	"favicon_md5":  true,
+	"status_code":  true,
+	"cookie":       true,
+	"server":       true,
+	"cert_subject": true,
+	"cert_issuer":  true,
+	"jarm":         true,
+	"url_path":     true,
+}
+
+// 支持的匹配方式，与evaluateNormalCondition保持一致
+var validMatchTypes = map[string]bool{
+	"regex":        true,
+	"contains":     true,
+	"not_contains": true,
+	"equals":       true,
+	"not_equals":   true,
+	"gt":           true,
+	"gte":          true,
+	"lt":           true,
+	"lte":          true,
+	"range":        true,
+	"extract":      true,
+	"active":       true,
+}
+
+// ValidateFingerprint 校验指纹结构、位置、匹配方式和正则，返回发现的问题列表
+func ValidateFingerprint(fingerprint *types.Fingerprint) []string {
+	var problems []string
+	if strings.TrimSpace(fingerprint.Name) == "" {
+		problems = append(problems, "name is empty")
+	}
+	if len(fingerprint.Rules) == 0 {
+		problems = append(problems, "no rules")
+	}
+	saved := make(map[string]bool)
+	for i, rule := range fingerprint.Rules {
+		prefix := fmt.Sprintf("rules[%d]", i)
+		// rule未设置logic时按OR处理
+		if rule.Logic != "" && !validLogic(rule.Logic) {
+			problems = append(problems, fmt.Sprintf("%s: invalid logic %q", prefix, rule.Logic))
+		}
+		if len(rule.Conditions) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: no conditions", prefix))
+		}
+		for j, condition := range rule.Conditions {
+			problems = append(problems, validateCondition(condition, fmt.Sprintf("%s.conditions[%d]", prefix, j), saved)...)
+		}
+	}
+	if fingerprint.Version != nil {
+		version := fingerprint.Version
+		if version.From != "" {
+			if !saved[version.From] {
+				problems = append(problems, fmt.Sprintf("version: variable %q is not saved by any extract condition", version.From))
+			}
+		} else if version.Pattern == "" {
+			problems = append(problems, "version: from or pattern is required")
+		} else {
+			if version.Location != "" && !validLocations[version.Location] {
+				problems = append(problems, fmt.Sprintf("version: invalid location %q", version.Location))
+			}
+			if re, err := regexp.Compile(version.Pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("version: invalid regex: %v", err))
+			} else if version.Group > re.NumSubexp() {
+				problems = append(problems, fmt.Sprintf("version: group %d exceeds %d groups in regex", version.Group, re.NumSubexp()))
+			}
+		}
+	}
+	return problems
+}
+
+// validateCondition 递归校验条件及条件组
+func validateCondition(condition types.Condition, prefix string, saved map[string]bool) []string {
+	var problems []string
+	if isConditionGroup(condition) {
+		if !validLogic(condition.Logic) {
+			problems = append(problems, fmt.Sprintf("%s: invalid logic %q", prefix, condition.Logic))
+		}
+		if len(condition.Conditions) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: empty condition group", prefix))
+		}
+		for i, sub := range condition.Conditions {
+			problems = append(problems, validateCondition(sub, fmt.Sprintf("%s.conditions[%d]", prefix, i), saved)...)
+		}
+		return problems
+	}
+	if !validMatchTypes[condition.MatchType] {
+		return append(problems, fmt.Sprintf("%s: invalid match_type %q", prefix, condition.MatchType))
+	}
+	if condition.MatchType == "active" {
+		if condition.Path == "" && condition.DynamicPath == "" {
+			problems = append(problems, fmt.Sprintf("%s: active condition must have path or dynamic_path", prefix))
+		}
+		for i, sub := range condition.Conditions {
+			problems = append(problems, validateCondition(sub, fmt.Sprintf("%s.conditions[%d]", prefix, i), saved)...)
+		}
+		return problems
+	}
+	if !validLocations[condition.Location] {
+		problems = append(problems, fmt.Sprintf("%s: invalid location %q", prefix, condition.Location))
+	}
+	if condition.Pattern == "" {
+		problems = append(problems, fmt.Sprintf("%s: pattern is empty", prefix))
+		return problems
+	}
+	switch condition.MatchType {
+	case "regex", "extract":
+		re, err := regexp.Compile(condition.Pattern)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid regex: %v", prefix, err))
+			break
+		}
+		if condition.MatchType == "extract" {
+			if condition.SaveAs == "" {
+				problems = append(problems, fmt.Sprintf("%s: extract condition must have save_as", prefix))
+			}
+			if condition.Group > re.NumSubexp() {
+				problems = append(problems, fmt.Sprintf("%s: group %d exceeds %d groups in regex", prefix, condition.Group, re.NumSubexp()))
+			}
+			saved[condition.SaveAs] = true
+		}
+	case "gt", "gte", "lt", "lte":
+		if _, err := strconv.ParseFloat(strings.TrimSpace(condition.Pattern), 64); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: pattern %q is not a number", prefix, condition.Pattern))
+		}
+	case "range":
+		if !validRange(condition.Pattern) {
+			problems = append(problems, fmt.Sprintf("%s: invalid range pattern %q", prefix, condition.Pattern))
+		}
+	}
+	for i, sub := range condition.Conditions {
+		problems = append(problems, validateCondition(sub, fmt.Sprintf("%s.conditions[%d]", prefix, i), saved)...)
+	}
+	return problems
+}
+
+// HasActiveCondition 判断指纹是否包含需要主动发送请求的条件
+func HasActiveCondition(fingerprint *types.Fingerprint) bool {
+	for _, rule := range fingerprint.Rules {
+		if hasActive(rule.Conditions) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActive(conditions []types.Condition) bool {
+	for _, condition := range conditions {
+		if condition.MatchType == "active" || hasActive(condition.Conditions) {
+			return true
+		}
+	}
+	return false
+}
+
+func validLogic(logic string) bool {
+	return logic == "AND" || logic == "OR"
+}
+
+// validRange 校验range格式，与matchRange的解析方式一致
+func validRange(pattern string) bool {
+	for _, part := range strings.Split(pattern, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		for _, bound := range strings.SplitN(part, "-", 2) {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(bound), 64); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -110,7 +110,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 
 func (p *Plugin) GetParameter() string {
@@ -133,18 +133,19 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	var matchFingers = []*types.Fingerprint{}
 	// 新版本
 	// 使用ac自动机进行预匹配
-	acFingers := AcRun(httpResult.Title, httpResult.RawHeaders, httpResult.ResponseBody)
+	acFingers := AcRun(httpResult)
 	matchFingers = append(matchFingers, acFingers...)
 	// 增加无法使用ac自动机的指纹
 	matchFingers = append(matchFingers, acMatcher.NonACFingerprints...)
 
 	for _, fingerprint := range matchFingers {
-		matchFlag, err := MatchFingerprint(fingerprint, httpResult)
+		product, err := MatchProduct(fingerprint, httpResult)
 		if err != nil {
 			return nil, err
 		}
-		if matchFlag {
+		if product != nil {
 			mu.Lock()
+			addProduct(httpResult, product)
 			alreadyExists := false
 			for _, tech := range httpResult.Technologies {
 				if strings.ToLower(tech) == strings.ToLower(fingerprint.Name) {
@@ -171,6 +172,19 @@ func (p *Plugin) Clone() interfaces.Plugin {
 	}
 }
 
+// addProduct 添加产品信息，同名产品只保留一条，已有条目没有版本时使用新的版本
+func addProduct(asset *types.AssetHttp, product *types.Product) {
+	for i := range asset.Products {
+		if strings.EqualFold(asset.Products[i].Name, product.Name) {
+			if asset.Products[i].Version == "" && product.Version != "" {
+				asset.Products[i] = *product
+			}
+			return
+		}
+	}
+	asset.Products = append(asset.Products, *product)
+}
+
 func popLastTwoBool(slice []bool) (bool, bool, []bool) {
 	if len(slice) < 2 {
 		return false, false, slice // 如果切片长度小于2，直接返回原切片
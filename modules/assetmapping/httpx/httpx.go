@@ -8,8 +8,10 @@
 package httpx
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
@@ -27,6 +29,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -45,6 +48,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -112,7 +119,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 
 func (p *Plugin) Execute(input interface{}) (interface{}, error) {
@@ -146,7 +153,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	httpxResultsHandler := func(r types.AssetHttp) {
 		p.Result <- r
 	}
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	for _, target := range targetList {
 		wg.Add(1)
 		go func(t string) {
@@ -157,7 +164,8 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 				return
 			default:
 				// 正常逻辑，只跑一次
-				utils.RunAnalyze(t, httpxResultsHandler)
+				// 携带任务配置的认证信息
+				utils.RunAnalyze(p.GetTaskId(), t, httpxResultsHandler, authmanager.GlobalAuthManager.Headers(p.GetTaskId(), t)...)
 			}
 		}(target)
 	}
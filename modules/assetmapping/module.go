@@ -15,6 +15,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
@@ -98,6 +99,8 @@ func (r *Runner) ModuleRun() error {
 	var end time.Time
 	doneCalled := false
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		//
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
@@ -155,13 +158,24 @@ func (r *Runner) ModuleRun() error {
 				defer allPluginWg.Done()
 				//发送来的数据 只能是types.Asset
 				if len(r.Option.AssetMapping) != 0 {
+					// 模块处理单条数据的时间预算，用尽后跳过剩余的插件
+					moduleCtx, moduleCancel := contextmanager.GlobalContextManagers.ModuleContext(r.Option.ID, r.Option.ModuleTimeout(r.GetName()))
+					defer moduleCancel()
+					// 追踪此数据在模块中的处理
+					moduleCtx, moduleSpan := tracing.StartItem(moduleCtx, r.Option.TraceCtx, r.GetName(), data)
+					defer moduleSpan.End()
 					// 调用插件
 					for _, pluginId := range r.Option.AssetMapping {
 						//var plgWg sync.WaitGroup
 						var plgWg sync.WaitGroup
 						plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
 						if flag {
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin start execute", plg.GetName()))
+							if contextmanager.BudgetExhausted(moduleCtx) {
+								handler.TaskHandle.ProgressSkip(r.GetName(), r.Option.Target, r.Option.ID, plg.GetName())
+								tracing.Skip(moduleCtx, r.GetName(), "module time budget exhausted: "+plg.GetName(), data)
+								continue
+							}
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug("plugin start execute")
 							plgWg.Add(1)
 							args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
 							if argsFlag {
@@ -172,12 +186,15 @@ func (r *Runner) ModuleRun() error {
 							plg.SetResult(resultChan)
 							plg.SetTaskId(r.Option.ID)
 							plg.SetTaskName(r.Option.TaskName)
+							if cp, ok := plg.(interfaces.ContextPlugin); ok {
+								cp.SetContext(moduleCtx)
+							}
 							// 这里和其他模块不同 传递的是数组
 							pluginFunc := func(assets interface{}) func() {
 								return func() {
 									defer plgWg.Done()
 									select {
-									case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+									case <-moduleCtx.Done():
 										return
 									default:
 										_, err := plg.Execute(assets)
@@ -186,13 +203,13 @@ func (r *Runner) ModuleRun() error {
 									}
 								}
 							}(assets)
-							err := pool.PoolManage.SubmitTask(r.GetName(), pluginFunc)
+							err := pool.PoolManage.SubmitTaskFor(r.Option.ID, r.GetName(), pluginFunc)
 							if err != nil {
 								plgWg.Done()
 								logger.SlogError(fmt.Sprintf("task pool error: %v", err))
 							}
 							plgWg.Wait()
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin end execute", plg.GetName()))
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug("plugin end execute")
 						} else {
 							logger.SlogError(fmt.Sprintf("plugin %v not found", pluginId))
 						}
@@ -8,6 +8,7 @@
 package customplugin
 
 import (
+	"context"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
@@ -30,6 +31,7 @@ type Plugin struct {
 	GetNameFunc   func() string
 	SetCustomFunc func(interface{})
 	TaskName      string
+	Ctx           context.Context
 }
 
 func NewPlugin(module string, plgId string, installFunc func() error, checkFunc func() error, executeFunc func(input interface{}, op options.PluginOption) (interface{}, error), unInstallFunc func() error, getNameFunc func() string, setCustomFunc func(interface{})) *Plugin {
@@ -52,6 +54,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -119,7 +125,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 
 func (p *Plugin) Execute(input interface{}) (interface{}, error) {
@@ -136,7 +142,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 		TaskId:     p.TaskId,
 		TaskName:   p.GetTaskName(),
 		Log:        p.Log,
-		Ctx:        contextmanager.GlobalContextManagers.GetContext(p.GetTaskId()),
+		Ctx:        contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId()),
 	}
 	if p.ExecuteFunc == nil {
 		p.Log("error exec is nil", "e")
@@ -14,7 +14,8 @@ import (
 )
 
 type Request struct {
-	Url string
+	Url     string
+	Headers []string // 额外的请求头，格式为 "Name: value"
 }
 
 func (r *Request) Request(path string) (types.HttpResponse, error) {
@@ -22,10 +23,10 @@ func (r *Request) Request(path string) (types.HttpResponse, error) {
 		path = path[1:] // 去掉前边的"/"
 	}
 	uri := r.Url + path
-	response, err := utils.Requests.HttpGet(uri)
+	response, err := r.get(uri)
 	if err != nil {
 		for i := 0; i < MaxRetries-5; i++ {
-			response, err = utils.Requests.HttpGet(uri)
+			response, err = r.get(uri)
 			if err != nil {
 				logger.SlogWarnLocal(fmt.Sprintf("Senstrydir target %s request error: %s", uri, err))
 				continue
@@ -35,3 +36,10 @@ func (r *Request) Request(path string) (types.HttpResponse, error) {
 	}
 	return response, err
 }
+
+func (r *Request) get(uri string) (types.HttpResponse, error) {
+	if len(r.Headers) != 0 {
+		return utils.Requests.HttpGetWithCustomHeader(uri, r.Headers)
+	}
+	return utils.Requests.HttpGet(uri)
+}
@@ -8,8 +8,10 @@
 package sentrydir
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
@@ -32,6 +34,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -50,6 +53,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -67,7 +74,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -130,7 +137,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	p.Log(fmt.Sprintf("scan terget begin: %v", data.URL))
 
 	// 获取上下文
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 
 	resultHandle := func(response types.HttpResponse) {
 		// 检查上下文是否已取消
@@ -172,6 +179,8 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 
 	dirDicConfigPath := filepath.Join(global.DictPath, dictFile)
 	controller := dirrunner.Controller{Targets: []string{data.URL}, Dictionary: dirDicConfigPath}
+	// 携带任务配置的认证信息
+	controller.Request.Headers = authmanager.GlobalAuthManager.Headers(p.GetTaskId(), data.URL)
 	op := dircore.Options{
 		Extensions:    []string{"php", "aspx", "jsp", "html", "js"},
 		Thread:        Thread,
@@ -44,7 +44,7 @@ func (r *Runner) ModuleRun() error {
 	for _, pluginId := range r.Option.PassiveScan {
 		plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
 		if flag {
-			logger.SlogDebugLocal(fmt.Sprintf("%v plugin start execute", plg.GetName()))
+			logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug("plugin start execute")
 			go func() {
 				wg.Add(1)
 				defer wg.Done()
@@ -77,6 +77,8 @@ func (r *Runner) ModuleRun() error {
 		handler.TaskHandle.ProgressEnd(r.GetName(), r.Option.Target, r.Option.ID, len(r.Option.PassiveScan), duration)
 	}
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
 			closePlgFunc()
@@ -16,6 +16,7 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
@@ -64,6 +65,8 @@ func (r *Runner) ModuleRun() error {
 				flag := results.Duplicate.PortIntask(r.Option.ID, portaliveResult.Host, port, r.Option.IsRestart)
 				if flag {
 					r.NextModule.GetInput() <- result
+				} else {
+					tracing.Drop(r.Option.TraceCtx, r.GetName(), "port already scanned in task", portaliveResult)
 				}
 			}
 		}
@@ -75,6 +78,8 @@ func (r *Runner) ModuleRun() error {
 	var end time.Time
 	doneCalled := false
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		//
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
@@ -130,13 +135,24 @@ func (r *Runner) ModuleRun() error {
 			go func(data interface{}) {
 				defer allPluginWg.Done()
 				if len(r.Option.PortScan) != 0 {
+					// 模块处理单条数据的时间预算，用尽后跳过剩余的插件
+					moduleCtx, moduleCancel := contextmanager.GlobalContextManagers.ModuleContext(r.Option.ID, r.Option.ModuleTimeout(r.GetName()))
+					defer moduleCancel()
+					// 追踪此数据在模块中的处理
+					moduleCtx, moduleSpan := tracing.StartItem(moduleCtx, r.Option.TraceCtx, r.GetName(), data)
+					defer moduleSpan.End()
 					// 调用插件
 					for _, pluginId := range r.Option.PortScan {
 						//var plgWg sync.WaitGroup
 						var plgWg sync.WaitGroup
 						plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
 						if flag {
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin start execute: %v", plg.GetName(), data))
+							if contextmanager.BudgetExhausted(moduleCtx) {
+								handler.TaskHandle.ProgressSkip(r.GetName(), r.Option.Target, r.Option.ID, plg.GetName())
+								tracing.Skip(moduleCtx, r.GetName(), "module time budget exhausted: "+plg.GetName(), data)
+								continue
+							}
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug(fmt.Sprintf("plugin start execute: %v", data))
 							plgWg.Add(1)
 							args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
 							if argsFlag {
@@ -150,11 +166,14 @@ func (r *Runner) ModuleRun() error {
 							plg.SetResult(resultChan)
 							plg.SetTaskId(r.Option.ID)
 							plg.SetTaskName(r.Option.TaskName)
+							if cp, ok := plg.(interfaces.ContextPlugin); ok {
+								cp.SetContext(moduleCtx)
+							}
 							pluginFunc := func(data interface{}) func() {
 								return func() {
 									defer plgWg.Done()
 									select {
-									case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+									case <-moduleCtx.Done():
 										return
 									default:
 										_, err := plg.Execute(data)
@@ -163,13 +182,13 @@ func (r *Runner) ModuleRun() error {
 									}
 								}
 							}(data)
-							err := pool.PoolManage.SubmitTask(r.GetName(), pluginFunc)
+							err := pool.PoolManage.SubmitTaskFor(r.Option.ID, r.GetName(), pluginFunc)
 							if err != nil {
 								plgWg.Done()
 								logger.SlogError(fmt.Sprintf("task pool error: %v", err))
 							}
 							plgWg.Wait()
-							logger.SlogDebugLocal(fmt.Sprintf("%v plugin end execute: %v", plg.GetName(), data))
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Debug(fmt.Sprintf("plugin end execute: %v", data))
 						} else {
 							logger.SlogError(fmt.Sprintf("plugin %v not found", pluginId))
 						}
@@ -40,6 +40,7 @@ type Plugin struct {
 	Custom       interface{}
 	TaskId       string
 	TaskName     string
+	Ctx          context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -76,6 +77,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -90,7 +95,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -235,7 +240,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	}
 	rustScanExecPath := filepath.Join(filepath.Join(global.ExtDir, "rustscan"), p.RustFileName)
 	// 假设你已经有获取 TaskID 的逻辑
-	taskContext := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	taskContext := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 
 	// 为命令设置一个超时时间
 	timeout := time.Duration(executionTimeout) * time.Minute // 例如，设置为30分钟超时
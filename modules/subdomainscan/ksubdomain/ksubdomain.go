@@ -33,6 +33,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -50,6 +51,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -67,7 +72,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -216,7 +221,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 		logger.SlogError(fmt.Sprintf("ksubdomain 运行失败: 没有提供子域名字典，请查看任务配置"))
 		return nil, nil
 	}
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	subfile = filepath.Join(global.DictPath, subfile)
 	subDictChan := make(chan string, 10)
 	go utils.Tools.ReadFileLineReader(subfile, subDictChan, ctx)
@@ -8,6 +8,7 @@
 package subdomainscan
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
@@ -16,7 +17,9 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/workqueue"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	"net"
@@ -28,6 +31,11 @@ type Runner struct {
 	Option     *options.TaskOptions
 	NextModule interfaces.ModuleRunner
 	Input      chan interface{}
+	queue      *workqueue.Queue
+	workWake   chan struct{}
+	workDone   chan struct{}
+	workWg     sync.WaitGroup
+	taken      []workqueue.Item // 本节点取出的子域名，下个模块处理完成后释放租约
 }
 
 func NewRunner(op *options.TaskOptions, nextModule interfaces.ModuleRunner) *Runner {
@@ -51,6 +59,14 @@ func (r *Runner) ModuleRun() error {
 			logger.SlogError(fmt.Sprintf("Next module run error: %v", err))
 		}
 	}()
+	// 多节点领取同一任务时，下个模块繁忙的子域名放入共享队列
+	r.queue = workqueue.Get(r.Option.ID)
+	if r.queue != nil {
+		r.workWake = make(chan struct{}, 1)
+		r.workDone = make(chan struct{})
+		r.workWg.Add(1)
+		go r.runWork()
+	}
 	// 结果处理 goroutine，异步读取插件的结果
 	resultWg.Add(1)
 	go func() {
@@ -60,7 +76,11 @@ func (r *Runner) ModuleRun() error {
 			case result, ok := <-resultChan:
 				if !ok {
 					// 如果 resultChan 关闭了，退出循环
-					// 此模块运行完毕，关闭下个模块的输入
+					// 此模块运行完毕，等待积压的子域名发送完毕后关闭下个模块的输入
+					if r.queue != nil {
+						close(r.workDone)
+						r.workWg.Wait()
+					}
 					r.NextModule.CloseInput()
 					return
 				}
@@ -75,16 +95,19 @@ func (r *Runner) ModuleRun() error {
 								// 没有在mongodb中查询到该子域名，存入数据库中并且开始扫描
 								go results.Handler.Subdomain(&subdomainResult)
 								// 将子域名解析结果发送到下个模块
-								r.NextModule.GetInput() <- subdomainResult
+								r.sendNext(subdomainResult)
+							} else {
+								tracing.Drop(r.Option.TraceCtx, r.GetName(), "subdomain exists in database", subdomainResult)
 							}
 						} else {
 							// 存入数据库中，并且开始扫描
 							go results.Handler.Subdomain(&subdomainResult)
 							// 将子域名解析结果发送到下个模块
-							r.NextModule.GetInput() <- subdomainResult
+							r.sendNext(subdomainResult)
 						}
 					} else {
 						// 跳过当前任务中已扫描的子域名
+						tracing.Drop(r.Option.TraceCtx, r.GetName(), "subdomain already scanned in task", subdomainResult)
 						continue
 					}
 				} else {
@@ -112,6 +135,8 @@ func (r *Runner) ModuleRun() error {
 								go results.Handler.Subdomain(&tmp)
 								r.NextModule.GetInput() <- tmp
 							}
+						} else {
+							tracing.Drop(r.Option.TraceCtx, r.GetName(), "target already scanned in task", target)
 						}
 					}
 				}
@@ -125,6 +150,8 @@ func (r *Runner) ModuleRun() error {
 	var end time.Time
 	doneCalled := false
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		// 输入有两种可能，一种域名，一种ip
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
@@ -136,6 +163,7 @@ func (r *Runner) ModuleRun() error {
 				doneCalled = true // 标记已调用 Done
 			}
 			nextModuleRun.Wait()
+			r.releaseWork()
 			return nil
 		case data, ok := <-r.Input:
 			if !ok {
@@ -156,6 +184,7 @@ func (r *Runner) ModuleRun() error {
 				}
 				logger.SlogInfoLocal(fmt.Sprintf("module %v target %v close resultChan", r.GetName(), r.Option.Target))
 				nextModuleRun.Wait()
+				r.releaseWork()
 				return nil
 			}
 			//_, ok = data.(string)
@@ -181,13 +210,24 @@ func (r *Runner) ModuleRun() error {
 				if len(r.Option.SubdomainScan) != 0 {
 					// 跳过插件
 					skipPluginFlag := true
+					// 模块处理单条数据的时间预算，用尽后跳过剩余的插件
+					moduleCtx, moduleCancel := contextmanager.GlobalContextManagers.ModuleContext(r.Option.ID, r.Option.ModuleTimeout(r.GetName()))
+					defer moduleCancel()
+					// 追踪此数据在模块中的处理
+					moduleCtx, moduleSpan := tracing.StartItem(moduleCtx, r.Option.TraceCtx, r.GetName(), data)
+					defer moduleSpan.End()
 					// 调用插件
 					for _, pluginId := range r.Option.SubdomainScan {
 						//var plgWg sync.WaitGroup
 						var plgWg sync.WaitGroup
 						plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
 						if flag {
-							logger.SlogInfoLocal(fmt.Sprintf("%v plugin start execute: %v", plg.GetName(), data))
+							if contextmanager.BudgetExhausted(moduleCtx) {
+								handler.TaskHandle.ProgressSkip(r.GetName(), r.Option.Target, r.Option.ID, plg.GetName())
+								tracing.Skip(moduleCtx, r.GetName(), "module time budget exhausted: "+plg.GetName(), data)
+								continue
+							}
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Info(fmt.Sprintf("plugin start execute: %v", data))
 							plgWg.Add(1)
 							args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
 							if argsFlag {
@@ -203,11 +243,14 @@ func (r *Runner) ModuleRun() error {
 							plg.SetResult(resultChan)
 							plg.SetTaskId(r.Option.ID)
 							plg.SetTaskName(r.Option.TaskName)
+							if cp, ok := plg.(interfaces.ContextPlugin); ok {
+								cp.SetContext(moduleCtx)
+							}
 							pluginFunc := func(data interface{}) func() {
 								return func() {
 									defer plgWg.Done()
 									select {
-									case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+									case <-moduleCtx.Done():
 										return
 									default:
 										_, err := plg.Execute(data)
@@ -216,13 +259,13 @@ func (r *Runner) ModuleRun() error {
 									}
 								}
 							}(data)
-							err := pool.PoolManage.SubmitTask(r.GetName(), pluginFunc)
+							err := pool.PoolManage.SubmitTaskFor(r.Option.ID, r.GetName(), pluginFunc)
 							if err != nil {
 								plgWg.Done()
 								logger.SlogError(fmt.Sprintf("task pool error: %v", err))
 							}
 							plgWg.Wait()
-							logger.SlogInfoLocal(fmt.Sprintf("%v plugin end execute: %v", plg.GetName(), data))
+							logger.Fields{TaskId: r.Option.ID, Target: r.Option.Target, Module: r.GetName(), Plugin: plg.GetName()}.Info(fmt.Sprintf("plugin end execute: %v", data))
 						} else {
 							// 插件没有找到跳过此插件
 							logger.SlogError(fmt.Sprintf("plugin %v not found, Skip this plugin", pluginId))
@@ -241,6 +284,74 @@ func (r *Runner) ModuleRun() error {
 	}
 }
 
+// sendNext 将子域名发送到下个模块，下个模块繁忙时放入共享队列，空闲的节点可以窃取
+func (r *Runner) sendNext(result types.SubdomainResult) {
+	if r.queue != nil {
+		select {
+		case r.NextModule.GetInput() <- result:
+			return
+		default:
+		}
+		data, err := json.Marshal(result)
+		if err == nil {
+			err = r.queue.Offer("subdomain", string(data))
+		}
+		if err == nil {
+			select {
+			case r.workWake <- struct{}{}:
+			default:
+			}
+			return
+		}
+		logger.SlogErrorLocal(fmt.Sprintf("task %v offer subdomain %v error: %v", r.Option.ID, result.Host, err))
+	}
+	r.NextModule.GetInput() <- result
+}
+
+// runWork 将本节点积压且没有被其他节点窃取的子域名发送到下个模块
+func (r *Runner) runWork() {
+	defer r.workWg.Done()
+	for {
+		if item, ok := r.queue.Take(); ok {
+			r.sendWork(item)
+			continue
+		}
+		select {
+		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
+			return
+		case <-r.workWake:
+		case <-r.workDone:
+			// 输入已结束，取完剩余的子域名后退出
+			for {
+				item, ok := r.queue.Take()
+				if !ok {
+					return
+				}
+				r.sendWork(item)
+			}
+		}
+	}
+}
+
+func (r *Runner) sendWork(item workqueue.Item) {
+	var result types.SubdomainResult
+	if err := json.Unmarshal([]byte(item.Value), &result); err != nil {
+		logger.SlogErrorLocal(fmt.Sprintf("task %v work parse error: %v", r.Option.ID, err))
+		r.queue.Done(item)
+		return
+	}
+	r.taken = append(r.taken, item)
+	r.NextModule.GetInput() <- result
+}
+
+// releaseWork 下个模块运行结束后释放取出的子域名的租约，runWork 已经退出
+func (r *Runner) releaseWork() {
+	for _, item := range r.taken {
+		r.queue.Done(item)
+	}
+	r.taken = nil
+}
+
 func (r *Runner) SetInput(ch chan interface{}) {
 	r.Input = ch
 }
@@ -9,6 +9,7 @@ package subfinder
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
@@ -33,6 +34,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -50,6 +52,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -67,7 +73,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v]%v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v]%v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -197,7 +203,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	if err != nil {
 		log.Fatalf("failed to create subfinder runner: %v", err)
 	}
-	err = subfinder.RunEnumerationWithCtx(contextmanager.GlobalContextManagers.GetContext(p.GetTaskId()))
+	err = subfinder.RunEnumerationWithCtx(contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId()))
 	if err != nil {
 		logger.SlogError(fmt.Sprintf("%v error: %v", p.GetName(), err))
 		return nil, err
@@ -8,9 +8,11 @@
 package katana
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
@@ -42,6 +44,7 @@ type Plugin struct {
 	KatanaDir      string
 	TaskId         string
 	TaskName       string
+	Ctx            context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -76,6 +79,10 @@ func (p *Plugin) SetTaskName(name string) {
 func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
+
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -92,7 +99,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -285,8 +292,12 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 		args = append(args, "-proxy")
 		args = append(args, proxy)
 	}
+	// 携带任务配置的认证信息
+	for _, header := range authmanager.GlobalAuthManager.Headers(p.GetTaskId(), data.URL) {
+		args = append(args, "-H", header)
+	}
 	logger.SlogDebugLocal(fmt.Sprintf("katana target:%v result:%v", data.URL, resultFile))
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	err := utils.Tools.ExecuteCommandWithTimeout(cmd, args, time.Duration(executionTimeout)*time.Minute, ctx)
 	if err != nil {
 		logger.SlogError(fmt.Sprintf("%v ExecuteCommandWithTimeout error: %v", p.GetName(), err))
@@ -10,6 +10,7 @@ package wayback
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
@@ -40,6 +41,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -56,6 +58,10 @@ func (p *Plugin) SetTaskName(name string) {
 func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
+
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -73,7 +79,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -288,7 +294,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	resultNumber := 0
 	urlWithoutHTTP := strings.TrimPrefix(data.URL, "http://")
 	urlWithoutHTTPS := strings.TrimPrefix(urlWithoutHTTP, "https://")
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	// Waybackarchive
 	number := source.WaybackarchiveRun(urlWithoutHTTPS, waybackResults, ctx)
 	p.Log(fmt.Sprintf("Waybackarchive targert %v obtain the number of URLs: %v", urlWithoutHTTPS, number))
@@ -0,0 +1,616 @@
+// jsanalysis-------------------------------------
+// @file      : extract.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/18 20:12
+// -------------------------------------------
+
+package jsanalysis
+
+import (
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Endpoint 从js中提取出的接口
+type Endpoint struct {
+	Url     string
+	Method  string
+	Params  []string
+	Body    string
+	Context string // fetch、axios、xhr、jquery、literal 等来源
+}
+
+// GraphQLOperation 从js中提取出的GraphQL操作
+type GraphQLOperation struct {
+	Type  string // query mutation subscription
+	Name  string
+	Query string
+}
+
+// Secret 从js中提取出的硬编码凭据
+type Secret struct {
+	Key   string
+	Value string
+}
+
+// Extraction js分析结果
+type Extraction struct {
+	Endpoints []Endpoint
+	GraphQL   []GraphQLOperation
+	Hosts     []string
+	Secrets   []Secret
+	SourceMap string
+}
+
+// 表达式无法静态求值时使用的占位符
+const exprPlaceholder = "EXPR"
+
+var (
+	httpMethods = map[string]string{
+		"get": "GET", "post": "POST", "put": "PUT", "delete": "DELETE",
+		"patch": "PATCH", "head": "HEAD", "options": "OPTIONS", "request": "",
+	}
+	// 可能是路径或者完整url的字符串
+	pathLikeRe = regexp.MustCompile(`^(?:(?:https?:)?//[a-zA-Z0-9.\-]+(?::\d+)?)?/[a-zA-Z0-9_\-./{}:$%?=&]*$`)
+	// 常见的静态资源，不作为接口输出
+	staticExtRe = regexp.MustCompile(`(?i)\.(png|apng|bmp|gif|ico|cur|jpg|jpeg|jfif|svg|tif|tiff|webp|woff2?|ttf|otf|eot|css|less|scss|mp3|mp4|webm|ogg|wav)(?:\?|#|$)`)
+	graphqlRe   = regexp.MustCompile(`(?s)^\s*(query|mutation|subscription)\s+([A-Za-z_][A-Za-z0-9_]*)?\s*[({]`)
+	sourceMapRe = regexp.MustCompile(`(?m)[#@]\s*sourceMappingURL=(\S+)\s*$`)
+	// 变量名或者属性名符合时，认为值可能是凭据
+	secretKeyRe = regexp.MustCompile(`(?i)(api[_\-]?key|app[_\-]?(?:key|secret)|secret|token|passw(?:or)?d|access[_\-]?key|private[_\-]?key|client[_\-]?secret|auth)`)
+	// 常见的占位符以及无意义的值
+	secretPlaceholderRe = regexp.MustCompile(`(?i)^(?:x+|\*+|0+|null|undefined|true|false|none|test|example|changeme|password|your[_\-]?.*|<.*>|\$\{.*\}|\{\{.*\}\})$`)
+	hostRe              = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}$`)
+
+	// AST解析失败时使用的正则兜底（jsluice 的思路，只是精度更低）
+	fallbackCallRe    = regexp.MustCompile(`(?i)(fetch|axios(?:\.(get|post|put|delete|patch|head|options))?|\$\.(get|post|ajax)|\.open)\s*\(\s*(?:["'](GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)["']\s*,\s*)?["'\x60]([^"'\x60\s]{1,512})["'\x60]`)
+	fallbackLiteralRe = regexp.MustCompile(`["'\x60]((?:https?:)?//[a-zA-Z0-9.\-]+(?::\d+)?/[^"'\x60\s<>]{0,512}|/[a-zA-Z0-9_\-][a-zA-Z0-9_\-./{}:$%?=&]{1,512})["'\x60]`)
+	fallbackGraphQLRe = regexp.MustCompile(`(?s)["'\x60]\s*((?:query|mutation|subscription)\s+[A-Za-z_][A-Za-z0-9_]*\s*[({][^"'\x60]{0,1000})["'\x60]`)
+)
+
+// Analyze 解析js内容，优先使用AST，解析失败时使用正则兜底
+func Analyze(content string) *Extraction {
+	ex := &extractor{
+		endpoints: make(map[string]*Endpoint),
+		graphql:   make(map[string]GraphQLOperation),
+		hosts:     make(map[string]struct{}),
+		secrets:   make(map[string]Secret),
+		called:    make(map[string]struct{}),
+	}
+	if m := sourceMapRe.FindAllStringSubmatch(content, -1); len(m) > 0 {
+		ex.sourceMap = strings.TrimSpace(m[len(m)-1][1])
+	}
+	program, err := parser.ParseFile(nil, "", content, parser.IgnoreRegExpErrors, parser.WithDisableSourceMaps)
+	if err != nil || program == nil {
+		ex.regexFallback(content)
+	} else {
+		ex.walk(reflect.ValueOf(program))
+	}
+	return ex.result()
+}
+
+type extractor struct {
+	endpoints map[string]*Endpoint
+	graphql   map[string]GraphQLOperation
+	hosts     map[string]struct{}
+	secrets   map[string]Secret
+	called    map[string]struct{}
+	sourceMap string
+}
+
+func (ex *extractor) result() *Extraction {
+	r := &Extraction{SourceMap: ex.sourceMap}
+	for _, e := range ex.endpoints {
+		sort.Strings(e.Params)
+		r.Endpoints = append(r.Endpoints, *e)
+	}
+	sort.Slice(r.Endpoints, func(i, j int) bool {
+		if r.Endpoints[i].Url == r.Endpoints[j].Url {
+			return r.Endpoints[i].Method < r.Endpoints[j].Method
+		}
+		return r.Endpoints[i].Url < r.Endpoints[j].Url
+	})
+	for _, g := range ex.graphql {
+		r.GraphQL = append(r.GraphQL, g)
+	}
+	sort.Slice(r.GraphQL, func(i, j int) bool { return r.GraphQL[i].Name < r.GraphQL[j].Name })
+	for h := range ex.hosts {
+		r.Hosts = append(r.Hosts, h)
+	}
+	sort.Strings(r.Hosts)
+	for _, sec := range ex.secrets {
+		r.Secrets = append(r.Secrets, sec)
+	}
+	sort.Slice(r.Secrets, func(i, j int) bool { return r.Secrets[i].Key+r.Secrets[i].Value < r.Secrets[j].Key+r.Secrets[j].Value })
+	return r
+}
+
+// walk 递归遍历AST（goja没有提供Visitor，这里通过反射遍历所有子节点）
+func (ex *extractor) walk(v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			ex.visit(v.Interface())
+		}
+		ex.walk(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				ex.walk(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			ex.walk(v.Index(i))
+		}
+	}
+}
+
+func (ex *extractor) visit(node interface{}) {
+	switch n := node.(type) {
+	case *ast.CallExpression:
+		ex.visitCall(n.Callee, n.ArgumentList)
+	case *ast.NewExpression:
+		// new Request(url, {method}) / new URL(url) / new XMLHttpRequest 不处理
+		if id, ok := n.Callee.(*ast.Identifier); ok && (id.Name == "Request" || id.Name == "URL") && len(n.ArgumentList) > 0 {
+			if u, ok := evalString(n.ArgumentList[0]); ok {
+				method, params, body := "", []string(nil), ""
+				if id.Name == "Request" && len(n.ArgumentList) > 1 {
+					method, params, body = requestOptions(n.ArgumentList[1])
+				}
+				ex.addEndpoint(u, method, params, body, strings.ToLower(string(id.Name)))
+			}
+		}
+	case *ast.StringLiteral:
+		ex.visitLiteral(string(n.Value))
+	case *ast.TemplateLiteral:
+		if s, ok := evalString(n); ok {
+			ex.visitLiteral(s)
+		}
+	case *ast.PropertyKeyed:
+		// {apiKey: "xxx"}
+		ex.visitSecret(propertyKey(n), n.Value)
+	case *ast.Binding:
+		// var token = "xxx"
+		if id, ok := n.Target.(*ast.Identifier); ok && n.Initializer != nil {
+			ex.visitSecret(string(id.Name), n.Initializer)
+		}
+	case *ast.AssignExpression:
+		// this.secret = "xxx" / config.token = "xxx"
+		if _, last := calleeName(n.Left); last != "" {
+			ex.visitSecret(last, n.Right)
+		}
+	}
+}
+
+func (ex *extractor) visitSecret(key string, value ast.Expression) {
+	if key == "" || !secretKeyRe.MatchString(key) {
+		return
+	}
+	lit, ok := value.(*ast.StringLiteral)
+	if !ok {
+		return
+	}
+	v := strings.TrimSpace(string(lit.Value))
+	if len(v) < 8 || len(v) > 512 || strings.ContainsAny(v, " \n") || secretPlaceholderRe.MatchString(v) || isPathLike(v) {
+		return
+	}
+	ex.secrets[key+"="+v] = Secret{Key: key, Value: v}
+}
+
+func (ex *extractor) visitLiteral(s string) {
+	if op, ok := parseGraphQL(s); ok {
+		ex.graphql[op.Type+":"+op.Name+":"+op.Query] = op
+		return
+	}
+	if isPathLike(s) {
+		ex.addEndpoint(s, "", nil, "", "literal")
+	}
+}
+
+func (ex *extractor) visitCall(callee ast.Expression, args []ast.Expression) {
+	if len(args) == 0 {
+		return
+	}
+	name, last := calleeName(callee)
+	switch {
+	case name == "fetch":
+		// fetch(url, {method, body})
+		if u, ok := evalString(args[0]); ok {
+			method, params, body := "", []string(nil), ""
+			if len(args) > 1 {
+				method, params, body = requestOptions(args[1])
+			}
+			ex.addEndpoint(u, method, params, body, "fetch")
+		}
+	case last == "open":
+		// xhr.open("POST", url)
+		if len(args) >= 2 {
+			m, ok1 := evalString(args[0])
+			u, ok2 := evalString(args[1])
+			if ok1 && ok2 {
+				if method, isMethod := httpMethods[strings.ToLower(m)]; isMethod && method != "" {
+					ex.addEndpoint(u, method, nil, "", "xhr")
+				}
+			}
+		}
+	case name == "axios" || name == "$.ajax" || name == "jQuery.ajax" || name == "request" || name == "ajax":
+		// axios(url, config) / axios({url, method, data}) / $.ajax({url, type, data})
+		if u, ok := evalString(args[0]); ok {
+			method, params, body := "", []string(nil), ""
+			if len(args) > 1 {
+				method, params, body = requestOptions(args[1])
+			}
+			ex.addEndpoint(u, method, params, body, contextName(name))
+		} else if obj, ok := args[0].(*ast.ObjectLiteral); ok {
+			if u, ok := objectString(obj, "url"); ok {
+				method, params, body := requestOptions(obj)
+				ex.addEndpoint(u, method, params, body, contextName(name))
+			}
+		}
+	default:
+		// axios.get(url, data) / $.post(url, data) / this.$http.put(url) 等
+		method, isMethod := httpMethods[strings.ToLower(last)]
+		if !isMethod || name == last {
+			return
+		}
+		u, ok := evalString(args[0])
+		if !ok || !isPathLike(u) {
+			return
+		}
+		if method == "" {
+			method = "GET"
+		}
+		var params []string
+		body := ""
+		if len(args) > 1 {
+			if obj, ok := args[1].(*ast.ObjectLiteral); ok {
+				if method == "GET" || method == "DELETE" || method == "HEAD" {
+					// get的第二个参数通常是config，参数在params字段中
+					if p, ok := objectValue(obj, "params").(*ast.ObjectLiteral); ok {
+						params = objectKeys(p)
+					}
+				} else {
+					params = objectKeys(obj)
+					body = objectToBody(obj)
+				}
+			}
+		}
+		ex.addEndpoint(u, method, params, body, contextName(name))
+	}
+}
+
+func (ex *extractor) addEndpoint(raw string, method string, params []string, body string, ctx string) {
+	raw = strings.TrimSpace(raw)
+	// baseUrl + "/api/xxx" 这种前缀无法计算时，按相对路径处理
+	if strings.HasPrefix(raw, exprPlaceholder+"/") {
+		raw = strings.TrimPrefix(raw, exprPlaceholder)
+	}
+	if raw == "" || !isPathLike(raw) || staticExtRe.MatchString(raw) {
+		return
+	}
+	if method == "" {
+		method = "GET"
+	}
+	if parsed, err := url.Parse(raw); err == nil {
+		if parsed.Host != "" {
+			ex.addHost(parsed.Hostname())
+		}
+		for k := range parsed.Query() {
+			params = append(params, k)
+		}
+	}
+	if ctx == "literal" {
+		// 已经在调用点中识别过的url不再作为字面量记录
+		if _, called := ex.called[raw]; called {
+			return
+		}
+	} else {
+		ex.called[raw] = struct{}{}
+	}
+	key := method + " " + raw
+	e, ok := ex.endpoints[key]
+	if !ok {
+		e = &Endpoint{Url: raw, Method: method, Context: ctx, Body: body}
+		ex.endpoints[key] = e
+	} else if e.Context == "literal" && ctx != "literal" {
+		// 调用点比单纯的字符串更有意义
+		e.Context = ctx
+	}
+	if e.Body == "" {
+		e.Body = body
+	}
+	e.Params = mergeParams(e.Params, params)
+	// 同一个url被识别为具体方法后，删除默认的GET字面量记录
+	if method != "GET" {
+		if lit, ok := ex.endpoints["GET "+raw]; ok && lit.Context == "literal" {
+			delete(ex.endpoints, "GET "+raw)
+		}
+	}
+}
+
+func (ex *extractor) addHost(host string) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || strings.Contains(host, exprPlaceholder) {
+		return
+	}
+	if hostRe.MatchString(host) {
+		ex.hosts[host] = struct{}{}
+	}
+}
+
+func (ex *extractor) regexFallback(content string) {
+	for _, m := range fallbackCallRe.FindAllStringSubmatch(content, -1) {
+		method := strings.ToUpper(m[4])
+		if method == "" {
+			if m[2] != "" {
+				method = strings.ToUpper(m[2])
+			} else if strings.EqualFold(m[3], "post") {
+				method = "POST"
+			}
+		}
+		ctx := "fetch"
+		switch {
+		case strings.HasPrefix(strings.ToLower(m[1]), "axios"):
+			ctx = "axios"
+		case strings.HasPrefix(m[1], "$"):
+			ctx = "jquery"
+		case m[1] == ".open":
+			ctx = "xhr"
+		}
+		ex.addEndpoint(m[5], method, nil, "", ctx)
+	}
+	for _, m := range fallbackLiteralRe.FindAllStringSubmatch(content, -1) {
+		ex.addEndpoint(m[1], "", nil, "", "literal")
+	}
+	for _, m := range fallbackGraphQLRe.FindAllStringSubmatch(content, -1) {
+		if op, ok := parseGraphQL(m[1]); ok {
+			ex.graphql[op.Type+":"+op.Name+":"+op.Query] = op
+		}
+	}
+}
+
+// evalString 尝试静态计算字符串表达式，无法计算的部分使用占位符替代
+func evalString(expr ast.Expression) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.StringLiteral:
+		return string(e.Value), true
+	case *ast.TemplateLiteral:
+		if e.Tag != nil {
+			return "", false
+		}
+		var sb strings.Builder
+		for i, el := range e.Elements {
+			sb.WriteString(string(el.Parsed))
+			if i < len(e.Expressions) {
+				if s, ok := evalString(e.Expressions[i]); ok {
+					sb.WriteString(s)
+				} else {
+					sb.WriteString(exprPlaceholder)
+				}
+			}
+		}
+		return sb.String(), true
+	case *ast.BinaryExpression:
+		if e.Operator.String() != "+" {
+			return "", false
+		}
+		left, lok := evalString(e.Left)
+		right, rok := evalString(e.Right)
+		if !lok && !rok {
+			return "", false
+		}
+		if !lok {
+			left = exprPlaceholder
+		}
+		if !rok {
+			right = exprPlaceholder
+		}
+		return left + right, true
+	}
+	return "", false
+}
+
+// calleeName 返回调用名称以及最后一段名称，例如 axios.get => ("axios.get", "get")
+func calleeName(expr ast.Expression) (string, string) {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return string(e.Name), string(e.Name)
+	case *ast.DotExpression:
+		left, _ := calleeName(e.Left)
+		if _, ok := e.Left.(*ast.ThisExpression); ok {
+			left = "this"
+		}
+		if left == "" {
+			left = exprPlaceholder
+		}
+		return left + "." + string(e.Identifier.Name), string(e.Identifier.Name)
+	case *ast.BracketExpression:
+		left, _ := calleeName(e.Left)
+		if s, ok := evalString(e.Member); ok {
+			return left + "." + s, s
+		}
+	}
+	return "", ""
+}
+
+func contextName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, "axios"):
+		return "axios"
+	case strings.HasPrefix(lower, "$") || strings.HasPrefix(lower, "jquery"):
+		return "jquery"
+	default:
+		return "call"
+	}
+}
+
+// requestOptions 从fetch/axios/$.ajax的配置对象中提取方法、参数和请求体
+func requestOptions(expr ast.Expression) (string, []string, string) {
+	obj, ok := expr.(*ast.ObjectLiteral)
+	if !ok {
+		return "", nil, ""
+	}
+	method := ""
+	for _, key := range []string{"method", "type"} {
+		if m, ok := objectString(obj, key); ok {
+			method = strings.ToUpper(m)
+			break
+		}
+	}
+	var params []string
+	body := ""
+	for _, key := range []string{"params", "data", "body"} {
+		switch v := objectValue(obj, key).(type) {
+		case *ast.ObjectLiteral:
+			params = append(params, objectKeys(v)...)
+			if key != "params" {
+				body = objectToBody(v)
+			}
+		case *ast.CallExpression:
+			// JSON.stringify({...})
+			if name, _ := calleeName(v.Callee); name == "JSON.stringify" && len(v.ArgumentList) > 0 {
+				if o, ok := v.ArgumentList[0].(*ast.ObjectLiteral); ok {
+					params = append(params, objectKeys(o)...)
+					body = objectToJSON(o)
+				}
+			}
+		default:
+			if s, ok := evalString(v); ok && key != "params" {
+				body = s
+			}
+		}
+	}
+	return method, params, body
+}
+
+func propertyKey(p *ast.PropertyKeyed) string {
+	switch k := p.Key.(type) {
+	case *ast.StringLiteral:
+		return string(k.Value)
+	case *ast.Identifier:
+		return string(k.Name)
+	}
+	return ""
+}
+
+func objectValue(obj *ast.ObjectLiteral, key string) ast.Expression {
+	for _, prop := range obj.Value {
+		if p, ok := prop.(*ast.PropertyKeyed); ok && propertyKey(p) == key {
+			return p.Value
+		}
+		if p, ok := prop.(*ast.PropertyShort); ok && string(p.Name.Name) == key {
+			return &p.Name
+		}
+	}
+	return nil
+}
+
+func objectString(obj *ast.ObjectLiteral, key string) (string, bool) {
+	v := objectValue(obj, key)
+	if v == nil {
+		return "", false
+	}
+	return evalString(v)
+}
+
+func objectKeys(obj *ast.ObjectLiteral) []string {
+	var keys []string
+	for _, prop := range obj.Value {
+		switch p := prop.(type) {
+		case *ast.PropertyKeyed:
+			if k := propertyKey(p); k != "" {
+				keys = append(keys, k)
+			}
+		case *ast.PropertyShort:
+			keys = append(keys, string(p.Name.Name))
+		}
+	}
+	return keys
+}
+
+// objectToBody 将对象字面量转为表单格式的请求体
+func objectToBody(obj *ast.ObjectLiteral) string {
+	var parts []string
+	for _, k := range objectKeys(obj) {
+		v := ""
+		if s, ok := objectString(obj, k); ok {
+			v = s
+		}
+		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+	}
+	return strings.Join(parts, "&")
+}
+
+// objectToJSON 将对象字面量转为json格式的请求体
+func objectToJSON(obj *ast.ObjectLiteral) string {
+	var parts []string
+	for _, k := range objectKeys(obj) {
+		v := ""
+		if s, ok := objectString(obj, k); ok {
+			v = s
+		}
+		parts = append(parts, strconvQuote(k)+":"+strconvQuote(v))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func strconvQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func parseGraphQL(s string) (GraphQLOperation, bool) {
+	m := graphqlRe.FindStringSubmatch(s)
+	if m == nil {
+		return GraphQLOperation{}, false
+	}
+	// 简单检查括号是否闭合，避免误报普通文本
+	if strings.Count(s, "{") == 0 || strings.Count(s, "{") != strings.Count(s, "}") {
+		return GraphQLOperation{}, false
+	}
+	return GraphQLOperation{Type: m[1], Name: m[2], Query: strings.TrimSpace(s)}, true
+}
+
+func isPathLike(s string) bool {
+	if len(s) < 2 || len(s) > 1024 || strings.HasPrefix(s, "//") && !strings.Contains(s[2:], "/") {
+		return false
+	}
+	if strings.Contains(s, " ") || strings.Contains(s, "\n") {
+		return false
+	}
+	// 排除正则、注释、日期等常见误报
+	if strings.HasPrefix(s, "/*") || strings.HasPrefix(s, "//") && !strings.Contains(s, ".") || strings.Count(s, "/") == len(s) {
+		return false
+	}
+	return pathLikeRe.MatchString(s)
+}
+
+func mergeParams(a []string, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	var out []string
+	for _, p := range append(a, b...) {
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	return out
+}
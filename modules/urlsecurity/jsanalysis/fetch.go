@@ -0,0 +1,85 @@
+// jsanalysis-------------------------------------
+// @file      : fetch.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/28 09:40
+// -------------------------------------------
+
+package jsanalysis
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 接口响应体最大读取长度
+const maxFetchBodySize = 1 * 1024 * 1024
+
+// 路径中包含这些关键字的接口可能产生副作用（登出、删除等），不主动请求
+var unsafeKeywords = []string{"logout", "signout", "sign-out", "log-out", "exit", "delete", "remove", "destroy", "drop", "clear", "reset", "revoke", "disable", "cancel", "unbind", "kill"}
+
+var fetchClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext:     (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+	},
+	// 不跟随跳转，保留接口本身的响应
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// fetchResponse 接口请求结果
+type fetchResponse struct {
+	Status int
+	Length int
+	Body   string
+}
+
+// safeToFetch 判断接口是否可以安全地发起GET请求
+func safeToFetch(absUrl string) bool {
+	u, err := url.Parse(absUrl)
+	if err != nil {
+		return false
+	}
+	path := strings.ToLower(u.Path)
+	for _, keyword := range unsafeKeywords {
+		if strings.Contains(path, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchEndpoint 在任务上下文中请求接口，携带任务配置的认证信息，任务停止或暂停时请求会被取消
+func fetchEndpoint(ctx context.Context, taskId string, absUrl string) (fetchResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, absUrl, nil)
+	if err != nil {
+		return fetchResponse{}, err
+	}
+	for k, v := range authmanager.GlobalAuthManager.HeaderMap(taskId, absUrl) {
+		req.Header.Set(k, v)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return fetchResponse{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBodySize))
+	if err != nil {
+		return fetchResponse{}, err
+	}
+	length := int(resp.ContentLength)
+	if length < 0 {
+		length = len(body)
+	}
+	return fetchResponse{Status: resp.StatusCode, Length: length, Body: string(body)}, nil
+}
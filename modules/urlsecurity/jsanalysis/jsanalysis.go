@@ -0,0 +1,393 @@
+// jsanalysis-------------------------------------
+// @file      : jsanalysis.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/18 20:05
+// -------------------------------------------
+
+package jsanalysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type Plugin struct {
+	Name      string
+	Module    string
+	Parameter string
+	PluginId  string
+	Result    chan interface{}
+	Custom    interface{}
+	TaskId    string
+	TaskName  string
+	Ctx       context.Context
+}
+
+func NewPlugin() *Plugin {
+	return &Plugin{
+		Name:     "jsanalysis",
+		Module:   "URLSecurity",
+		PluginId: "27b077246693b65b140aad9387d06120",
+	}
+}
+
+func (p *Plugin) SetTaskName(name string) {
+	p.TaskName = name
+}
+
+func (p *Plugin) GetTaskName() string {
+	return p.TaskName
+}
+
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
+func (p *Plugin) SetTaskId(id string) {
+	p.TaskId = id
+}
+
+func (p *Plugin) GetTaskId() string {
+	return p.TaskId
+}
+
+func (p *Plugin) Log(msg string, tp ...string) {
+	var logTp string
+	if len(tp) > 0 {
+		logTp = tp[0] // 使用传入的参数
+	} else {
+		logTp = "i"
+	}
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
+}
+func (p *Plugin) SetCustom(cu interface{}) {
+	p.Custom = cu
+}
+
+func (p *Plugin) GetCustom() interface{} {
+	return p.Custom
+}
+
+func (p *Plugin) SetPluginId(id string) {
+	p.PluginId = id
+}
+
+func (p *Plugin) GetPluginId() string {
+	return p.PluginId
+}
+
+func (p *Plugin) SetResult(ch chan interface{}) {
+	p.Result = ch
+}
+
+func (p *Plugin) SetName(name string) {
+	p.Name = name
+}
+
+func (p *Plugin) GetName() string {
+	return p.Name
+}
+
+func (p *Plugin) SetModule(module string) {
+	p.Module = module
+}
+
+func (p *Plugin) GetModule() string {
+	return p.Module
+}
+
+func (p *Plugin) Install() error {
+	return nil
+}
+
+func (p *Plugin) Check() error {
+	return nil
+}
+func (p *Plugin) UnInstall() error {
+	return nil
+}
+func (p *Plugin) SetParameter(args string) {
+	p.Parameter = args
+}
+
+func (p *Plugin) GetParameter() string {
+	return p.Parameter
+}
+
+func (p *Plugin) Execute(input interface{}) (interface{}, error) {
+	data, ok := input.(types.UrlResult)
+	if !ok {
+		return nil, nil
+	}
+	// 本插件输出的结果不再重复分析
+	if data.OutputType == "js" || data.Body == "" {
+		return nil, nil
+	}
+	if !utils.Tools.IsSuffixURL(data.Output, ".js") {
+		return nil, nil
+	}
+	sourceMapFlag := true
+	secretFlag := true
+	// 默认只输出与js同根域名的接口
+	allHost := false
+	// 请求提取出的GET接口并将响应交给本模块的其他插件检测，接口可能有副作用，需要fetch=true显式开启
+	fetchFlag := false
+	parameter := p.GetParameter()
+	if parameter != "" {
+		args, err := utils.Tools.ParseArgs(parameter, "sourcemap", "secret", "allhost", "fetch")
+		if err != nil {
+		} else {
+			for key, value := range args {
+				if value != "" {
+					switch key {
+					case "sourcemap":
+						sourceMapFlag = value != "false"
+					case "secret":
+						secretFlag = value != "false"
+					case "allhost":
+						allHost = value == "true"
+					case "fetch":
+						fetchFlag = value == "true"
+					default:
+						continue
+					}
+				}
+			}
+		}
+	}
+	// 同一个js在当前任务中只分析一次
+	bodyHash := utils.Tools.HashXX64String(data.Body)
+	if !results.Duplicate.SensitiveBody(bodyHash, p.TaskId, "jsanalysis") {
+		return nil, nil
+	}
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
+	extraction := Analyze(data.Body)
+	if sourceMapFlag && extraction.SourceMap != "" {
+		mapUrl, err := ResolveSourceMapURL(data.Output, extraction.SourceMap)
+		if err == nil {
+			sources, err := FetchSourceMap(mapUrl)
+			if err != nil {
+				p.Log(fmt.Sprintf("%v fetch source map %v error: %v", data.Output, mapUrl, err), "w")
+			} else {
+				p.Log(fmt.Sprintf("%v source map %v unpacked %v sources", data.Output, mapUrl, len(sources)))
+				for _, source := range sources {
+					select {
+					case <-ctx.Done():
+						return nil, nil
+					default:
+					}
+					mergeExtraction(extraction, Analyze(source.Content))
+				}
+			}
+		}
+	}
+	rootDomain, _ := utils.Tools.GetRootDomain(data.Output)
+	graphqlEndpoint := ""
+	for _, endpoint := range extraction.Endpoints {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+		}
+		absUrl, ok := resolveEndpoint(data.Output, endpoint.Url)
+		if !ok {
+			continue
+		}
+		if !allHost {
+			if endpointRoot, err := utils.Tools.GetRootDomain(absUrl); err != nil || endpointRoot != rootDomain {
+				continue
+			}
+		}
+		if graphqlEndpoint == "" && strings.Contains(strings.ToLower(absUrl), "graphql") {
+			graphqlEndpoint = strings.SplitN(absUrl, "?", 2)[0]
+		}
+		if endpoint.Method == "GET" {
+			absUrl = withParams(absUrl, endpoint.Params)
+			if !results.Duplicate.URL(absUrl, p.TaskId) {
+				continue
+			}
+			urlResult := types.UrlResult{
+				Input:      data.Output,
+				Source:     data.Output,
+				OutputType: "js",
+				Output:     absUrl,
+				Time:       utils.Tools.GetTimeNow(),
+				RootDomain: rootDomain,
+				Tags:       []string{endpoint.Context},
+			}
+			if fetchFlag && safeToFetch(absUrl) {
+				if resp, err := fetchEndpoint(ctx, p.TaskId, absUrl); err == nil {
+					urlResult.Status = resp.Status
+					urlResult.Length = resp.Length
+					urlResult.Body = resp.Body
+				}
+			}
+			p.Result <- urlResult
+		} else {
+			body := endpoint.Body
+			if body == "" {
+				body = paramsBody(absUrl, endpoint.Params)
+			}
+			if !results.Duplicate.Crawler(endpoint.Method+absUrl+body, p.TaskId) {
+				continue
+			}
+			p.Result <- types.CrawlerResult{
+				Url:        absUrl,
+				Method:     endpoint.Method,
+				Body:       body,
+				Time:       utils.Tools.GetTimeNow(),
+				RootDomain: rootDomain,
+				Tags:       []string{"js", endpoint.Context},
+				Source:     data.Output,
+			}
+		}
+	}
+	if len(extraction.GraphQL) != 0 {
+		if graphqlEndpoint == "" {
+			if u, err := url.Parse(data.Output); err == nil {
+				graphqlEndpoint = fmt.Sprintf("%v://%v/graphql", u.Scheme, u.Host)
+			}
+		}
+		for _, op := range extraction.GraphQL {
+			body, err := json.Marshal(map[string]string{"query": op.Query})
+			if err != nil || graphqlEndpoint == "" {
+				continue
+			}
+			if !results.Duplicate.Crawler("POST"+graphqlEndpoint+string(body), p.TaskId) {
+				continue
+			}
+			p.Result <- types.CrawlerResult{
+				Url:        graphqlEndpoint,
+				Method:     "POST",
+				Body:       string(body),
+				Time:       utils.Tools.GetTimeNow(),
+				RootDomain: rootDomain,
+				Tags:       []string{"js", "graphql", op.Type},
+				Source:     data.Output,
+			}
+		}
+	}
+	if len(extraction.Hosts) != 0 {
+		p.Log(fmt.Sprintf("%v hard-coded hosts: %v", data.Output, strings.Join(extraction.Hosts, ",")))
+	}
+	if secretFlag && len(extraction.Secrets) != 0 {
+		var matchList []string
+		for _, secret := range extraction.Secrets {
+			matchList = append(matchList, fmt.Sprintf("%v: %v", secret.Key, secret.Value))
+		}
+		tmpResult := types.SensitiveResult{
+			Url:      data.Output,
+			UrlId:    data.ResultId,
+			SID:      "JS Hardcoded Secret",
+			Match:    matchList,
+			Time:     utils.Tools.GetTimeNow(),
+			Color:    "red",
+			Md5:      bodyHash,
+			TaskName: p.TaskName,
+			Status:   1,
+		}
+		go results.Handler.Sensitive(&tmpResult)
+		results.Handler.SensitiveBody(data.Body, bodyHash)
+	}
+	return nil, nil
+}
+
+// resolveEndpoint 将提取到的路径转换为绝对地址，包含无法计算的表达式的路径不输出
+func resolveEndpoint(base string, endpoint string) (string, bool) {
+	pathPart := strings.SplitN(endpoint, "?", 2)[0]
+	if strings.Contains(pathPart, exprPlaceholder) {
+		return "", false
+	}
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false
+	}
+	abs := baseUrl.ResolveReference(ref)
+	if abs.Scheme != "http" && abs.Scheme != "https" {
+		return "", false
+	}
+	abs.Fragment = ""
+	return abs.String(), true
+}
+
+// withParams 将提取到的参数中url没有的参数以空值追加到查询字符串中
+func withParams(absUrl string, params []string) string {
+	if len(params) == 0 {
+		return absUrl
+	}
+	u, err := url.Parse(absUrl)
+	if err != nil {
+		return absUrl
+	}
+	query := u.Query()
+	added := false
+	for _, param := range params {
+		if param == "" || query.Has(param) {
+			continue
+		}
+		query.Set(param, "")
+		added = true
+	}
+	if !added {
+		return absUrl
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// paramsBody 没有提取到请求体时，使用url中没有的参数生成表单请求体
+func paramsBody(absUrl string, params []string) string {
+	var query url.Values
+	if u, err := url.Parse(absUrl); err == nil {
+		query = u.Query()
+	}
+	form := url.Values{}
+	for _, param := range params {
+		if param == "" || query.Has(param) {
+			continue
+		}
+		form.Set(param, "")
+	}
+	return form.Encode()
+}
+
+// mergeExtraction 合并source map中原始源码的分析结果
+func mergeExtraction(dst *Extraction, src *Extraction) {
+	dst.Endpoints = append(dst.Endpoints, src.Endpoints...)
+	dst.GraphQL = append(dst.GraphQL, src.GraphQL...)
+	for _, h := range src.Hosts {
+		if !slices.Contains(dst.Hosts, h) {
+			dst.Hosts = append(dst.Hosts, h)
+		}
+	}
+	for _, sec := range src.Secrets {
+		if !slices.Contains(dst.Secrets, sec) {
+			dst.Secrets = append(dst.Secrets, sec)
+		}
+	}
+}
+
+func (p *Plugin) Clone() interfaces.Plugin {
+	return &Plugin{
+		Name:     p.Name,
+		Module:   p.Module,
+		PluginId: p.PluginId,
+		Custom:   p.Custom,
+		TaskId:   p.TaskId,
+	}
+}
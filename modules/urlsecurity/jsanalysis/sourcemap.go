@@ -0,0 +1,111 @@
+// jsanalysis-------------------------------------
+// @file      : sourcemap.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/18 20:46
+// -------------------------------------------
+
+package jsanalysis
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net/url"
+	"strings"
+)
+
+// 单个map文件允许解包的最大源码数量，避免超大的bundle占用过多资源
+const maxSourceMapSources = 500
+
+// SourceFile source map 中还原出的原始源码
+type SourceFile struct {
+	Name    string
+	Content string
+}
+
+type sourceMap struct {
+	Version        int       `json:"version"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+// ResolveSourceMapURL 根据js地址计算sourceMappingURL的绝对地址，内联的data url原样返回
+func ResolveSourceMapURL(jsUrl string, ref string) (string, error) {
+	if strings.HasPrefix(ref, "data:") {
+		return ref, nil
+	}
+	base, err := url.Parse(jsUrl)
+	if err != nil {
+		return "", err
+	}
+	refUrl, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(refUrl).String(), nil
+}
+
+// FetchSourceMap 获取并解包source map，返回其中包含的原始源码
+func FetchSourceMap(mapUrl string) ([]SourceFile, error) {
+	var content []byte
+	if strings.HasPrefix(mapUrl, "data:") {
+		// data:application/json;base64,xxxx
+		idx := strings.Index(mapUrl, ",")
+		if idx == -1 {
+			return nil, fmt.Errorf("invalid inline source map")
+		}
+		if strings.Contains(mapUrl[:idx], ";base64") {
+			decoded, err := base64.StdEncoding.DecodeString(mapUrl[idx+1:])
+			if err != nil {
+				return nil, err
+			}
+			content = decoded
+		} else {
+			unescaped, err := url.PathUnescape(mapUrl[idx+1:])
+			if err != nil {
+				return nil, err
+			}
+			content = []byte(unescaped)
+		}
+	} else {
+		resp, err := utils.Requests.HttpGet(mapUrl)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("source map %v status code %v", mapUrl, resp.StatusCode)
+		}
+		content = []byte(resp.Body)
+	}
+	return ParseSourceMap(content)
+}
+
+// ParseSourceMap 解析source map内容
+func ParseSourceMap(content []byte) ([]SourceFile, error) {
+	// 部分map文件以 )]}' 开头防止xssi
+	body := strings.TrimSpace(string(content))
+	if strings.HasPrefix(body, ")]}'") {
+		body = body[strings.Index(body, "\n")+1:]
+	}
+	var sm sourceMap
+	if err := json.Unmarshal([]byte(body), &sm); err != nil {
+		return nil, err
+	}
+	var files []SourceFile
+	for i, name := range sm.Sources {
+		if i >= len(sm.SourcesContent) || i >= maxSourceMapSources {
+			break
+		}
+		if sm.SourcesContent[i] == nil || *sm.SourcesContent[i] == "" {
+			continue
+		}
+		// 第三方依赖不做分析
+		if strings.Contains(name, "node_modules/") || strings.Contains(name, "webpack/bootstrap") {
+			continue
+		}
+		files = append(files, SourceFile{Name: name, Content: *sm.SourcesContent[i]})
+	}
+	return files, nil
+}
@@ -15,8 +15,12 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/options"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/plugins"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/pool"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/tracing"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"strings"
 	"sync"
 	"time"
 )
@@ -54,13 +58,23 @@ func (r *Runner) ModuleRun() error {
 		defer resultWg.Done()
 		for {
 			select {
-			case _, ok := <-resultChan:
+			case result, ok := <-resultChan:
 				if !ok {
 					// 如果 resultChan 关闭了，退出循环
 					// 此模块运行完毕，关闭下个模块的输入
 					r.NextModule.CloseInput()
 					return
 				}
+				// 插件（如jsanalysis）从js中提取出的新接口，存入数据库并发送到下个模块
+				// 该结果已经在插件中进行去重，任务信息在 runPlugins 中补充
+				switch res := result.(type) {
+				case types.UrlResult:
+					go results.Handler.URL(&res)
+					r.NextModule.GetInput() <- res
+				case types.CrawlerResult:
+					go results.Handler.Crawler(&res)
+					r.NextModule.GetInput() <- res
+				}
 			}
 		}
 	}()
@@ -71,6 +85,8 @@ func (r *Runner) ModuleRun() error {
 	var end time.Time
 	doneCalled := false
 	for {
+		// 任务暂停时停止读取输入，恢复后继续
+		contextmanager.GlobalContextManagers.WaitIfPaused(r.Option.ID)
 		select {
 		case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
 			allPluginWg.Wait()
@@ -122,55 +138,103 @@ func (r *Runner) ModuleRun() error {
 			allPluginWg.Add(1)
 			go func(data interface{}) {
 				defer allPluginWg.Done()
-				if len(r.Option.URLSecurity) != 0 {
-					// 调用插件
-					for _, pluginId := range r.Option.URLSecurity {
-						//var plgWg sync.WaitGroup
-						var plgWg sync.WaitGroup
-						plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
-						if flag {
-							//logger.SlogDebugLocal(fmt.Sprintf("%v plugin start execute", plg.GetName()))
-							plgWg.Add(1)
-							args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
-							if argsFlag {
-								plg.SetParameter(args)
-							} else {
-								plg.SetParameter("")
-							}
-							plg.SetResult(resultChan)
-							plg.SetTaskId(r.Option.ID)
-							plg.SetTaskName(r.Option.TaskName)
-							pluginFunc := func(data interface{}) func() {
-								return func() {
-									defer plgWg.Done()
-									select {
-									case <-contextmanager.GlobalContextManagers.GetContext(r.Option.ID).Done():
-										return
-									default:
-										_, err := plg.Execute(data)
-										if err != nil {
-										}
-									}
-								}
-							}(data)
-							err := pool.PoolManage.SubmitTask(r.GetName(), pluginFunc)
-							if err != nil {
-								plgWg.Done()
-								logger.SlogError(fmt.Sprintf("task pool error: %v", err))
-							}
-							plgWg.Wait()
-							//logger.SlogDebugLocal(fmt.Sprintf("%v plugin end execute", plg.GetName()))
-						} else {
-							logger.SlogError(fmt.Sprintf("plugin %v not found", pluginId))
-						}
-					}
+				if len(r.Option.URLSecurity) == 0 {
+					return
+				}
+				// 插件从js中提取出的新接口同样经过本模块的插件检测，只检测一层，避免循环
+				for _, derived := range r.runPlugins(data, resultChan, true) {
+					r.runPlugins(derived, resultChan, false)
 				}
-
 			}(data)
 		}
 	}
 }
 
+// runPlugins 使用本模块的插件处理一条数据，插件的结果发送到 resultChan
+// collect 为true时返回插件产生的新的url和爬虫结果
+func (r *Runner) runPlugins(data interface{}, resultChan chan interface{}, collect bool) []interface{} {
+	// 模块处理单条数据的时间预算，用尽后跳过剩余的插件
+	moduleCtx, moduleCancel := contextmanager.GlobalContextManagers.ModuleContext(r.Option.ID, r.Option.ModuleTimeout(r.GetName()))
+	defer moduleCancel()
+	// 追踪此数据在模块中的处理
+	moduleCtx, moduleSpan := tracing.StartItem(moduleCtx, r.Option.TraceCtx, r.GetName(), data)
+	defer moduleSpan.End()
+	// 插件的结果先补充任务信息再发送到结果处理，重新检测的数据与入库的数据使用相同的结果id
+	var derived []interface{}
+	out := make(chan interface{}, 100)
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		for result := range out {
+			switch res := result.(type) {
+			case types.UrlResult:
+				res.TaskName = r.Option.TaskName
+				res.ResultId = utils.Tools.GenerateHash()
+				if strings.Contains(res.Output, "api") {
+					res.Tags = append(res.Tags, "api")
+				}
+				result = res
+			case types.CrawlerResult:
+				res.TaskName = r.Option.TaskName
+				res.ResultId = utils.Tools.GenerateHash()
+				result = res
+			}
+			if collect {
+				derived = append(derived, result)
+			}
+			resultChan <- result
+		}
+	}()
+	for _, pluginId := range r.Option.URLSecurity {
+		var plgWg sync.WaitGroup
+		plg, flag := plugins.GlobalPluginManager.GetPlugin(r.GetName(), pluginId)
+		if !flag {
+			logger.SlogError(fmt.Sprintf("plugin %v not found", pluginId))
+			continue
+		}
+		if contextmanager.BudgetExhausted(moduleCtx) {
+			handler.TaskHandle.ProgressSkip(r.GetName(), r.Option.Target, r.Option.ID, plg.GetName())
+			tracing.Skip(moduleCtx, r.GetName(), "module time budget exhausted: "+plg.GetName(), data)
+			continue
+		}
+		plgWg.Add(1)
+		args, argsFlag := utils.Tools.GetParameter(r.Option.Parameters, r.GetName(), plg.GetPluginId())
+		if argsFlag {
+			plg.SetParameter(args)
+		} else {
+			plg.SetParameter("")
+		}
+		plg.SetResult(out)
+		plg.SetTaskId(r.Option.ID)
+		plg.SetTaskName(r.Option.TaskName)
+		if cp, ok := plg.(interfaces.ContextPlugin); ok {
+			cp.SetContext(moduleCtx)
+		}
+		pluginFunc := func(data interface{}) func() {
+			return func() {
+				defer plgWg.Done()
+				select {
+				case <-moduleCtx.Done():
+					return
+				default:
+					_, err := plg.Execute(data)
+					if err != nil {
+					}
+				}
+			}
+		}(data)
+		err := pool.PoolManage.SubmitTaskFor(r.Option.ID, r.GetName(), pluginFunc)
+		if err != nil {
+			plgWg.Done()
+			logger.SlogError(fmt.Sprintf("task pool error: %v", err))
+		}
+		plgWg.Wait()
+	}
+	close(out)
+	<-forwardDone
+	return derived
+}
+
 func (r *Runner) SetInput(ch chan interface{}) {
 	r.Input = ch
 }
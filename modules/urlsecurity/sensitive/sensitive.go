@@ -8,6 +8,7 @@
 package sensitive
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
@@ -32,6 +33,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -50,6 +52,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -65,7 +71,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -182,12 +188,13 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	// 检查body是否在当前任务已经检测过
 	respMd5 := utils.Tools.HashXX64String(data.Body)
 	duplicateFlag := results.Duplicate.SensitiveBody(respMd5, p.TaskId, "sens")
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	if duplicateFlag {
 		pdfCheck := false
+		verify := false
 		parameter := p.GetParameter()
 		if parameter != "" {
-			args, err := utils.Tools.ParseArgs(parameter, "pdf")
+			args, err := utils.Tools.ParseArgs(parameter, "pdf", "verify")
 			if err != nil {
 			} else {
 				for key, value := range args {
@@ -197,6 +204,11 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 							if value == "true" {
 								pdfCheck = true
 							}
+						case "verify":
+							// 在线验证支持的密钥类型
+							if value == "true" {
+								verify = true
+							}
 						default:
 							continue
 						}
@@ -217,6 +229,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 				}
 			}
 		}
+		scanTime := utils.Tools.GetTimeNow()
 		chunkSize := 5120
 		overlapSize := 100
 		chunkChan := GenerateChunks(data.Body, chunkSize, overlapSize)
@@ -245,6 +258,7 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 				}
 			}
 		}
+		var found []string
 		if len(collector.matchMap) > 0 {
 			for ruleName, matchList := range collector.GetAll() {
 				color, exists := matchColorMap[ruleName]
@@ -261,11 +275,28 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 					Md5:      respMd5,
 					TaskName: p.TaskName,
 					Status:   1,
+					Verify:   verify,
 				}
+				tmpResult.Fingerprint = results.SensitiveFingerprint(ruleName, data.Output)
+				found = append(found, tmpResult.Fingerprint)
 				go results.Handler.Sensitive(&tmpResult)
 			}
 			results.Handler.SensitiveBody(data.Body, respMd5)
 		}
+		// 关闭该URL上本次未复现的敏感信息
+		var rules []string
+		for _, rule := range global.SensitiveRules {
+			if rule.State && rule.RuleCompile != nil {
+				rules = append(rules, rule.Name)
+			}
+		}
+		results.Handler.SensitiveClose(&types.FindingClose{
+			TaskName: p.TaskName,
+			Url:      data.Output,
+			Rules:    rules,
+			Keep:     found,
+			Before:   scanTime,
+		})
 	}
 	//end = time.Now()
 	//duration := end.Sub(start)
@@ -0,0 +1,66 @@
+// nuclei-------------------------------------
+// @file      : auth.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 15:10
+// -------------------------------------------
+
+package nuclei
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
+	"github.com/projectdiscovery/nuclei/v3/pkg/authprovider/authx"
+	"github.com/projectdiscovery/retryablehttp-go"
+	urlutil "github.com/projectdiscovery/utils/url"
+	"net/http"
+	"net/url"
+)
+
+// authProvider 将任务的认证配置提供给nuclei，在每个请求发送前注入认证请求头
+type authProvider struct {
+	taskId string
+}
+
+func (a *authProvider) lookup(rawUrl string) []authx.AuthStrategy {
+	if len(authmanager.GlobalAuthManager.HeaderMap(a.taskId, rawUrl)) == 0 {
+		return nil
+	}
+	return []authx.AuthStrategy{&authStrategy{taskId: a.taskId}}
+}
+
+func (a *authProvider) LookupAddr(addr string) []authx.AuthStrategy {
+	return a.lookup(addr)
+}
+
+func (a *authProvider) LookupURL(u *url.URL) []authx.AuthStrategy {
+	return a.lookup(u.String())
+}
+
+func (a *authProvider) LookupURLX(u *urlutil.URL) []authx.AuthStrategy {
+	return a.lookup(u.String())
+}
+
+func (a *authProvider) GetTemplatePaths() []string {
+	return nil
+}
+
+func (a *authProvider) PreFetchSecrets() error {
+	return nil
+}
+
+// authStrategy 请求发送时获取当前有效的认证信息，保证会话刷新后使用新的token
+type authStrategy struct {
+	taskId string
+}
+
+func (s *authStrategy) Apply(req *http.Request) {
+	for k, v := range authmanager.GlobalAuthManager.HeaderMap(s.taskId, req.URL.String()) {
+		req.Header.Set(k, v)
+	}
+}
+
+func (s *authStrategy) ApplyOnRR(req *retryablehttp.Request) {
+	for k, v := range authmanager.GlobalAuthManager.HeaderMap(s.taskId, req.URL.String()) {
+		req.Header.Set(k, v)
+	}
+}
@@ -0,0 +1,50 @@
+// nuclei-------------------------------------
+// @file      : fuzz.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/27 10:20
+// -------------------------------------------
+
+package nuclei
+
+import (
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	inputtypes "github.com/projectdiscovery/nuclei/v3/pkg/input/types"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// crawlerRequests 将爬虫和接口文档展开的请求转换为nuclei的模糊测试输入，保留请求方法、请求头和请求体
+func crawlerRequests(crawlerResults []types.CrawlerResult) []*inputtypes.RequestResponse {
+	var requests []*inputtypes.RequestResponse
+	seen := make(map[string]struct{})
+	for _, c := range crawlerResults {
+		method := strings.ToUpper(c.Method)
+		if method == "" {
+			method = http.MethodGet
+		}
+		key := method + c.Url + c.Body
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		req, err := http.NewRequest(method, c.Url, strings.NewReader(c.Body))
+		if err != nil {
+			continue
+		}
+		for k, v := range c.Headers {
+			req.Header.Set(k, v)
+		}
+		raw, err := httputil.DumpRequest(req, true)
+		if err != nil {
+			continue
+		}
+		rr, err := inputtypes.ParseRawRequestWithURL(string(raw), c.Url)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, rr)
+	}
+	return requests
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/handler"
@@ -21,10 +22,13 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
 	nuclei "github.com/projectdiscovery/nuclei/v3/lib"
 	"github.com/projectdiscovery/nuclei/v3/pkg/catalog/config"
+	inputtypes "github.com/projectdiscovery/nuclei/v3/pkg/input/types"
 	"github.com/projectdiscovery/nuclei/v3/pkg/output"
+	nucleiTemplates "github.com/projectdiscovery/nuclei/v3/pkg/templates"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,6 +41,7 @@ type Plugin struct {
 	Custom    interface{}
 	TaskId    string
 	TaskName  string
+	Ctx       context.Context
 }
 
 func NewPlugin() *Plugin {
@@ -55,6 +60,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -122,7 +131,7 @@ func (p *Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 
 func (p *Plugin) Execute(input interface{}) (interface{}, error) {
@@ -130,6 +139,8 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	var tmplateFilters nuclei.TemplateFilters
 	var targets []string
 	var tmpTags []string
+	// 爬虫和接口文档展开的请求，使用DAST模板进行模糊测试
+	var requests []*inputtypes.RequestResponse
 	switch a := input.(type) {
 	case []types.AssetOther:
 		for _, assetOther := range a {
@@ -143,11 +154,24 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 		}
 		// 如果是http则限制为http的poc
 		tmplateFilters.ProtocolTypes = "http"
+	case []types.CrawlerResult:
+		requests = crawlerRequests(a)
+		if len(requests) == 0 {
+			return nil, nil
+		}
+		tmplateFilters.ProtocolTypes = "http"
+	case types.CrawlerResult:
+		// 单条请求在模块中会再以批量的形式发送，这里只处理批量的请求
+		return nil, nil
 	default:
-		return nil, errors.New("input is not AssetHttp, AssetOther")
+		return nil, errors.New("input is not AssetHttp, AssetOther, CrawlerResult")
 	}
 	targets = utils.Tools.RemoveStringDuplicates(targets)
-	p.Log(fmt.Sprintf("target %v start", targets))
+	if len(requests) != 0 {
+		p.Log(fmt.Sprintf("fuzz %v requests start", len(requests)))
+	} else {
+		p.Log(fmt.Sprintf("target %v start", targets))
+	}
 	start := time.Now()
 	parameter := p.GetParameter()
 	var templates []string
@@ -161,8 +185,10 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	concurrency.JavascriptTemplateConcurrency = 80
 	concurrency.TemplatePayloadConcurrency = 15
 	concurrency.ProbeConcurrency = 5
+	// 默认对收到的请求进行模糊测试
+	fuzzFlag := true
 	if parameter != "" {
-		args, err := utils.Tools.ParseArgs(parameter, "t", "s", "es", "tags", "etags", "rl", "rld", "bs", "c", "hbs", "headc", "jsc", "pc", "prc", "as", "InteractshURL")
+		args, err := utils.Tools.ParseArgs(parameter, "t", "s", "es", "tags", "etags", "rl", "rld", "bs", "c", "hbs", "headc", "jsc", "pc", "prc", "as", "InteractshURL", "fuzz")
 		if err != nil {
 		} else {
 			for key, value := range args {
@@ -210,6 +236,8 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 						concurrency.ProbeConcurrency = prcValue
 					case "as":
 						tmplateFilters.Tags = append(tmplateFilters.Tags, tmpTags...)
+					case "fuzz":
+						fuzzFlag = value != "false"
 					//case "InteractshURL":
 					//	options = append(options, nuclei.WithInteractshOptions(nuclei.InteractshOpts(interactsh.Options{
 					//		ServerURL: value,
@@ -221,6 +249,13 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 			}
 		}
 	}
+	if len(requests) != 0 {
+		if !fuzzFlag {
+			return nil, nil
+		}
+		// 只运行模糊测试模板，在请求的参数、请求头和请求体上注入
+		options = append(options, nuclei.DASTMode(), nuclei.WithHttpRequests(requests))
+	}
 	// 全局速率限制
 	options = append(options, nuclei.WithGlobalRateLimitCtx(context.Background(), maxTokens, duration))
 
@@ -234,7 +269,23 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 
 	// TmplateFilters 模板过滤
 	options = append(options, nuclei.WithTemplateFilters(tmplateFilters))
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+
+	// 认证扫描
+	if authmanager.GlobalAuthManager.HasProfiles(p.GetTaskId()) {
+		options = append(options, nuclei.WithAuthProvider(&authProvider{taskId: p.GetTaskId()}))
+	}
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
+	// 本次扫描发现的漏洞指纹，扫描结束后关闭这些目标上未复现的漏洞
+	scanTime := utils.Tools.GetTimeNow()
+	var foundMu sync.Mutex
+	var found []string
+	// 本次扫描运行的模板，只关闭这些模板的漏洞
+	var vulnIds []string
+	options = append(options, nuclei.WithTemplatesLoadedCallback(func(tpls []*nucleiTemplates.Template) {
+		for _, tpl := range tpls {
+			vulnIds = append(vulnIds, strings.TrimSuffix(filepath.Base(tpl.Path), ".yaml"))
+		}
+	}))
 	callBackFunc := func(event *output.ResultEvent) {
 		vulName := event.Info.Name
 		level := event.Info.SeverityHolder.Severity.String()
@@ -246,17 +297,23 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 			url = event.Host + event.Port
 		}
 		tmpResult := types.VulnResult{
-			Url:      url,
-			VulnId:   strings.TrimSuffix(filepath.Base(event.TemplatePath), ".yaml"),
-			VulName:  vulName,
-			Matched:  event.Matched,
-			Level:    level,
-			Time:     utils.Tools.GetTimeNow(),
-			Request:  event.Request,
-			Response: event.Response,
+			Url:         url,
+			VulnId:      strings.TrimSuffix(filepath.Base(event.TemplatePath), ".yaml"),
+			VulName:     vulName,
+			Matched:     event.Matched,
+			Level:       level,
+			Time:        utils.Tools.GetTimeNow(),
+			Request:     event.Request,
+			Response:    event.Response,
+			MatcherName: event.MatcherName,
+			Source:      p.Name,
 		}
 		tmpResult.TaskName = p.TaskName
 		tmpResult.Status = 1
+		tmpResult.Fingerprint = results.VulnFingerprint(tmpResult.VulnId, tmpResult.Url, tmpResult.MatcherName)
+		foundMu.Lock()
+		found = append(found, tmpResult.Fingerprint)
+		foundMu.Unlock()
 		go results.Handler.Vulnerability(&tmpResult)
 	}
 
@@ -268,6 +325,25 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	err := ne.ExecuteNucleiWithOptsCtx(ctx, targets, options...)
 	if err != nil {
 		p.Log(fmt.Sprintf("Nuclei target %v to err: %s", targets, err), "e")
+	} else if ctx.Err() == nil && len(vulnIds) != 0 && len(requests) == 0 {
+		// 扫描完整结束才关闭未复现的漏洞，模糊测试的请求分批发送，不关闭
+		var hosts []string
+		for _, target := range targets {
+			if host := results.FindingHost(target); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		foundMu.Lock()
+		keep := append([]string(nil), found...)
+		foundMu.Unlock()
+		results.Handler.VulnerabilityClose(&types.FindingClose{
+			TaskName: p.TaskName,
+			Source:   p.Name,
+			Hosts:    utils.Tools.RemoveStringDuplicates(hosts),
+			VulnIds:  utils.Tools.RemoveStringDuplicates(vulnIds),
+			Keep:     keep,
+			Before:   scanTime,
+		})
 	}
 
 	//ne, err := nuclei.NewNucleiEngineCtx(context.Background(), options...)
@@ -0,0 +1,265 @@
+// apidiscovery-------------------------------------
+// @file      : apidiscovery.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 10:20
+// -------------------------------------------
+
+package apidiscovery
+
+import (
+	"context"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/results"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 默认探测的 OpenAPI/Swagger 文档路径
+var defaultSpecPaths = []string{
+	"/swagger.json",
+	"/swagger.yaml",
+	"/swagger/v1/swagger.json",
+	"/swagger/doc.json",
+	"/api/swagger.json",
+	"/v2/api-docs",
+	"/v3/api-docs",
+	"/api-docs",
+	"/openapi.json",
+	"/openapi.yaml",
+	"/api/openapi.json",
+}
+
+// 默认探测的 GraphQL 路径
+var defaultGraphQLPaths = []string{
+	"/graphql",
+	"/api/graphql",
+	"/v1/graphql",
+}
+
+type Plugin struct {
+	Name      string
+	Module    string
+	Parameter string
+	PluginId  string
+	Result    chan interface{}
+	Custom    interface{}
+	TaskId    string
+	TaskName  string
+	Ctx       context.Context
+}
+
+func NewPlugin() *Plugin {
+	return &Plugin{
+		Name:     "apidiscovery",
+		Module:   "WebCrawler",
+		PluginId: "4a9e901fca30ec7c40e01b1e86324dbd",
+	}
+}
+
+func (p *Plugin) SetTaskName(name string) {
+	p.TaskName = name
+}
+
+func (p *Plugin) GetTaskName() string {
+	return p.TaskName
+}
+
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
+func (p *Plugin) SetTaskId(id string) {
+	p.TaskId = id
+}
+
+func (p *Plugin) GetTaskId() string {
+	return p.TaskId
+}
+
+func (p *Plugin) Log(msg string, tp ...string) {
+	var logTp string
+	if len(tp) > 0 {
+		logTp = tp[0] // 使用传入的参数
+	} else {
+		logTp = "i"
+	}
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
+}
+func (p *Plugin) SetCustom(cu interface{}) {
+	p.Custom = cu
+}
+
+func (p *Plugin) GetCustom() interface{} {
+	return p.Custom
+}
+
+func (p *Plugin) SetPluginId(id string) {
+	p.PluginId = id
+}
+
+func (p *Plugin) GetPluginId() string {
+	return p.PluginId
+}
+
+func (p *Plugin) SetResult(ch chan interface{}) {
+	p.Result = ch
+}
+
+func (p *Plugin) SetName(name string) {
+	p.Name = name
+}
+
+func (p *Plugin) GetName() string {
+	return p.Name
+}
+
+func (p *Plugin) SetModule(module string) {
+	p.Module = module
+}
+
+func (p *Plugin) GetModule() string {
+	return p.Module
+}
+
+func (p *Plugin) Install() error {
+	return nil
+}
+
+func (p *Plugin) Check() error {
+	return nil
+}
+func (p *Plugin) UnInstall() error {
+	return nil
+}
+func (p *Plugin) SetParameter(args string) {
+	p.Parameter = args
+}
+
+func (p *Plugin) GetParameter() string {
+	return p.Parameter
+}
+
+func (p *Plugin) Execute(input interface{}) (interface{}, error) {
+	data, ok := input.(types.AssetHttp)
+	if !ok {
+		return nil, nil
+	}
+	baseUrl, err := url.Parse(data.URL)
+	if err != nil || baseUrl.Host == "" {
+		return nil, nil
+	}
+	base := fmt.Sprintf("%v://%v", baseUrl.Scheme, baseUrl.Host)
+	// 同一个站点在当前任务中只探测一次
+	if !results.Duplicate.DuplicateLocalCache("duplicates:" + p.TaskId + ":apidiscovery:" + base) {
+		return nil, nil
+	}
+	specPaths := defaultSpecPaths
+	graphqlPaths := defaultGraphQLPaths
+	graphqlFlag := true
+	// 单个文档最多展开的请求数量
+	maxOperations := 2000
+	parameter := p.GetParameter()
+	if parameter != "" {
+		args, err := utils.Tools.ParseArgs(parameter, "paths", "gpaths", "graphql", "max")
+		if err != nil {
+		} else {
+			for key, value := range args {
+				if value != "" {
+					switch key {
+					case "paths":
+						specPaths = strings.Split(value, ",")
+					case "gpaths":
+						graphqlPaths = strings.Split(value, ",")
+					case "graphql":
+						graphqlFlag = value != "false"
+					case "max":
+						maxOperations, _ = strconv.Atoi(value)
+					default:
+						continue
+					}
+				}
+			}
+		}
+	}
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
+	rootDomain, _ := utils.Tools.GetRootDomain(data.URL)
+	for _, specPath := range specPaths {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+		}
+		specUrl := base + "/" + strings.TrimPrefix(strings.TrimSpace(specPath), "/")
+		resp, err := utils.Requests.HttpGet(specUrl)
+		if err != nil || resp.StatusCode != 200 || resp.Body == "" {
+			continue
+		}
+		requests, err := ParseSpec([]byte(resp.Body), base)
+		if err != nil {
+			if err != ErrNotSpec {
+				p.Log(fmt.Sprintf("%v parse api document error: %v", specUrl, err), "w")
+			}
+			continue
+		}
+		p.Log(fmt.Sprintf("found api document %v, %v operations", specUrl, len(requests)))
+		p.emit(requests, specUrl, rootDomain, maxOperations, "openapi")
+	}
+	if !graphqlFlag {
+		return nil, nil
+	}
+	for _, graphqlPath := range graphqlPaths {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+		}
+		endpoint := base + "/" + strings.TrimPrefix(strings.TrimSpace(graphqlPath), "/")
+		requests, err := IntrospectGraphQL(endpoint)
+		if err != nil {
+			continue
+		}
+		p.Log(fmt.Sprintf("found graphql introspection %v, %v operations", endpoint, len(requests)))
+		p.emit(requests, endpoint, rootDomain, maxOperations, "graphql")
+	}
+	return nil, nil
+}
+
+// emit 将展开的接口请求发送到结果通道
+func (p *Plugin) emit(requests []Request, source string, rootDomain string, max int, tag string) {
+	for i, req := range requests {
+		if max > 0 && i >= max {
+			p.Log(fmt.Sprintf("%v operations exceed %v, the rest are ignored", source, max), "w")
+			return
+		}
+		if !results.Duplicate.Crawler(req.Method+req.Url+req.Body, p.TaskId) {
+			continue
+		}
+		p.Result <- types.CrawlerResult{
+			Url:        req.Url,
+			Method:     req.Method,
+			Body:       req.Body,
+			Headers:    req.Headers,
+			RootDomain: rootDomain,
+			Time:       utils.Tools.GetTimeNow(),
+			Tags:       []string{"api", tag},
+			Source:     source,
+		}
+	}
+}
+
+func (p *Plugin) Clone() interfaces.Plugin {
+	return &Plugin{
+		Name:     p.Name,
+		Module:   p.Module,
+		PluginId: p.PluginId,
+		Custom:   p.Custom,
+		TaskId:   p.TaskId,
+	}
+}
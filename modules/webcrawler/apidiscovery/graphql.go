@@ -0,0 +1,191 @@
+// apidiscovery-------------------------------------
+// @file      : graphql.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 11:15
+// -------------------------------------------
+
+package apidiscovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"sort"
+	"strings"
+)
+
+// 精简的内省查询，只获取生成请求所需要的字段
+const introspectionQuery = `query IntrospectionQuery { __schema { queryType { name } mutationType { name } types { kind name fields(includeDeprecated: true) { name args { name type { ...TypeRef } } type { ...TypeRef } } } } } fragment TypeRef on __Type { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }`
+
+type gqlTypeRef struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	OfType *gqlTypeRef `json:"ofType"`
+}
+
+type gqlField struct {
+	Name string `json:"name"`
+	Args []struct {
+		Name string     `json:"name"`
+		Type gqlTypeRef `json:"type"`
+	} `json:"args"`
+	Type gqlTypeRef `json:"type"`
+}
+
+type gqlType struct {
+	Kind   string     `json:"kind"`
+	Name   string     `json:"name"`
+	Fields []gqlField `json:"fields"`
+}
+
+type gqlSchema struct {
+	Data struct {
+		Schema struct {
+			QueryType *struct {
+				Name string `json:"name"`
+			} `json:"queryType"`
+			MutationType *struct {
+				Name string `json:"name"`
+			} `json:"mutationType"`
+			Types []gqlType `json:"types"`
+		} `json:"__schema"`
+	} `json:"data"`
+}
+
+// IntrospectGraphQL 对GraphQL接口发送内省查询，并将每个query/mutation展开为请求
+func IntrospectGraphQL(endpoint string) ([]Request, error) {
+	body, _ := json.Marshal(map[string]string{"query": introspectionQuery})
+	err, resp := utils.Requests.HttpPost(endpoint, body, "json")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %v", resp.StatusCode)
+	}
+	return ParseIntrospection(endpoint, resp.Body)
+}
+
+// ParseIntrospection 解析内省查询结果
+func ParseIntrospection(endpoint string, content []byte) ([]Request, error) {
+	var schema gqlSchema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, err
+	}
+	s := schema.Data.Schema
+	if s.QueryType == nil && s.MutationType == nil {
+		return nil, errors.New("introspection disabled")
+	}
+	types := make(map[string]gqlType, len(s.Types))
+	for _, t := range s.Types {
+		types[t.Name] = t
+	}
+	var requests []Request
+	build := func(opType string, typeName string) {
+		t, ok := types[typeName]
+		if !ok {
+			return
+		}
+		sort.Slice(t.Fields, func(i, j int) bool { return t.Fields[i].Name < t.Fields[j].Name })
+		for _, field := range t.Fields {
+			query, variables := buildOperation(opType, field)
+			payload, err := json.Marshal(map[string]interface{}{
+				"query":     query,
+				"variables": variables,
+			})
+			if err != nil {
+				continue
+			}
+			requests = append(requests, Request{
+				Method:  "POST",
+				Url:     endpoint,
+				Body:    string(payload),
+				Headers: map[string]string{"Content-Type": "application/json"},
+			})
+		}
+	}
+	if s.QueryType != nil {
+		build("query", s.QueryType.Name)
+	}
+	if s.MutationType != nil {
+		build("mutation", s.MutationType.Name)
+	}
+	return requests, nil
+}
+
+// buildOperation 生成单个字段的操作语句，参数通过variables传入
+func buildOperation(opType string, field gqlField) (string, map[string]interface{}) {
+	variables := make(map[string]interface{})
+	var defs []string
+	var args []string
+	for _, arg := range field.Args {
+		defs = append(defs, fmt.Sprintf("$%v: %v", arg.Name, typeString(arg.Type)))
+		args = append(args, fmt.Sprintf("%v: $%v", arg.Name, arg.Name))
+		variables[arg.Name] = exampleValue(arg.Type)
+	}
+	var sb strings.Builder
+	sb.WriteString(opType)
+	sb.WriteString(" ")
+	sb.WriteString(field.Name)
+	if len(defs) > 0 {
+		sb.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	sb.WriteString(" { ")
+	sb.WriteString(field.Name)
+	if len(args) > 0 {
+		sb.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	// 返回值为对象时只查询__typename，保证语句合法
+	switch baseType(field.Type).Kind {
+	case "OBJECT", "INTERFACE", "UNION":
+		sb.WriteString(" { __typename }")
+	}
+	sb.WriteString(" }")
+	return sb.String(), variables
+}
+
+func baseType(t gqlTypeRef) gqlTypeRef {
+	for t.OfType != nil && (t.Kind == "NON_NULL" || t.Kind == "LIST") {
+		t = *t.OfType
+	}
+	return t
+}
+
+func typeString(t gqlTypeRef) string {
+	switch t.Kind {
+	case "NON_NULL":
+		if t.OfType != nil {
+			return typeString(*t.OfType) + "!"
+		}
+	case "LIST":
+		if t.OfType != nil {
+			return "[" + typeString(*t.OfType) + "]"
+		}
+	}
+	return t.Name
+}
+
+func exampleValue(t gqlTypeRef) interface{} {
+	if t.Kind == "LIST" && t.OfType != nil {
+		return []interface{}{exampleValue(*t.OfType)}
+	}
+	t = baseType(t)
+	switch t.Name {
+	case "Int":
+		return 1
+	case "Float":
+		return 1.0
+	case "Boolean":
+		return true
+	case "ID":
+		return "1"
+	case "String":
+		return "test"
+	}
+	// 枚举和输入对象无法确定具体值，使用空值由服务端返回错误信息
+	if t.Kind == "INPUT_OBJECT" {
+		return map[string]interface{}{}
+	}
+	return nil
+}
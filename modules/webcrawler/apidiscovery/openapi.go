@@ -0,0 +1,140 @@
+// apidiscovery-------------------------------------
+// @file      : openapi.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 10:48
+// -------------------------------------------
+
+package apidiscovery
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	"github.com/projectdiscovery/nuclei/v3/pkg/input/formats"
+	"github.com/projectdiscovery/nuclei/v3/pkg/input/formats/openapi"
+	inputtypes "github.com/projectdiscovery/nuclei/v3/pkg/input/types"
+	"net/url"
+	"strings"
+)
+
+// ErrNotSpec 内容不是 OpenAPI/Swagger 文档
+var ErrNotSpec = errors.New("not an openapi or swagger document")
+
+// 文档中需要认证的参数在没有配置值时使用的占位符
+const authPlaceholder = "ScopeSentry"
+
+// Request 从接口文档中展开的具体请求
+type Request struct {
+	Method  string
+	Url     string
+	Body    string
+	Headers map[string]string
+}
+
+// transportHeaders 由发送请求时自动生成的请求头，不从文档中保留
+var transportHeaders = map[string]struct{}{
+	"host":            {},
+	"content-length":  {},
+	"user-agent":      {},
+	"accept-encoding": {},
+}
+
+// ParseSpec 解析 OpenAPI 3.x 或 Swagger 2.0 文档（json/yaml），展开其中的每个操作
+// 文档中声明的servers统一替换为目标站点，避免请求发送到范围外的地址
+func ParseSpec(content []byte, base string) ([]Request, error) {
+	var probe map[string]interface{}
+	if err := yaml.Unmarshal(content, &probe); err != nil {
+		return nil, ErrNotSpec
+	}
+	if _, ok := probe["paths"]; !ok {
+		return nil, ErrNotSpec
+	}
+	var schema *openapi3.T
+	loader := openapi3.NewLoader()
+	if v, ok := probe["swagger"]; ok && strings.HasPrefix(fmt.Sprint(v), "2") {
+		var schemaV2 openapi2.T
+		if err := yaml.Unmarshal(content, &schemaV2); err != nil {
+			return nil, err
+		}
+		converted, err := openapi2conv.ToV3(&schemaV2)
+		if err != nil {
+			return nil, err
+		}
+		if err := loader.ResolveRefsIn(converted, nil); err != nil {
+			return nil, err
+		}
+		schema = converted
+	} else if v, ok := probe["openapi"]; ok && strings.HasPrefix(fmt.Sprint(v), "3") {
+		loaded, err := loader.LoadFromData(content)
+		if err != nil {
+			return nil, err
+		}
+		schema = loaded
+	} else {
+		return nil, ErrNotSpec
+	}
+	rewriteServers(schema, base)
+
+	opts := formats.InputFormatOptions{
+		Variables:            make(map[string]interface{}),
+		SkipFormatValidation: true,
+	}
+	if len(schema.Security) > 0 {
+		params, err := openapi.GetGlobalParamsForSecurityRequirement(schema, &schema.Security)
+		if err == nil {
+			for _, param := range params {
+				opts.Variables[param.Value.Name] = authPlaceholder
+			}
+		}
+	}
+	var requests []Request
+	err := openapi.GenerateRequestsFromSchema(schema, opts, func(rr *inputtypes.RequestResponse) bool {
+		if rr.Request == nil {
+			return true
+		}
+		headers := make(map[string]string)
+		rr.Request.Headers.Iterate(func(key string, value string) bool {
+			if _, ok := transportHeaders[strings.ToLower(key)]; !ok {
+				headers[key] = value
+			}
+			return true
+		})
+		requests = append(requests, Request{
+			Method:  strings.ToUpper(rr.Request.Method),
+			Url:     rr.URL.String(),
+			Body:    rr.Request.Body,
+			Headers: headers,
+		})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// rewriteServers 保留servers中的路径，将协议和主机替换为目标站点
+func rewriteServers(schema *openapi3.T, base string) {
+	paths := make(map[string]struct{})
+	for _, server := range schema.Servers {
+		if server == nil {
+			continue
+		}
+		serverPath := ""
+		if u, err := url.Parse(server.URL); err == nil && !strings.Contains(server.URL, "{") {
+			serverPath = strings.TrimSuffix(u.Path, "/")
+		}
+		paths[serverPath] = struct{}{}
+	}
+	if len(paths) == 0 {
+		paths[""] = struct{}{}
+	}
+	schema.Servers = nil
+	for p := range paths {
+		schema.Servers = append(schema.Servers, &openapi3.Server{URL: base + p})
+	}
+}
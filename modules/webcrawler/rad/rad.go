@@ -8,10 +8,12 @@
 package rad
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/authmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/contextmanager"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/interfaces"
@@ -19,6 +21,8 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/utils"
+	"gopkg.in/yaml.v3"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -41,6 +45,7 @@ type Plugin struct {
 	RadDir      string
 	TaskId      string
 	TaskName    string
+	Ctx         context.Context
 }
 
 type Request struct {
@@ -82,6 +87,10 @@ func (p *Plugin) GetTaskName() string {
 	return p.TaskName
 }
 
+func (p *Plugin) SetContext(ctx context.Context) {
+	p.Ctx = ctx
+}
+
 func (p *Plugin) SetTaskId(id string) {
 	p.TaskId = id
 }
@@ -98,7 +107,7 @@ func (p Plugin) Log(msg string, tp ...string) {
 	} else {
 		logTp = "i"
 	}
-	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId())
+	logger.PluginsLog(fmt.Sprintf("[Plugins %v] %v", p.GetName(), msg), logTp, p.GetModule(), p.GetPluginId(), p.GetTaskId())
 }
 func (p *Plugin) SetCustom(cu interface{}) {
 	p.Custom = cu
@@ -222,12 +231,22 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 			}
 		}
 	}
+	// 携带任务配置的认证信息，生成本次运行使用的临时配置文件
+	if headers := authmanager.GlobalAuthManager.HeaderMap(p.GetTaskId(), data.Host); len(headers) != 0 {
+		authConfigPath, err := writeAuthConfig(radConfigPath, data.Host, headers)
+		if err != nil {
+			p.Log(fmt.Sprintf("write rad auth config error: %v", err), "w")
+		} else {
+			radConfigPath = authConfigPath
+			defer utils.Tools.DeleteFile(authConfigPath)
+		}
+	}
 	args := []string{"--url-file", data.Filepath, "--json", resultPath, "--config", radConfigPath}
 	if proxy != "" {
 		args = append(args, "-http-proxy")
 		args = append(args, proxy)
 	}
-	ctx := contextmanager.GlobalContextManagers.GetContext(p.GetTaskId())
+	ctx := contextmanager.GlobalContextManagers.PluginContext(p.Ctx, p.GetTaskId())
 	err := utils.Tools.ExecuteCommandWithTimeout(filepath.Join(filepath.Join(global.ExtDir, "rad"), p.RadFileName), args, time.Duration(executionTimeout)*time.Minute, ctx)
 	if err != nil {
 		logger.SlogError(fmt.Sprintf("%v ExecuteCommandWithTimeout error: %v", p.GetName(), err))
@@ -348,6 +367,40 @@ func (p *Plugin) Execute(input interface{}) (interface{}, error) {
 	return nil, nil
 }
 
+// writeAuthConfig 在rad配置的 domain-headers 中追加目标主机的认证请求头，写入临时配置文件
+func writeAuthConfig(configPath string, host string, headers map[string]string) (string, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return "", err
+	}
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	domain := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		domain = h
+	}
+	domainHeaders, _ := config["domain-headers"].([]interface{})
+	// 认证头需要优先匹配，放在最前面
+	config["domain-headers"] = append([]interface{}{map[string]interface{}{
+		"domain":  domain,
+		"headers": headers,
+	}}, domainHeaders...)
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	authConfigPath := filepath.Join(filepath.Join(global.ExtDir, "rad"), "rad_config_"+utils.Tools.GenerateRandomString(8)+".yml")
+	if err := os.WriteFile(authConfigPath, out, 0644); err != nil {
+		return "", err
+	}
+	return authConfigPath, nil
+}
+
 func (p *Plugin) Clone() interfaces.Plugin {
 	return &Plugin{
 		Name:        p.Name,
@@ -0,0 +1,137 @@
+// logger-------------------------------------
+// @file      : level.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/24 10:40
+// -------------------------------------------
+
+package logger
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+)
+
+// 各模块的日志级别，模块的日志通过 ZapLog.Named(模块名称) 输出，没有配置的模块使用默认级别
+var levels = struct {
+	sync.RWMutex
+	def     zapcore.Level
+	modules map[string]zapcore.Level
+	min     zapcore.Level
+}{def: zapcore.InfoLevel, min: zapcore.InfoLevel}
+
+// SetLevels 修改默认日志级别和各模块的日志级别，运行中修改立即生效
+func SetLevels(def string, modules map[string]string) error {
+	defLevel := zapcore.InfoLevel
+	if def != "" {
+		l, err := zapcore.ParseLevel(def)
+		if err != nil {
+			return fmt.Errorf("log level %v: %v", def, err)
+		}
+		defLevel = l
+	}
+	moduleLevels := make(map[string]zapcore.Level, len(modules))
+	minLevel := defLevel
+	for module, level := range modules {
+		l, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("module %v log level %v: %v", module, level, err)
+		}
+		moduleLevels[module] = l
+		if l < minLevel {
+			minLevel = l
+		}
+	}
+	levels.Lock()
+	levels.def = defLevel
+	levels.modules = moduleLevels
+	levels.min = minLevel
+	levels.Unlock()
+	return nil
+}
+
+func levelFor(module string) zapcore.Level {
+	levels.RLock()
+	defer levels.RUnlock()
+	if l, ok := levels.modules[module]; ok {
+		return l
+	}
+	return levels.def
+}
+
+// levelCore 根据日志所属的模块过滤日志级别
+type levelCore struct {
+	zapcore.Core
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	levels.RLock()
+	defer levels.RUnlock()
+	return l >= levels.min
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields)}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Fields 日志的结构化字段，json格式输出时作为单独的字段
+type Fields struct {
+	TaskId string
+	Target string
+	Module string
+	Plugin string
+}
+
+// log 模块日志级别未开启时直接返回，不创建子logger，字段在写入时才构造
+func (f Fields) log(level zapcore.Level, msg string) {
+	if level < levelFor(f.Module) {
+		return
+	}
+	l := ZapLog
+	if f.Module != "" {
+		l = l.Named(f.Module)
+	}
+	ce := l.WithOptions(zap.AddCallerSkip(2)).Check(level, msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, 4)
+	if f.TaskId != "" {
+		fields = append(fields, zap.String("task", f.TaskId))
+	}
+	if f.Target != "" {
+		fields = append(fields, zap.String("target", f.Target))
+	}
+	if f.Module != "" {
+		fields = append(fields, zap.String("module", f.Module))
+	}
+	if f.Plugin != "" {
+		fields = append(fields, zap.String("plugin", f.Plugin))
+	}
+	ce.Write(fields...)
+}
+
+func (f Fields) Debug(msg string) {
+	f.log(zapcore.DebugLevel, msg)
+}
+
+func (f Fields) Info(msg string) {
+	f.log(zapcore.InfoLevel, msg)
+}
+
+func (f Fields) Warn(msg string) {
+	f.log(zapcore.WarnLevel, msg)
+}
+
+func (f Fields) Error(msg string) {
+	f.log(zapcore.ErrorLevel, msg)
+}
@@ -11,6 +11,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/Autumn-27/ScopeSentry-Scan/internal/global"
@@ -42,24 +44,54 @@ func NewLogger() error {
 		FunctionKey:    zapcore.OmitKey,
 		StacktraceKey:  "stacktrace",
 	}
-	atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+	cfg := global.AppConfig.Log
+	level := cfg.Level
+	if level == "" {
+		level = "info"
+		if global.AppConfig.Debug {
+			level = "debug"
+		}
+	}
 	if global.AppConfig.Debug {
-		atom = zap.NewAtomicLevelAt(zap.DebugLevel)
 		encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
 		//encoderConfig.CallerKey = "caller_line"
 	}
-	c := zap.Config{
-		Level:         atom,
-		Encoding:      "console",
-		EncoderConfig: encoderConfig,
-		OutputPaths:   []string{"stdout"},
-	}
-	c.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	var err error
-	ZapLog, err = c.Build()
-	if err != nil {
+	if err := SetLevels(level, cfg.ModuleLevels); err != nil {
 		return fmt.Errorf("log 初始化失败: %v", err)
 	}
+	// json格式使用统一的字段名称，便于日志平台采集
+	jsonConfig := zap.NewProductionEncoderConfig()
+	jsonConfig.TimeKey = "ts"
+	jsonConfig.LevelKey = "level"
+	jsonConfig.NameKey = zapcore.OmitKey
+	jsonConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	jsonConfig.EncodeDuration = zapcore.SecondsDurationEncoder
+	node := zap.String("node", global.AppConfig.NodeName)
+
+	var cores []zapcore.Core
+	if cfg.Format == "json" {
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(jsonConfig), zapcore.Lock(os.Stdout), zapcore.DebugLevel).With([]zap.Field{node}))
+	} else {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		cores = append(cores, zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.Lock(os.Stdout), zapcore.DebugLevel))
+	}
+	// 本地日志文件，redis不可用时节点仍然保留日志
+	if cfg.File != "off" {
+		path := cfg.File
+		if path == "" {
+			path = filepath.Join(global.AbsolutePath, "data", "logs", "scan.log")
+		}
+		w, err := newRotateWriter(path, cfg.MaxSize, cfg.MaxBackups)
+		if err != nil {
+			return fmt.Errorf("log 初始化失败: %v", err)
+		}
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(jsonConfig), w, zapcore.DebugLevel).With([]zap.Field{node}))
+	}
+	ZapLog = zap.New(&levelCore{Core: zapcore.NewTee(cores...)},
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	)
 
 	go processLogBuffer()
 
@@ -135,19 +167,25 @@ type logMessage struct {
 	Log  string `json:"log"`
 }
 
-func PluginsLog(msg string, tp string, module string, id string) {
+// PluginsLog 插件日志，本地日志带有模块、插件和任务字段，并按模块的日志级别过滤
+// taskId 为可选参数，兼容没有传入任务id的自定义插件
+func PluginsLog(msg string, tp string, module string, id string, taskId ...string) {
+	f := Fields{Module: module, Plugin: id}
+	if len(taskId) > 0 {
+		f.TaskId = taskId[0]
+	}
 	switch tp {
 	case "i":
-		SlogInfoLocal(msg)
+		f.Info(msg)
 		msg = "[info] " + msg
 	case "e":
-		SlogErrorLocal(msg)
+		f.Error(msg)
 		msg = "[error] " + msg
 	case "d":
-		SlogDebugLocal(msg)
+		f.Debug(msg)
 		msg = "[debug] " + msg
 	case "w":
-		SlogWarnLocal(msg)
+		f.Warn(msg)
 		msg = "[warning] " + msg
 
 	}
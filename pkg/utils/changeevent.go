@@ -0,0 +1,283 @@
+// utils-------------------------------------
+// @file      : changeevent.go
+// @author    : Autumn
+// @contact   : rainy-autumn@outlook.com
+// @time      : 2026/10/19 16:05
+// -------------------------------------------
+
+package utils
+
+import (
+	"fmt"
+	"github.com/Autumn-27/ScopeSentry-Scan/internal/types"
+	"github.com/projectdiscovery/tlsx/pkg/tlsx/clients"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 资产变化事件类型
+const (
+	ChangeNewPort        = "new_port"
+	ChangeServiceVersion = "service_version_changed"
+	ChangeTitle          = "title_changed"
+	ChangeLoginPage      = "login_page"
+	ChangeStatusCode     = "status_code_changed"
+	ChangeIP             = "ip_changed"
+	ChangeTLSCert        = "tls_cert_changed"
+	ChangeTLSExpiring    = "tls_cert_expiring"
+	ChangeTLSExpired     = "tls_cert_expired"
+	ChangeNewTechnology  = "new_technology"
+	ChangeContent        = "content_changed"
+)
+
+// 证书剩余有效期小于该值时产生即将过期事件
+const certExpiringThreshold = 30 * 24 * time.Hour
+
+var severityRank = map[string]int{
+	"info":     1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+	"critical": 5,
+}
+
+// 暴露后风险较高的服务
+var riskyServices = []string{
+	"redis", "mysql", "mssql", "ms-sql", "postgresql", "oracle", "mongodb", "elasticsearch", "memcached",
+	"docker", "kubernetes", "etcd", "zookeeper", "rdp", "ms-wbt-server", "vnc", "telnet", "smb",
+	"microsoft-ds", "ftp", "rsync", "ldap", "jdwp", "rmi",
+}
+
+var loginTitleRegex = regexp.MustCompile(`(?i)(log ?in|sign ?in|admin|dashboard|console|manage|登录|登陆|后台|管理|控制台)`)
+
+var passwordInputRegex = regexp.MustCompile(`(?i)<input[^>]+type\s*=\s*["']?password`)
+
+// SeverityRank 返回等级对应的数值，未知等级返回0
+func (r *Result) SeverityRank(severity string) int {
+	return severityRank[strings.ToLower(severity)]
+}
+
+// MaxSeverity 返回事件中最高的等级
+func (r *Result) MaxSeverity(events []types.ChangeEvent) string {
+	max := ""
+	for _, event := range events {
+		if r.SeverityRank(event.Severity) > r.SeverityRank(max) {
+			max = event.Severity
+		}
+	}
+	return max
+}
+
+// IsLoginPage 根据标题和页面内容判断是否为登录或管理页面
+func (r *Result) IsLoginPage(title string, body string) bool {
+	if title != "" && loginTitleRegex.MatchString(title) {
+		return true
+	}
+	return passwordInputRegex.MatchString(body)
+}
+
+// NewPortEvents 已知主机上出现新端口时产生的事件，高风险服务和登录页面等级更高
+func (r *Result) NewPortEvents(host string, port string, service string, title string, body string) []types.ChangeEvent {
+	severity := "low"
+	for _, s := range riskyServices {
+		if strings.EqualFold(service, s) {
+			severity = "high"
+			break
+		}
+	}
+	events := []types.ChangeEvent{{
+		Type:        ChangeNewPort,
+		Severity:    severity,
+		FieldName:   "Port",
+		New:         port,
+		Description: fmt.Sprintf("new port %v/%v opened on %v", port, service, host),
+	}}
+	if r.IsLoginPage(title, body) {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeLoginPage,
+			Severity:    "high",
+			FieldName:   "Title",
+			New:         title,
+			Description: fmt.Sprintf("login page exposed on new port %v:%v", host, port),
+		})
+	}
+	return events
+}
+
+// assetOtherEvents 对非http资产的变化进行分类
+func (r *Result) assetOtherEvents(old, new types.AssetOther) []types.ChangeEvent {
+	var events []types.ChangeEvent
+	if old.Service != new.Service || old.Version != new.Version {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeServiceVersion,
+			Severity:    "medium",
+			FieldName:   "Service",
+			Old:         strings.TrimSpace(old.Service + " " + old.Version),
+			New:         strings.TrimSpace(new.Service + " " + new.Version),
+			Description: fmt.Sprintf("%v:%v service changed", new.Host, new.Port),
+		})
+	}
+	if old.IP != new.IP {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeIP,
+			Severity:    "low",
+			FieldName:   "IP",
+			Old:         old.IP,
+			New:         new.IP,
+			Description: fmt.Sprintf("%v resolved to a new ip", new.Host),
+		})
+	}
+	return events
+}
+
+// assetHttpEvents 对http资产的变化进行分类
+func (r *Result) assetHttpEvents(old, new types.AssetHttp) []types.ChangeEvent {
+	var events []types.ChangeEvent
+	if old.Title != new.Title {
+		if r.IsLoginPage(new.Title, new.ResponseBody) && !r.IsLoginPage(old.Title, old.ResponseBody) {
+			events = append(events, types.ChangeEvent{
+				Type:        ChangeLoginPage,
+				Severity:    "high",
+				FieldName:   "Title",
+				Old:         old.Title,
+				New:         new.Title,
+				Description: fmt.Sprintf("%v changed to a login page", new.URL),
+			})
+		} else {
+			events = append(events, types.ChangeEvent{
+				Type:        ChangeTitle,
+				Severity:    "low",
+				FieldName:   "Title",
+				Old:         old.Title,
+				New:         new.Title,
+				Description: fmt.Sprintf("%v title changed", new.URL),
+			})
+		}
+	}
+	if old.WebServer != new.WebServer || old.Service != new.Service {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeServiceVersion,
+			Severity:    "medium",
+			FieldName:   "WebServer",
+			Old:         old.WebServer,
+			New:         new.WebServer,
+			Description: fmt.Sprintf("%v web server changed", new.URL),
+		})
+	}
+	if old.StatusCode != new.StatusCode {
+		severity := "info"
+		// 之前不可访问的页面变为可访问
+		if new.StatusCode == 200 && (old.StatusCode == 401 || old.StatusCode == 403 || old.StatusCode == 404) {
+			severity = "medium"
+		}
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeStatusCode,
+			Severity:    severity,
+			FieldName:   "StatusCode",
+			Old:         fmt.Sprint(old.StatusCode),
+			New:         fmt.Sprint(new.StatusCode),
+			Description: fmt.Sprintf("%v status code changed", new.URL),
+		})
+	}
+	if old.IP != new.IP {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeIP,
+			Severity:    "low",
+			FieldName:   "IP",
+			Old:         old.IP,
+			New:         new.IP,
+			Description: fmt.Sprintf("%v resolved to a new ip", new.Host),
+		})
+	}
+	for _, tech := range new.Technologies {
+		found := false
+		for _, oldTech := range old.Technologies {
+			if strings.EqualFold(tech, oldTech) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			events = append(events, types.ChangeEvent{
+				Type:        ChangeNewTechnology,
+				Severity:    "low",
+				FieldName:   "Technologies",
+				New:         tech,
+				Description: fmt.Sprintf("%v new technology %v", new.URL, tech),
+			})
+		}
+	}
+	events = append(events, r.tlsEvents(old, new)...)
+	if old.ResponseBodyHash != new.ResponseBodyHash && len(events) == 0 {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeContent,
+			Severity:    "info",
+			FieldName:   "BodyHash",
+			Old:         old.ResponseBodyHash,
+			New:         new.ResponseBodyHash,
+			Description: fmt.Sprintf("%v content changed", new.URL),
+		})
+	}
+	return events
+}
+
+// tlsEvents 证书变化以及即将过期的事件，过期提醒只在跨过阈值的那次扫描中产生
+func (r *Result) tlsEvents(old, new types.AssetHttp) []types.ChangeEvent {
+	newCert := leafCertificate(new.TLSData)
+	if newCert == nil {
+		return nil
+	}
+	var events []types.ChangeEvent
+	oldCert := leafCertificate(old.TLSData)
+	changed := oldCert != nil && oldCert.FingerprintHash.SHA256 != newCert.FingerprintHash.SHA256
+	if changed {
+		events = append(events, types.ChangeEvent{
+			Type:        ChangeTLSCert,
+			Severity:    "medium",
+			FieldName:   "TLSCertificate",
+			Old:         oldCert.SubjectCN + " " + oldCert.FingerprintHash.SHA256,
+			New:         newCert.SubjectCN + " " + newCert.FingerprintHash.SHA256,
+			Description: fmt.Sprintf("%v tls certificate changed, issuer %v", new.URL, newCert.IssuerCN),
+		})
+	}
+	if newCert.NotAfter.IsZero() {
+		return events
+	}
+	now := time.Now()
+	// 上次扫描时证书的状态
+	lastScan, err := time.ParseInLocation("2006-01-02 15:04:05", old.LastScanTime, time.Local)
+	if err != nil {
+		lastScan = time.Time{}
+	}
+	notAfter := newCert.NotAfter.Format("2006-01-02")
+	if now.After(newCert.NotAfter) {
+		if changed || oldCert == nil || lastScan.IsZero() || !lastScan.After(newCert.NotAfter) {
+			events = append(events, types.ChangeEvent{
+				Type:        ChangeTLSExpired,
+				Severity:    "high",
+				FieldName:   "TLSCertificate",
+				New:         notAfter,
+				Description: fmt.Sprintf("%v tls certificate expired at %v", new.URL, notAfter),
+			})
+		}
+	} else if newCert.NotAfter.Sub(now) < certExpiringThreshold {
+		if changed || oldCert == nil || lastScan.IsZero() || newCert.NotAfter.Sub(lastScan) >= certExpiringThreshold {
+			events = append(events, types.ChangeEvent{
+				Type:        ChangeTLSExpiring,
+				Severity:    "medium",
+				FieldName:   "TLSCertificate",
+				New:         notAfter,
+				Description: fmt.Sprintf("%v tls certificate expires at %v", new.URL, notAfter),
+			})
+		}
+	}
+	return events
+}
+
+func leafCertificate(resp *clients.Response) *clients.CertificateResponse {
+	if resp == nil || resp.CertificateResponse == nil {
+		return nil
+	}
+	return resp.CertificateResponse
+}
@@ -13,12 +13,19 @@ import (
 	"github.com/Autumn-27/ScopeSentry-Scan/pkg/logger"
 	"github.com/projectdiscovery/httpx/runner"
 	"math"
+	"strings"
+	"sync"
 	"time"
 )
 
-var HttpxRun *runner.Runner
+// 每个任务使用自己的httpx实例，并发运行的任务参数可以不同
+var (
+	httpxMu      sync.Mutex
+	httpxRunners = make(map[string]*runner.Runner)
+)
 
-func InitHttpx(cdncheck string, screenshot bool, screenshotTimeout int, tLSProbe bool, followRedirects bool, bypassHeader bool, threads int) {
+// InitHttpx 使用任务的参数创建httpx实例
+func InitHttpx(taskId string, cdncheck string, screenshot bool, screenshotTimeout int, tLSProbe bool, followRedirects bool, bypassHeader bool, threads int) {
 	customHeaders := []string{}
 	if bypassHeader {
 		customHeaders = []string{
@@ -70,16 +77,29 @@ func InitHttpx(cdncheck string, screenshot bool, screenshotTimeout int, tLSProbe
 		Wappalyzer:                Wappalyzer,
 		DisableStdout:             true,
 	}
-	var err error
-	HttpxRun, err = runner.New(&options)
+	httpxRun, err := runner.New(&options)
 	if err != nil {
 		logger.SlogErrorLocal(fmt.Sprintf("httpx get error: %v", err))
+		return
+	}
+	httpxMu.Lock()
+	old := httpxRunners[taskId]
+	httpxRunners[taskId] = httpxRun
+	httpxMu.Unlock()
+	if old != nil {
+		old.Close()
 	}
-	//defer httpxRunner.Close()
-	//fmt.Println(options)
 }
 
-func RunAnalyze(target string, resultCallback func(r types.AssetHttp)) {
+// RunAnalyze 使用任务的httpx实例探测单个目标，customHeaders 为该目标额外携带的请求头，格式为 "Name: value"
+func RunAnalyze(taskId string, target string, resultCallback func(r types.AssetHttp), customHeaders ...string) {
+	httpxMu.Lock()
+	httpxRun := httpxRunners[taskId]
+	httpxMu.Unlock()
+	if httpxRun == nil {
+		logger.SlogErrorLocal(fmt.Sprintf("httpx of task %v is not initialized", taskId))
+		return
+	}
 	resuFunc := func(r runner.Result) {
 		if r.Host == "" {
 			return
@@ -88,9 +108,27 @@ func RunAnalyze(target string, resultCallback func(r types.AssetHttp)) {
 
 		resultCallback(ah)
 	}
-	HttpxRun.RunAnalyze(target, HttpxRun.HTTPX(), resuFunc)
+	if len(customHeaders) == 0 {
+		httpxRun.RunAnalyze(target, httpxRun.HTTPX(), resuFunc)
+		return
+	}
+	headers := make(map[string]string)
+	for _, header := range customHeaders {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 2 {
+			headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	httpxRun.RunAnalyze(target, httpxRun.HTTPX(), resuFunc, headers)
 }
 
-func HttpxClose() {
-	HttpxRun.Close()
+// HttpxClose 任务结束时关闭任务的httpx实例
+func HttpxClose(taskId string) {
+	httpxMu.Lock()
+	httpxRun := httpxRunners[taskId]
+	delete(httpxRunners, taskId)
+	httpxMu.Unlock()
+	if httpxRun != nil {
+		httpxRun.Close()
+	}
 }
@@ -70,8 +70,12 @@ func (r *Result) CompareAssetOther(old, new types.AssetOther) types.AssetChangeL
 			New:       new.Banner,
 		})
 	}
-	if len(Change.Change) != 0 {
+	Change.Events = r.assetOtherEvents(old, new)
+	if len(Change.Change) != 0 || len(Change.Events) != 0 {
 		Change.Timestamp = new.Time
+		Change.Host = new.Host
+		Change.Port = new.Port
+		Change.Severity = r.MaxSeverity(Change.Events)
 		return Change
 	} else {
 		return types.AssetChangeLog{}
@@ -159,6 +163,15 @@ func (r *Result) CompareAssetHttp(old, new types.AssetHttp) types.AssetChangeLog
 			New:       tecCompStr,
 		})
 	}
+	oldCert := leafCertificate(old.TLSData)
+	newCert := leafCertificate(new.TLSData)
+	if oldCert != nil && newCert != nil && oldCert.FingerprintHash.SHA256 != newCert.FingerprintHash.SHA256 {
+		Change.Change = append(Change.Change, types.ChangeLog{
+			FieldName: "TLSCertificate",
+			Old:       oldCert.FingerprintHash.SHA256,
+			New:       newCert.FingerprintHash.SHA256,
+		})
+	}
 	if old.CDN != new.CDN {
 		Change.Change = append(Change.Change, types.ChangeLog{
 			FieldName: "CDN",
@@ -186,8 +199,12 @@ func (r *Result) CompareAssetHttp(old, new types.AssetHttp) types.AssetChangeLog
 			New:       fmt.Sprintf("%.2f%%", simNum*100),
 		})
 	}
-	if len(Change.Change) != 0 {
+	Change.Events = r.assetHttpEvents(old, new)
+	if len(Change.Change) != 0 || len(Change.Events) != 0 {
 		Change.Timestamp = new.Time
+		Change.Host = new.Host
+		Change.Port = new.Port
+		Change.Severity = r.MaxSeverity(Change.Events)
 		return Change
 	} else {
 		return types.AssetChangeLog{}
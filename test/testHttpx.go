@@ -20,8 +20,8 @@ func main() {
 	httpxResultsHandler := func(r types.AssetHttp) {
 		fmt.Printf("%v %v\n", r.URL, r.Screenshot)
 	}
-	utils.InitHttpx("false", false, 10, false, false, false, 10)
-	utils.RunAnalyze("baidu.com", httpxResultsHandler)
+	utils.InitHttpx("test", "false", false, 10, false, false, false, 10)
+	utils.RunAnalyze("test", "baidu.com", httpxResultsHandler)
 	//utils.Requests.Httpx([]string{"https://www.baidu.com/"}, httpxResultsHandler, "true", true, 10, true, true, context.Background(), 10, false)
 	//StatusCode, ContentLength, err := httpxMode.HttpSurvival("https://b31dadwaaidu.com")
 	//fmt.Println(StatusCode, ContentLength, err)